@@ -73,6 +73,31 @@ func (a *AccountService) Update(accountURL string, req acme.Account) (acme.Accou
 	return account, nil
 }
 
+// UpdateContact Replaces the contacts of an account.
+// An empty list removes all the contacts.
+func (a *AccountService) UpdateContact(accountURL string, contacts []string) (acme.Account, error) {
+	if accountURL == "" {
+		return acme.Account{}, errors.New("account[update]: empty URL")
+	}
+
+	if contacts == nil {
+		contacts = []string{}
+	}
+
+	// acme.Account omits an empty list of contacts.
+	req := struct {
+		Contact []string `json:"contact"`
+	}{Contact: contacts}
+
+	var account acme.Account
+	_, err := a.core.post(accountURL, req, &account)
+	if err != nil {
+		return acme.Account{}, err
+	}
+
+	return account, nil
+}
+
 // Deactivate Deactivates an account.
 func (a *AccountService) Deactivate(accountURL string) error {
 	if accountURL == "" {
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountService_UpdateContact(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		contacts []string
+		expected string
+	}{
+		{
+			desc:     "contacts",
+			contacts: []string{"mailto:admin@example.com", "mailto:ops@example.com"},
+			expected: `{"contact":["mailto:admin@example.com","mailto:ops@example.com"]}`,
+		},
+		{
+			desc:     "no contacts",
+			expected: `{"contact":[]}`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			mux, apiURL := tester.SetupFakeAPI(t)
+
+			// small value keeps test fast
+			privateKey, errK := rsa.GenerateKey(rand.Reader, 1024)
+			require.NoError(t, errK, "Could not generate test key")
+
+			mux.HandleFunc("POST /account", func(w http.ResponseWriter, r *http.Request) {
+				body, err := readSignedBody(r, privateKey)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+
+				if string(body) != test.expected {
+					http.Error(w, "unexpected body: "+string(body), http.StatusBadRequest)
+					return
+				}
+
+				var account acme.Account
+				err = json.Unmarshal(body, &account)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+
+				err = tester.WriteJSONResponse(w, acme.Account{Status: acme.StatusValid, Contact: account.Contact})
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+			})
+
+			core, err := New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+			require.NoError(t, err)
+
+			account, err := core.Accounts.UpdateContact(apiURL+"/account", test.contacts)
+			require.NoError(t, err)
+
+			assert.Equal(t, acme.StatusValid, account.Status)
+			assert.Equal(t, test.contacts, account.Contact)
+		})
+	}
+}
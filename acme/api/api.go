@@ -23,6 +23,8 @@ type Core struct {
 	nonceManager *nonces.Manager
 	jws          *secure.JWS
 	directory    acme.Directory
+	directoryURL string
+	retryPolicy  RetryPolicy
 	HTTPClient   *http.Client
 
 	common         service // Reuse a single struct instead of allocating one for each service on the heap.
@@ -46,7 +48,7 @@ func New(httpClient *http.Client, userAgent, caDirURL, kid string, privateKey cr
 
 	jws := secure.NewJWS(privateKey, kid, nonceManager)
 
-	c := &Core{doer: doer, nonceManager: nonceManager, jws: jws, directory: dir, HTTPClient: httpClient}
+	c := &Core{doer: doer, nonceManager: nonceManager, jws: jws, directory: dir, directoryURL: caDirURL, HTTPClient: httpClient}
 
 	c.common.core = c
 	c.Accounts = (*AccountService)(&c.common)
@@ -58,6 +60,11 @@ func New(httpClient *http.Client, userAgent, caDirURL, kid string, privateKey cr
 	return c, nil
 }
 
+// SetRetryPolicy sets the policy used to retry the requests to the ACME server.
+func (a *Core) SetRetryPolicy(policy RetryPolicy) {
+	a.retryPolicy = policy
+}
+
 // post performs an HTTP POST request and parses the response body as JSON,
 // into the provided respBody object.
 func (a *Core) post(uri string, reqBody, response interface{}) (*http.Response, error) {
@@ -66,16 +73,40 @@ func (a *Core) post(uri string, reqBody, response interface{}) (*http.Response,
 		return nil, errors.New("failed to marshal message")
 	}
 
-	return a.retrievablePost(uri, content, response)
+	return a.retrievablePost(uri, content, response, false)
 }
 
 // postAsGet performs an HTTP POST ("POST-as-GET") request.
 // https://www.rfc-editor.org/rfc/rfc8555.html#section-6.3
 func (a *Core) postAsGet(uri string, response interface{}) (*http.Response, error) {
-	return a.retrievablePost(uri, []byte{}, response)
+	return a.retrievablePost(uri, []byte{}, response, true)
 }
 
-func (a *Core) retrievablePost(uri string, content []byte, response interface{}) (*http.Response, error) {
+// retrievablePost performs a signed POST request, and retries it according to the retry policy.
+// The transient failures (5xx responses, network errors) are only retried for the idempotent requests (POST-as-GET),
+// because a non-idempotent request (new order, finalization, revocation, ...) may have been processed by the server.
+func (a *Core) retrievablePost(uri string, content []byte, response interface{}, idempotent bool) (*http.Response, error) {
+	retry := newRetrier(a.retryPolicy, idempotent)
+
+	for {
+		resp, err := a.nonceRetrievablePost(uri, content, response)
+		if err == nil {
+			return resp, nil
+		}
+
+		delay, ok := retry.next(resp, err)
+		if !ok {
+			return resp, err
+		}
+
+		log.Infof("retry in %s due to: %v", delay, err)
+
+		time.Sleep(delay)
+	}
+}
+
+// nonceRetrievablePost performs a signed POST request, and retries it if the nonce was invalidated.
+func (a *Core) nonceRetrievablePost(uri string, content []byte, response interface{}) (*http.Response, error) {
 	// during tests, allow to support ~90% of bad nonce with a minimum of attempts.
 	bo := backoff.NewExponentialBackOff()
 	bo.InitialInterval = 200 * time.Millisecond
@@ -148,6 +179,16 @@ func (a *Core) GetDirectory() acme.Directory {
 	return a.directory
 }
 
+// GetDirectoryURL Gets the URL of the directory.
+func (a *Core) GetDirectoryURL() string {
+	return a.directoryURL
+}
+
+// GetAccountURL Gets the URL of the account (key identifier).
+func (a *Core) GetAccountURL() string {
+	return a.jws.GetKid()
+}
+
 func getDirectory(do *sender.Doer, caDirURL string) (acme.Directory, error) {
 	var dir acme.Directory
 	if _, err := do.Get(caDirURL, &dir); err != nil {
@@ -8,6 +8,26 @@ import (
 
 type AuthorizationService service
 
+// New Creates a new authorization for a domain or an IP address (pre-authorization).
+// - https://www.rfc-editor.org/rfc/rfc8555.html#section-7.4.1
+func (c *AuthorizationService) New(domain string) (acme.ExtendedAuthorization, error) {
+	newAuthzURL := c.core.GetDirectory().NewAuthzURL
+	if newAuthzURL == "" {
+		return acme.ExtendedAuthorization{}, errors.New("authorization[new]: the server does not support pre-authorization")
+	}
+
+	var authz acme.Authorization
+	resp, err := c.core.post(newAuthzURL, acme.NewAuthorizationMessage{Identifier: newIdentifier(domain)}, &authz)
+	if err != nil {
+		return acme.ExtendedAuthorization{}, err
+	}
+
+	return acme.ExtendedAuthorization{
+		Authorization: authz,
+		Location:      resp.Header.Get("Location"),
+	}, nil
+}
+
 // Get Gets an authorization.
 func (c *AuthorizationService) Get(authzURL string) (acme.Authorization, error) {
 	if authzURL == "" {
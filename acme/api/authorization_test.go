@@ -0,0 +1,89 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorizationService_New(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	// small value keeps test fast
+	privateKey, errK := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, errK, "Could not generate test key")
+
+	mux.HandleFunc("/newAuthz", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := readSignedBody(r, privateKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		msg := acme.NewAuthorizationMessage{}
+		err = json.Unmarshal(body, &msg)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Location", apiURL+"/authz/"+msg.Identifier.Value)
+		w.WriteHeader(http.StatusCreated)
+
+		err = json.NewEncoder(w).Encode(acme.Authorization{
+			Status:     acme.StatusPending,
+			Identifier: msg.Identifier,
+			Challenges: []acme.Challenge{{Type: "dns-01", Status: acme.StatusPending, URL: apiURL + "/chlg", Token: "token"}},
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	core, err := New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc     string
+		domain   string
+		expected acme.Identifier
+	}{
+		{
+			desc:     "domain",
+			domain:   "example.com",
+			expected: acme.Identifier{Type: "dns", Value: "example.com"},
+		},
+		{
+			desc:     "IP address",
+			domain:   "192.0.2.1",
+			expected: acme.Identifier{Type: "ip", Value: "192.0.2.1"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			authz, err := core.Authorizations.New(test.domain)
+			require.NoError(t, err)
+
+			assert.Equal(t, apiURL+"/authz/"+test.domain, authz.Location)
+			assert.Equal(t, acme.StatusPending, authz.Status)
+			assert.Equal(t, test.expected, authz.Identifier)
+			assert.Len(t, authz.Challenges, 1)
+		})
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"io"
 	"net/http"
 	"runtime"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-acme/lego/v4/acme"
 )
@@ -142,7 +144,39 @@ func checkError(req *http.Request, resp *http.Response) error {
 			return &acme.NonceError{ProblemDetails: errorDetails}
 		}
 
+		if errorDetails.Type == acme.RateLimitedErr {
+			return &acme.RateLimitError{
+				ProblemDetails: errorDetails,
+				RetryAfter:     parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+			}
+		}
+
 		return errorDetails
 	}
 	return nil
 }
+
+// parseRetryAfter parses the value of the header Retry-After (delay in seconds or HTTP-date).
+// Returns a zero time if the value is missing or invalid.
+// - https://www.rfc-editor.org/rfc/rfc9110.html#section-10.2.3
+func parseRetryAfter(value string, now time.Time) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err == nil {
+		if seconds < 0 {
+			return time.Time{}
+		}
+
+		return now.Add(time.Duration(seconds) * time.Second)
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return date
+}
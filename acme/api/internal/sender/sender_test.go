@@ -5,7 +5,9 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-acme/lego/v4/acme"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -65,3 +67,79 @@ func TestDo_CustomUserAgent(t *testing.T) {
 	}
 	assert.Len(t, strings.Split(ua, " "), 5)
 }
+
+func TestDo_rateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.Header().Set("Content-Type", "application/problem+json")
+		rw.Header().Set("Retry-After", "120")
+		rw.WriteHeader(http.StatusTooManyRequests)
+		_, _ = rw.Write([]byte(`{"type":"urn:ietf:params:acme:error:rateLimited","detail":"too many certificates"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	doer := NewDoer(http.DefaultClient, "")
+
+	start := time.Now()
+
+	_, err := doer.Post(server.URL, strings.NewReader("{}"), "application/jose+json", nil)
+	require.Error(t, err)
+
+	var rateLimitErr *acme.RateLimitError
+	require.ErrorAs(t, err, &rateLimitErr)
+
+	assert.Equal(t, acme.RateLimitedErr, rateLimitErr.Type)
+	assert.Equal(t, http.StatusTooManyRequests, rateLimitErr.HTTPStatus)
+	assert.WithinRange(t, rateLimitErr.RetryAfter, start.Add(120*time.Second), time.Now().Add(120*time.Second))
+
+	// the rate limit errors are still problem details.
+	var problem *acme.ProblemDetails
+	require.ErrorAs(t, err, &problem)
+
+	assert.Equal(t, "urn:ietf:params:acme:error:rateLimited", problem.Type)
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc     string
+		value    string
+		expected time.Time
+	}{
+		{
+			desc:     "empty",
+			value:    "",
+			expected: time.Time{},
+		},
+		{
+			desc:     "seconds",
+			value:    "30",
+			expected: now.Add(30 * time.Second),
+		},
+		{
+			desc:     "negative seconds",
+			value:    "-30",
+			expected: time.Time{},
+		},
+		{
+			desc:     "HTTP-date",
+			value:    "Fri, 01 Mar 2024 13:00:00 GMT",
+			expected: time.Date(2024, time.March, 1, 13, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:     "invalid",
+			value:    "soon",
+			expected: time.Time{},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			retryAfter := parseRetryAfter(test.value, now)
+
+			assert.True(t, test.expected.Equal(retryAfter), "expected %s, got %s", test.expected, retryAfter)
+		})
+	}
+}
@@ -36,13 +36,7 @@ func (o *OrderService) New(domains []string) (acme.ExtendedOrder, error) {
 func (o *OrderService) NewWithOptions(domains []string, opts *OrderOptions) (acme.ExtendedOrder, error) {
 	var identifiers []acme.Identifier
 	for _, domain := range domains {
-		ident := acme.Identifier{Value: domain, Type: "dns"}
-
-		if net.ParseIP(domain) != nil {
-			ident.Type = "ip"
-		}
-
-		identifiers = append(identifiers, ident)
+		identifiers = append(identifiers, newIdentifier(domain))
 	}
 
 	orderReq := acme.Order{Identifiers: identifiers}
@@ -110,3 +104,14 @@ func (o *OrderService) UpdateForCSR(orderURL string, csr []byte) (acme.ExtendedO
 
 	return acme.ExtendedOrder{Order: order}, nil
 }
+
+// newIdentifier creates the identifier of a domain or an IP address.
+func newIdentifier(domain string) acme.Identifier {
+	ident := acme.Identifier{Value: domain, Type: "dns"}
+
+	if net.ParseIP(domain) != nil {
+		ident.Type = "ip"
+	}
+
+	return ident
+}
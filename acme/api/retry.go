@@ -0,0 +1,126 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/go-acme/lego/v4/acme"
+)
+
+const (
+	defaultRetryInitialInterval = time.Second
+	defaultRetryMaxInterval     = 30 * time.Second
+)
+
+// RetryPolicy defines how the requests to the ACME server are retried
+// on transient failures (5xx responses, network errors) and when the requests are rate limited.
+// The zero value disables the retries.
+//
+// The transient failures are only retried for the idempotent requests (POST-as-GET).
+// The requests rejected because of a bad nonce are always retried.
+type RetryPolicy struct {
+	// MaxAttempts the maximum number of attempts of a request, including the first one.
+	MaxAttempts int
+
+	// InitialInterval the delay before the first retry (1s by default).
+	InitialInterval time.Duration
+
+	// MaxInterval the maximum delay between two attempts (30s by default).
+	MaxInterval time.Duration
+
+	// RespectRetryAfter retries the rate-limited requests once the delay defined by the server (`Retry-After`) is elapsed.
+	// If false, the rate-limited requests are not retried.
+	RespectRetryAfter bool
+
+	// MaxRetryAfter the maximum delay to wait before retrying a rate-limited request.
+	// The request is not retried if the server asks to wait longer.
+	// Zero means no limit.
+	MaxRetryAfter time.Duration
+}
+
+func (p RetryPolicy) newBackOff() backoff.BackOff {
+	bo := backoff.NewExponentialBackOff()
+	bo.InitialInterval = defaultRetryInitialInterval
+	bo.MaxInterval = defaultRetryMaxInterval
+	bo.MaxElapsedTime = 0
+
+	if p.InitialInterval > 0 {
+		bo.InitialInterval = p.InitialInterval
+	}
+
+	if p.MaxInterval > 0 {
+		bo.MaxInterval = p.MaxInterval
+	}
+
+	return bo
+}
+
+// retrier applies a RetryPolicy to the attempts of one request.
+type retrier struct {
+	policy     RetryPolicy
+	idempotent bool
+	bo         backoff.BackOff
+	attempts   int
+}
+
+func newRetrier(policy RetryPolicy, idempotent bool) *retrier {
+	return &retrier{policy: policy, idempotent: idempotent, bo: policy.newBackOff(), attempts: 1}
+}
+
+// next returns the delay before the next attempt,
+// or false if the request must not be retried.
+func (r *retrier) next(resp *http.Response, err error) (time.Duration, bool) {
+	if r.attempts >= r.policy.MaxAttempts {
+		return 0, false
+	}
+
+	var delay time.Duration
+
+	var rateLimitErr *acme.RateLimitError
+
+	switch {
+	case errors.As(err, &rateLimitErr):
+		if !r.policy.RespectRetryAfter {
+			return 0, false
+		}
+
+		if rateLimitErr.RetryAfter.IsZero() {
+			delay = r.bo.NextBackOff()
+			break
+		}
+
+		delay = max(time.Until(rateLimitErr.RetryAfter), 0)
+
+		if r.policy.MaxRetryAfter > 0 && delay > r.policy.MaxRetryAfter {
+			return 0, false
+		}
+
+	case r.idempotent && isTransientError(resp, err):
+		delay = r.bo.NextBackOff()
+
+	default:
+		return 0, false
+	}
+
+	if delay == backoff.Stop {
+		return 0, false
+	}
+
+	r.attempts++
+
+	return delay, true
+}
+
+// isTransientError returns true if the error is a server error (5xx) or a network error.
+func isTransientError(resp *http.Response, err error) bool {
+	if resp != nil {
+		return resp.StatusCode >= http.StatusInternalServerError
+	}
+
+	var urlErr *url.Error
+
+	return errors.As(err, &urlErr)
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCore_retrievablePost_retryPolicy(t *testing.T) {
+	rateLimited := func(retryAfter string) http.HandlerFunc {
+		return func(rw http.ResponseWriter, _ *http.Request) {
+			if retryAfter != "" {
+				rw.Header().Set("Retry-After", retryAfter)
+			}
+
+			rw.Header().Set("Content-Type", "application/problem+json")
+			rw.WriteHeader(http.StatusTooManyRequests)
+			_, _ = rw.Write([]byte(`{"type":"urn:ietf:params:acme:error:rateLimited","detail":"too many new orders"}`))
+		}
+	}
+
+	unavailable := func(rw http.ResponseWriter, _ *http.Request) {
+		http.Error(rw, "unavailable", http.StatusServiceUnavailable)
+	}
+
+	testCases := []struct {
+		desc             string
+		policy           RetryPolicy
+		postAsGet        bool
+		failure          http.HandlerFunc
+		failures         int32
+		expectedAttempts int32
+		assertErr        func(t *testing.T, err error)
+	}{
+		{
+			desc:             "no policy: server error",
+			failure:          unavailable,
+			failures:         1,
+			expectedAttempts: 1,
+			assertErr: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+			},
+		},
+		{
+			desc:             "server error",
+			policy:           RetryPolicy{MaxAttempts: 3, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond},
+			postAsGet:        true,
+			failure:          unavailable,
+			failures:         2,
+			expectedAttempts: 3,
+			assertErr: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		{
+			desc:             "server error: max attempts",
+			policy:           RetryPolicy{MaxAttempts: 2, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond},
+			postAsGet:        true,
+			failure:          unavailable,
+			failures:         5,
+			expectedAttempts: 2,
+			assertErr: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+			},
+		},
+		{
+			desc:             "server error: not idempotent",
+			policy:           RetryPolicy{MaxAttempts: 3, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond},
+			failure:          unavailable,
+			failures:         1,
+			expectedAttempts: 1,
+			assertErr: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+			},
+		},
+		{
+			desc:             "rate limited: respect Retry-After",
+			policy:           RetryPolicy{MaxAttempts: 3, RespectRetryAfter: true},
+			failure:          rateLimited("0"),
+			failures:         1,
+			expectedAttempts: 2,
+			assertErr: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		{
+			desc:             "rate limited: without Retry-After",
+			policy:           RetryPolicy{MaxAttempts: 3, InitialInterval: time.Millisecond, RespectRetryAfter: true},
+			failure:          rateLimited(""),
+			failures:         1,
+			expectedAttempts: 2,
+			assertErr: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		{
+			desc:             "rate limited: Retry-After not respected",
+			policy:           RetryPolicy{MaxAttempts: 3},
+			failure:          rateLimited("0"),
+			failures:         1,
+			expectedAttempts: 1,
+			assertErr: func(t *testing.T, err error) {
+				t.Helper()
+
+				var rateLimitErr *acme.RateLimitError
+				require.ErrorAs(t, err, &rateLimitErr)
+			},
+		},
+		{
+			desc:             "rate limited: Retry-After too long",
+			policy:           RetryPolicy{MaxAttempts: 3, RespectRetryAfter: true, MaxRetryAfter: time.Minute},
+			failure:          rateLimited("3600"),
+			failures:         1,
+			expectedAttempts: 1,
+			assertErr: func(t *testing.T, err error) {
+				t.Helper()
+
+				var rateLimitErr *acme.RateLimitError
+				require.ErrorAs(t, err, &rateLimitErr)
+
+				assert.WithinDuration(t, time.Now().Add(time.Hour), rateLimitErr.RetryAfter, time.Minute)
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			mux, apiURL := tester.SetupFakeAPI(t)
+
+			privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+			require.NoError(t, err)
+
+			var attempts atomic.Int32
+
+			handler := func(rw http.ResponseWriter, req *http.Request) {
+				if attempts.Add(1) <= test.failures {
+					test.failure(rw, req)
+					return
+				}
+
+				err := tester.WriteJSONResponse(rw, acme.Order{Status: acme.StatusPending})
+				if err != nil {
+					http.Error(rw, err.Error(), http.StatusInternalServerError)
+				}
+			}
+
+			mux.HandleFunc("POST /newOrder", handler)
+			mux.HandleFunc("POST /order", handler)
+
+			core, err := New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+			require.NoError(t, err)
+
+			core.SetRetryPolicy(test.policy)
+
+			if test.postAsGet {
+				_, err = core.Orders.Get(apiURL + "/order")
+			} else {
+				_, err = core.Orders.New([]string{"example.com"})
+			}
+
+			test.assertErr(t, err)
+
+			assert.Equal(t, test.expectedAttempts, attempts.Load())
+		})
+	}
+}
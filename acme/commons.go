@@ -233,6 +233,14 @@ type Authorization struct {
 	Wildcard bool `json:"wildcard,omitempty"`
 }
 
+// ExtendedAuthorization a extended Authorization.
+type ExtendedAuthorization struct {
+	Authorization
+
+	// The authorization URL, contains the value of the response header `Location`
+	Location string `json:"-"`
+}
+
 // ExtendedChallenge a extended Challenge.
 type ExtendedChallenge struct {
 	Challenge
@@ -292,6 +300,14 @@ type Identifier struct {
 	Value string `json:"value"`
 }
 
+// NewAuthorizationMessage the request to pre-authorize an identifier.
+// - https://www.rfc-editor.org/rfc/rfc8555.html#section-7.4.1
+type NewAuthorizationMessage struct {
+	// identifier (required, object):
+	// The identifier to be authorized.
+	Identifier Identifier `json:"identifier"`
+}
+
 // CSRMessage Certificate Signing Request.
 // - https://www.rfc-editor.org/rfc/rfc8555.html#section-7.4
 type CSRMessage struct {
@@ -2,12 +2,16 @@ package acme
 
 import (
 	"fmt"
+	"time"
 )
 
 // Errors types.
 const (
-	errNS       = "urn:ietf:params:acme:error:"
-	BadNonceErr = errNS + "badNonce"
+	errNS                 = "urn:ietf:params:acme:error:"
+	BadNonceErr           = errNS + "badNonce"
+	RateLimitedErr        = errNS + "rateLimited"
+	ServerInternalErr     = errNS + "serverInternal"
+	UserActionRequiredErr = errNS + "userActionRequired"
 )
 
 // ProblemDetails the problem details object.
@@ -56,3 +60,19 @@ func (p ProblemDetails) Error() string {
 type NonceError struct {
 	*ProblemDetails
 }
+
+// RateLimitError represents the error which is returned
+// if the server is rate limiting the requests of the client.
+// - https://www.rfc-editor.org/rfc/rfc8555.html#section-6.6
+type RateLimitError struct {
+	*ProblemDetails
+
+	// RetryAfter the time after which the request can be retried (from the `Retry-After` header).
+	// Zero if the server doesn't provide it.
+	RetryAfter time.Time
+}
+
+// Unwrap returns the problem details, to keep matching the rate limit errors as *ProblemDetails.
+func (e *RateLimitError) Unwrap() error {
+	return e.ProblemDetails
+}
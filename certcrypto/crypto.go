@@ -139,7 +139,7 @@ func GenerateCSR(privateKey crypto.PrivateKey, domain string, san []string, must
 	var dnsNames []string
 	var ipAddresses []net.IP
 	for _, altname := range san {
-		if ip := net.ParseIP(altname); ip != nil {
+		if ip := net.ParseIP(NormalizeIdentifier(altname)); ip != nil {
 			ipAddresses = append(ipAddresses, ip)
 		} else {
 			dnsNames = append(dnsNames, altname)
@@ -147,7 +147,7 @@ func GenerateCSR(privateKey crypto.PrivateKey, domain string, san []string, must
 	}
 
 	template := x509.CertificateRequest{
-		Subject:     pkix.Name{CommonName: domain},
+		Subject:     pkix.Name{CommonName: NormalizeIdentifier(domain)},
 		DNSNames:    dnsNames,
 		IPAddresses: ipAddresses,
 	}
@@ -218,74 +218,84 @@ func ParsePEMCertificate(cert []byte) (*x509.Certificate, error) {
 }
 
 func GetCertificateMainDomain(cert *x509.Certificate) (string, error) {
-	return getMainDomain(cert.Subject, cert.DNSNames)
+	return getMainDomain(cert.Subject, cert.DNSNames, cert.IPAddresses)
 }
 
 func GetCSRMainDomain(cert *x509.CertificateRequest) (string, error) {
-	return getMainDomain(cert.Subject, cert.DNSNames)
+	return getMainDomain(cert.Subject, cert.DNSNames, cert.IPAddresses)
 }
 
-func getMainDomain(subject pkix.Name, dnsNames []string) (string, error) {
-	if subject.CommonName == "" && len(dnsNames) == 0 {
+func getMainDomain(subject pkix.Name, dnsNames []string, ipAddresses []net.IP) (string, error) {
+	switch {
+	case subject.CommonName != "":
+		return NormalizeIdentifier(subject.CommonName), nil
+	case len(dnsNames) > 0:
+		return dnsNames[0], nil
+	case len(ipAddresses) > 0:
+		return ipAddresses[0].String(), nil
+	default:
 		return "", errors.New("missing domain")
 	}
+}
 
-	if subject.CommonName != "" {
-		return subject.CommonName, nil
-	}
+// ExtractDomains returns the identifiers (domains and IP addresses) of a certificate.
+// The common name is the first identifier, the IP addresses are in their canonical form.
+func ExtractDomains(cert *x509.Certificate) []string {
+	return extractIdentifiers(cert.Subject.CommonName, cert.DNSNames, cert.IPAddresses)
+}
 
-	return dnsNames[0], nil
+// ExtractDomainsCSR returns the identifiers (domains and IP addresses) of a CSR.
+// The common name is the first identifier, the IP addresses are in their canonical form.
+func ExtractDomainsCSR(csr *x509.CertificateRequest) []string {
+	return extractIdentifiers(csr.Subject.CommonName, csr.DNSNames, csr.IPAddresses)
 }
 
-func ExtractDomains(cert *x509.Certificate) []string {
-	var domains []string
-	if cert.Subject.CommonName != "" {
-		domains = append(domains, cert.Subject.CommonName)
+func extractIdentifiers(commonName string, dnsNames []string, ipAddresses []net.IP) []string {
+	var identifiers []string
+	if commonName != "" {
+		identifiers = append(identifiers, NormalizeIdentifier(commonName))
 	}
 
-	// Check for SAN certificate
-	for _, sanDomain := range cert.DNSNames {
-		if sanDomain == cert.Subject.CommonName {
+	for _, sanName := range dnsNames {
+		if slices.Contains(identifiers, sanName) {
+			// Duplicate; skip this name
 			continue
 		}
-		domains = append(domains, sanDomain)
+
+		identifiers = append(identifiers, sanName)
 	}
 
-	commonNameIP := net.ParseIP(cert.Subject.CommonName)
-	for _, sanIP := range cert.IPAddresses {
-		if !commonNameIP.Equal(sanIP) {
-			domains = append(domains, sanIP.String())
+	for _, sanIP := range ipAddresses {
+		if slices.Contains(identifiers, sanIP.String()) {
+			continue
 		}
+
+		identifiers = append(identifiers, sanIP.String())
 	}
 
-	return domains
+	return identifiers
 }
 
-func ExtractDomainsCSR(csr *x509.CertificateRequest) []string {
-	var domains []string
-	if csr.Subject.CommonName != "" {
-		domains = append(domains, csr.Subject.CommonName)
-	}
-
-	// loop over the SubjectAltName DNS names
-	for _, sanName := range csr.DNSNames {
-		if slices.Contains(domains, sanName) {
-			// Duplicate; skip this name
-			continue
-		}
+// IsIPAddress returns true if the identifier is an IP address (RFC 8738).
+func IsIPAddress(identifier string) bool {
+	return net.ParseIP(identifier) != nil
+}
 
-		// Name is unique
-		domains = append(domains, sanName)
+// NormalizeIdentifier returns the canonical form of an IP address
+// (RFC 5952 for IPv6 addresses, without brackets),
+// or the identifier unchanged if it is not an IP address.
+func NormalizeIdentifier(identifier string) string {
+	value := identifier
+	if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		value = value[1 : len(value)-1]
 	}
 
-	cnip := net.ParseIP(csr.Subject.CommonName)
-	for _, sanIP := range csr.IPAddresses {
-		if !cnip.Equal(sanIP) {
-			domains = append(domains, sanIP.String())
-		}
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return identifier
 	}
 
-	return domains
+	return ip.String()
 }
 
 func GeneratePemCert(privateKey *rsa.PrivateKey, domain string, extensions []pkix.Extension) ([]byte, error) {
@@ -5,6 +5,7 @@ import (
 	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/x509"
 	"encoding/pem"
 	"regexp"
 	"testing"
@@ -167,6 +168,63 @@ func TestParsePEMPrivateKey(t *testing.T) {
 	require.Errorf(t, err, "Expected to return an error for non-PEM input")
 }
 
+func TestExtractDomainsCSR_ipAddresses(t *testing.T) {
+	privateKey, err := GeneratePrivateKey(EC256)
+	require.NoError(t, err)
+
+	csrDER, err := GenerateCSR(privateKey, "[2001:DB8::1]", []string{"2001:db8:0::1", "example.com", "192.0.2.1"}, false)
+	require.NoError(t, err)
+
+	csr, err := x509.ParseCertificateRequest(csrDER)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"2001:db8::1", "example.com", "192.0.2.1"}, ExtractDomainsCSR(csr))
+
+	mainDomain, err := GetCSRMainDomain(csr)
+	require.NoError(t, err)
+
+	assert.Equal(t, "2001:db8::1", mainDomain)
+}
+
+func TestGetCSRMainDomain_ipAddressOnly(t *testing.T) {
+	privateKey, err := GeneratePrivateKey(EC256)
+	require.NoError(t, err)
+
+	csrDER, err := GenerateCSR(privateKey, "", []string{"192.0.2.1"}, false)
+	require.NoError(t, err)
+
+	csr, err := x509.ParseCertificateRequest(csrDER)
+	require.NoError(t, err)
+
+	mainDomain, err := GetCSRMainDomain(csr)
+	require.NoError(t, err)
+
+	assert.Equal(t, "192.0.2.1", mainDomain)
+}
+
+func TestNormalizeIdentifier(t *testing.T) {
+	testCases := []struct {
+		identifier string
+		expected   string
+	}{
+		{identifier: "example.com", expected: "example.com"},
+		{identifier: "*.example.com", expected: "*.example.com"},
+		{identifier: "192.0.2.1", expected: "192.0.2.1"},
+		{identifier: "2001:DB8:0:0:0:0:0:1", expected: "2001:db8::1"},
+		{identifier: "[2001:db8::1]", expected: "2001:db8::1"},
+		{identifier: "::ffff:192.0.2.1", expected: "192.0.2.1"},
+		{identifier: "[example.com]", expected: "[example.com]"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.identifier, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, NormalizeIdentifier(test.identifier))
+		})
+	}
+}
+
 type MockRandReader struct {
 	b *bytes.Buffer
 }
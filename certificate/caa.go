@@ -0,0 +1,208 @@
+package certificate
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/go-acme/lego/v4/log"
+	"github.com/miekg/dns"
+)
+
+// CAA property tags.
+// https://www.rfc-editor.org/rfc/rfc8659.html#section-4
+const (
+	caaTagIssue     = "issue"
+	caaTagIssueWild = "issuewild"
+	caaTagIodef     = "iodef"
+)
+
+// CAA parameters.
+// https://www.rfc-editor.org/rfc/rfc8657.html#section-3
+const (
+	caaParamAccountURI        = "accounturi"
+	caaParamValidationMethods = "validationmethods"
+)
+
+// caaFlagCritical the Issuer Critical Flag.
+const caaFlagCritical = 128
+
+// caaLookupFunc returns the relevant CAA RRset of a domain.
+type caaLookupFunc func(fqdn string) ([]*dns.CAA, error)
+
+// challengeTypes is implemented by the resolvers which know the challenge types they can solve.
+type challengeTypes interface {
+	ChallengeTypes() []challenge.Type
+}
+
+// CAAError is returned when a CAA record forbids the issuance of a certificate.
+type CAAError struct {
+	Domain string
+	Reason string
+}
+
+func (e *CAAError) Error() string {
+	return fmt.Sprintf("[%s] acme: CAA forbids the issuance: %s", e.Domain, e.Reason)
+}
+
+// caaPreflight checks that the CAA records (RFC 8659, RFC 8657) of all the domains allow the CA to issue a certificate.
+func (c *Certifier) caaPreflight(domains []string) error {
+	identities := c.core.GetDirectory().Meta.CaaIdentities
+	if len(identities) == 0 {
+		log.Warnf("[%s] acme: CAA preflight skipped: the server does not provide CAA identities", strings.Join(domains, ", "))
+		return nil
+	}
+
+	checker := &caaChecker{
+		lookup:     dns01.FindCAAByFqdn,
+		identities: identities,
+		accountURI: c.core.GetAccountURL(),
+	}
+
+	if r, ok := c.resolver.(challengeTypes); ok {
+		checker.challenges = r.ChallengeTypes()
+	}
+
+	var errAll error
+
+	for _, domain := range domains {
+		err := checker.check(domain)
+		if err != nil {
+			errAll = errors.Join(errAll, err)
+		}
+	}
+
+	return errAll
+}
+
+type caaChecker struct {
+	lookup     caaLookupFunc
+	identities []string
+	accountURI string
+	challenges []challenge.Type
+}
+
+// check checks the CAA records of a domain.
+// https://www.rfc-editor.org/rfc/rfc8659.html#section-4
+func (c *caaChecker) check(domain string) error {
+	// CAA doesn't apply to IP addresses.
+	if net.ParseIP(domain) != nil {
+		return nil
+	}
+
+	wildcard := strings.HasPrefix(domain, "*.")
+
+	records, err := c.lookup(dns.Fqdn(strings.TrimPrefix(domain, "*.")))
+	if err != nil {
+		return fmt.Errorf("[%s] acme: CAA preflight: %w", domain, err)
+	}
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	var issue, issueWild []*dns.CAA
+
+	for _, record := range records {
+		switch strings.ToLower(record.Tag) {
+		case caaTagIssue:
+			issue = append(issue, record)
+		case caaTagIssueWild:
+			issueWild = append(issueWild, record)
+		case caaTagIodef:
+		default:
+			if record.Flag&caaFlagCritical != 0 {
+				return &CAAError{Domain: domain, Reason: fmt.Sprintf("unknown critical property %q", record.Tag)}
+			}
+		}
+	}
+
+	properties := issue
+	if wildcard && len(issueWild) > 0 {
+		properties = issueWild
+	}
+
+	if len(properties) == 0 {
+		return nil
+	}
+
+	var reasons []string
+
+	for _, property := range properties {
+		reason := c.match(property)
+		if reason == "" {
+			return nil
+		}
+
+		reasons = append(reasons, reason)
+	}
+
+	return &CAAError{Domain: domain, Reason: strings.Join(reasons, "; ")}
+}
+
+// match returns an empty string if the property allows the issuance, otherwise the reason of the denial.
+func (c *caaChecker) match(property *dns.CAA) string {
+	issuer, params, err := parseCAAValue(property.Value)
+	if err != nil {
+		return fmt.Sprintf("%s %q: %v", property.Tag, property.Value, err)
+	}
+
+	if issuer == "" {
+		return fmt.Sprintf("%s %q: no issuer is allowed", property.Tag, property.Value)
+	}
+
+	if !slices.ContainsFunc(c.identities, func(identity string) bool { return strings.EqualFold(identity, issuer) }) {
+		return fmt.Sprintf("%s %q: the issuer doesn't match the CA identities (%s)", property.Tag, property.Value, strings.Join(c.identities, ", "))
+	}
+
+	if accountURI, ok := params[caaParamAccountURI]; ok && accountURI != c.accountURI {
+		return fmt.Sprintf("%s %q: the account URI doesn't match %q", property.Tag, property.Value, c.accountURI)
+	}
+
+	if methods, ok := params[caaParamValidationMethods]; ok && len(c.challenges) > 0 {
+		allowed := strings.Split(methods, ",")
+
+		// The solver can use any of the challenge types (depending on the challenges offered by the server),
+		// so all of them must be allowed.
+		var forbidden []string
+		for _, t := range c.challenges {
+			if !slices.Contains(allowed, t.String()) {
+				forbidden = append(forbidden, t.String())
+			}
+		}
+
+		if len(forbidden) > 0 {
+			return fmt.Sprintf("%s %q: the validation methods don't allow the challenges (%s)", property.Tag, property.Value, strings.Join(forbidden, ", "))
+		}
+	}
+
+	return ""
+}
+
+// parseCAAValue parses the value of an issue or issuewild property.
+// https://www.rfc-editor.org/rfc/rfc8659.html#section-4.2
+func parseCAAValue(value string) (string, map[string]string, error) {
+	issuer, rawParams, _ := strings.Cut(value, ";")
+
+	params := make(map[string]string)
+
+	for _, param := range strings.Split(rawParams, ";") {
+		param = strings.TrimSpace(param)
+		if param == "" {
+			continue
+		}
+
+		k, v, ok := strings.Cut(param, "=")
+		if !ok {
+			return "", nil, fmt.Errorf("malformed parameter %q", param)
+		}
+
+		params[strings.ToLower(strings.TrimSpace(k))] = strings.TrimSpace(v)
+	}
+
+	return strings.TrimSpace(issuer), params, nil
+}
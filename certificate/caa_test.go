@@ -0,0 +1,162 @@
+package certificate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_caaChecker_check(t *testing.T) {
+	const accountURI = "https://ca.example/acct/123"
+
+	testCases := []struct {
+		desc       string
+		domain     string
+		records    []string
+		lookupErr  error
+		challenges []challenge.Type
+		expected   string
+	}{
+		{
+			desc:   "no CAA records",
+			domain: "example.com",
+		},
+		{
+			desc:    "issuer allowed",
+			domain:  "example.com",
+			records: []string{`example.com. 0 IN CAA 0 issue "letsencrypt.org"`},
+		},
+		{
+			desc:    "one of the issuers allowed",
+			domain:  "example.com",
+			records: []string{`example.com. 0 IN CAA 0 issue "other.example"`, `example.com. 0 IN CAA 0 issue "letsencrypt.org"`},
+		},
+		{
+			desc:     "issuer not allowed",
+			domain:   "example.com",
+			records:  []string{`example.com. 0 IN CAA 0 issue "other.example"`},
+			expected: `[example.com] acme: CAA forbids the issuance: issue "other.example": the issuer doesn't match the CA identities (letsencrypt.org)`,
+		},
+		{
+			desc:     "no issuance",
+			domain:   "example.com",
+			records:  []string{`example.com. 0 IN CAA 0 issue ";"`},
+			expected: `[example.com] acme: CAA forbids the issuance: issue ";": no issuer is allowed`,
+		},
+		{
+			desc:    "only iodef",
+			domain:  "example.com",
+			records: []string{`example.com. 0 IN CAA 0 iodef "mailto:security@example.com"`},
+		},
+		{
+			desc:     "unknown critical property",
+			domain:   "example.com",
+			records:  []string{`example.com. 0 IN CAA 0 issue "letsencrypt.org"`, `example.com. 0 IN CAA 128 foo "bar"`},
+			expected: `[example.com] acme: CAA forbids the issuance: unknown critical property "foo"`,
+		},
+		{
+			desc:     "wildcard with issuewild",
+			domain:   "*.example.com",
+			records:  []string{`example.com. 0 IN CAA 0 issue "letsencrypt.org"`, `example.com. 0 IN CAA 0 issuewild ";"`},
+			expected: `[*.example.com] acme: CAA forbids the issuance: issuewild ";": no issuer is allowed`,
+		},
+		{
+			desc:    "wildcard without issuewild",
+			domain:  "*.example.com",
+			records: []string{`example.com. 0 IN CAA 0 issue "letsencrypt.org"`},
+		},
+		{
+			desc:    "non-wildcard ignores issuewild",
+			domain:  "example.com",
+			records: []string{`example.com. 0 IN CAA 0 issue "letsencrypt.org"`, `example.com. 0 IN CAA 0 issuewild ";"`},
+		},
+		{
+			desc:    "account URI allowed",
+			domain:  "example.com",
+			records: []string{`example.com. 0 IN CAA 0 issue "letsencrypt.org; accounturi=https://ca.example/acct/123"`},
+		},
+		{
+			desc:     "account URI not allowed",
+			domain:   "example.com",
+			records:  []string{`example.com. 0 IN CAA 0 issue "letsencrypt.org; accounturi=https://ca.example/acct/456"`},
+			expected: `[example.com] acme: CAA forbids the issuance: issue "letsencrypt.org; accounturi=https://ca.example/acct/456": the account URI doesn't match "https://ca.example/acct/123"`,
+		},
+		{
+			desc:       "validation method allowed",
+			domain:     "example.com",
+			records:    []string{`example.com. 0 IN CAA 0 issue "letsencrypt.org; validationmethods=dns-01,http-01"`},
+			challenges: []challenge.Type{challenge.DNS01},
+		},
+		{
+			desc:       "validation method not allowed",
+			domain:     "example.com",
+			records:    []string{`example.com. 0 IN CAA 0 issue "letsencrypt.org; validationmethods=dns-01"`},
+			challenges: []challenge.Type{challenge.TLSALPN01, challenge.HTTP01},
+			expected:   `[example.com] acme: CAA forbids the issuance: issue "letsencrypt.org; validationmethods=dns-01": the validation methods don't allow the challenges (tls-alpn-01, http-01)`,
+		},
+		{
+			desc:       "validation method not allowed for one of the challenges",
+			domain:     "example.com",
+			records:    []string{`example.com. 0 IN CAA 0 issue "letsencrypt.org; validationmethods=dns-01"`},
+			challenges: []challenge.Type{challenge.HTTP01, challenge.DNS01},
+			expected:   `[example.com] acme: CAA forbids the issuance: issue "letsencrypt.org; validationmethods=dns-01": the validation methods don't allow the challenges (http-01)`,
+		},
+		{
+			desc:     "malformed parameter",
+			domain:   "example.com",
+			records:  []string{`example.com. 0 IN CAA 0 issue "letsencrypt.org; foo"`},
+			expected: `[example.com] acme: CAA forbids the issuance: issue "letsencrypt.org; foo": malformed parameter "foo"`,
+		},
+		{
+			desc:   "IP address",
+			domain: "192.0.2.1",
+		},
+		{
+			desc:      "lookup error",
+			domain:    "example.com",
+			lookupErr: errors.New("SERVFAIL"),
+			expected:  "[example.com] acme: CAA preflight: SERVFAIL",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			checker := &caaChecker{
+				lookup: func(fqdn string) ([]*dns.CAA, error) {
+					assert.Equal(t, "example.com.", fqdn)
+
+					if test.lookupErr != nil {
+						return nil, test.lookupErr
+					}
+
+					var records []*dns.CAA
+					for _, s := range test.records {
+						rr, err := dns.NewRR(s)
+						require.NoError(t, err)
+
+						records = append(records, rr.(*dns.CAA))
+					}
+
+					return records, nil
+				},
+				identities: []string{"letsencrypt.org"},
+				accountURI: accountURI,
+				challenges: test.challenges,
+			}
+
+			err := checker.check(test.domain)
+			if test.expected == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.EqualError(t, err, test.expected)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"crypto"
 	"crypto/x509"
 	"encoding/base64"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"io"
@@ -45,10 +46,15 @@ type Resource struct {
 	Domain            string `json:"domain"`
 	CertURL           string `json:"certUrl"`
 	CertStableURL     string `json:"certStableUrl"`
+	CADirURL          string `json:"caDirUrl,omitempty"`
+	Profile           string `json:"profile,omitempty"`
 	PrivateKey        []byte `json:"-"`
 	Certificate       []byte `json:"-"`
 	IssuerCertificate []byte `json:"-"`
 	CSR               []byte `json:"-"`
+
+	// AlternateChains the certificate chains offered by the CA, other than the selected chain.
+	AlternateChains []AlternateChain `json:"alternateChains,omitempty"`
 }
 
 // ObtainRequest The request to obtain certificate.
@@ -74,9 +80,16 @@ type ObtainRequest struct {
 	Bundle         bool
 	PreferredChain string
 
+	// The rules to select the certificate chain, in addition to PreferredChain.
+	ChainPreference *ChainPreference
+
+	// If true, the chains not selected are returned in Resource.AlternateChains.
+	AlternateChains bool
+
 	// A string uniquely identifying the profile
 	// which will be used to affect issuance of the certificate requested by this Order.
 	// - https://www.ietf.org/id/draft-aaron-acme-profiles-00.html#section-4
+	// Ignored (with a warning) if the ACME server doesn't support profiles.
 	Profile string
 
 	AlwaysDeactivateAuthorizations bool
@@ -85,6 +98,11 @@ type ObtainRequest struct {
 	// order is intended to replace.
 	// - https://datatracker.ietf.org/doc/html/draft-ietf-acme-ari-03#section-5
 	ReplacesCertID string
+
+	// If true, the CAA records of the domains are checked before creating the order.
+	// - https://www.rfc-editor.org/rfc/rfc8659.html
+	// - https://www.rfc-editor.org/rfc/rfc8657.html
+	CAAPreflight bool
 }
 
 // ObtainForCSRRequest The request to obtain a certificate matching the CSR passed into it.
@@ -101,9 +119,16 @@ type ObtainForCSRRequest struct {
 	Bundle         bool
 	PreferredChain string
 
+	// The rules to select the certificate chain, in addition to PreferredChain.
+	ChainPreference *ChainPreference
+
+	// If true, the chains not selected are returned in Resource.AlternateChains.
+	AlternateChains bool
+
 	// A string uniquely identifying the profile
 	// which will be used to affect issuance of the certificate requested by this Order.
 	// - https://www.ietf.org/id/draft-aaron-acme-profiles-00.html#section-4
+	// Ignored (with a warning) if the ACME server doesn't support profiles.
 	Profile string
 
 	AlwaysDeactivateAuthorizations bool
@@ -112,6 +137,11 @@ type ObtainForCSRRequest struct {
 	// order is intended to replace.
 	// - https://datatracker.ietf.org/doc/html/draft-ietf-acme-ari-03#section-5
 	ReplacesCertID string
+
+	// If true, the CAA records of the domains are checked before creating the order.
+	// - https://www.rfc-editor.org/rfc/rfc8659.html
+	// - https://www.rfc-editor.org/rfc/rfc8657.html
+	CAAPreflight bool
 }
 
 type resolver interface {
@@ -122,6 +152,9 @@ type CertifierOptions struct {
 	KeyType             certcrypto.KeyType
 	Timeout             time.Duration
 	OverallRequestLimit int
+
+	// OrderStore persists the orders in progress to resume them after an interruption (optional).
+	OrderStore OrderStore
 }
 
 // Certifier A service to obtain/renew/revoke certificates.
@@ -165,40 +198,41 @@ func (c *Certifier) Obtain(request ObtainRequest) (*Resource, error) {
 		log.Infof("[%s] acme: Obtaining SAN certificate", strings.Join(domains, ", "))
 	}
 
+	profile, err := c.checkProfile(request.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.CAAPreflight {
+		err = c.caaPreflight(domains)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	orderOpts := &api.OrderOptions{
 		NotBefore:      request.NotBefore,
 		NotAfter:       request.NotAfter,
-		Profile:        request.Profile,
+		Profile:        profile,
 		ReplacesCertID: request.ReplacesCertID,
 	}
 
-	order, err := c.core.Orders.NewWithOptions(domains, orderOpts)
+	order, state, err := c.createOrResumeOrder(domains, orderOpts)
 	if err != nil {
 		return nil, err
 	}
 
-	authz, err := c.getAuthorizations(order)
+	authz, err := c.solveOrder(domains, order, request.AlwaysDeactivateAuthorizations)
 	if err != nil {
-		// If any challenge fails, return. Do not generate partial SAN certificates.
-		c.deactivateAuthorizations(order, request.AlwaysDeactivateAuthorizations)
-		return nil, err
-	}
-
-	err = c.resolver.Solve(authz)
-	if err != nil {
-		// If any challenge fails, return. Do not generate partial SAN certificates.
-		c.deactivateAuthorizations(order, request.AlwaysDeactivateAuthorizations)
 		return nil, err
 	}
 
-	log.Infof("[%s] acme: Validations succeeded; requesting certificates", strings.Join(domains, ", "))
-
 	failures := newObtainError()
-	cert, err := c.getForOrder(domains, order, request.Bundle, request.PrivateKey, request.MustStaple, request.PreferredChain)
+	cert, err := c.getForOrder(domains, order, state, request.Bundle, request.PrivateKey, request.MustStaple, newChainOptions(request.PreferredChain, request.ChainPreference, request.AlternateChains))
 	if err != nil {
-		for _, auth := range authz {
-			failures.Add(challenge.GetTargetedDomain(auth), err)
-		}
+		addOrderFailures(failures, domains, authz, err)
+	} else {
+		c.deleteOrderState(domains)
 	}
 
 	if request.AlwaysDeactivateAuthorizations {
@@ -232,40 +266,51 @@ func (c *Certifier) ObtainForCSR(request ObtainForCSRRequest) (*Resource, error)
 		log.Infof("[%s] acme: Obtaining SAN certificate given a CSR", strings.Join(domains, ", "))
 	}
 
+	profile, err := c.checkProfile(request.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.CAAPreflight {
+		err = c.caaPreflight(domains)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	orderOpts := &api.OrderOptions{
 		NotBefore:      request.NotBefore,
 		NotAfter:       request.NotAfter,
-		Profile:        request.Profile,
+		Profile:        profile,
 		ReplacesCertID: request.ReplacesCertID,
 	}
 
-	order, err := c.core.Orders.NewWithOptions(domains, orderOpts)
+	order, state, err := c.createOrResumeOrder(domains, orderOpts)
 	if err != nil {
 		return nil, err
 	}
 
-	authz, err := c.getAuthorizations(order)
-	if err != nil {
-		// If any challenge fails, return. Do not generate partial SAN certificates.
-		c.deactivateAuthorizations(order, request.AlwaysDeactivateAuthorizations)
-		return nil, err
+	if isOrderFinalized(order) && !bytes.Equal(state.CSR, certcrypto.PEMEncode(request.CSR)) {
+		// The order in progress has been finalized with another CSR.
+		c.deleteOrderState(domains)
+
+		order, state, err = c.createOrResumeOrder(domains, orderOpts)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	err = c.resolver.Solve(authz)
+	authz, err := c.solveOrder(domains, order, request.AlwaysDeactivateAuthorizations)
 	if err != nil {
-		// If any challenge fails, return. Do not generate partial SAN certificates.
-		c.deactivateAuthorizations(order, request.AlwaysDeactivateAuthorizations)
 		return nil, err
 	}
 
-	log.Infof("[%s] acme: Validations succeeded; requesting certificates", strings.Join(domains, ", "))
-
 	failures := newObtainError()
-	cert, err := c.getForCSR(domains, order, request.Bundle, request.CSR.Raw, nil, request.PreferredChain)
+	cert, err := c.getForCSR(domains, order, state, request.Bundle, request.CSR.Raw, nil, newChainOptions(request.PreferredChain, request.ChainPreference, request.AlternateChains))
 	if err != nil {
-		for _, auth := range authz {
-			failures.Add(challenge.GetTargetedDomain(auth), err)
-		}
+		addOrderFailures(failures, domains, authz, err)
+	} else {
+		c.deleteOrderState(domains)
 	}
 
 	if request.AlwaysDeactivateAuthorizations {
@@ -280,7 +325,71 @@ func (c *Certifier) ObtainForCSR(request ObtainForCSRRequest) (*Resource, error)
 	return cert, failures.Join()
 }
 
-func (c *Certifier) getForOrder(domains []string, order acme.ExtendedOrder, bundle bool, privateKey crypto.PrivateKey, mustStaple bool, preferredChain string) (*Resource, error) {
+// createOrResumeOrder resumes the order in progress for the domains (see OrderStore), or creates a new order.
+func (c *Certifier) createOrResumeOrder(domains []string, orderOpts *api.OrderOptions) (acme.ExtendedOrder, *OrderState, error) {
+	state, order := c.resumeOrder(domains, orderOpts)
+	if state != nil {
+		return order, state, nil
+	}
+
+	order, err := c.core.Orders.NewWithOptions(domains, orderOpts)
+	if err != nil {
+		return acme.ExtendedOrder{}, nil, err
+	}
+
+	return order, c.newOrderState(domains, orderOpts, order), nil
+}
+
+// solveOrder solves the authorizations of the order, if the order is not yet finalized.
+func (c *Certifier) solveOrder(domains []string, order acme.ExtendedOrder, alwaysDeactivateAuthorizations bool) ([]acme.Authorization, error) {
+	if isOrderFinalized(order) {
+		return nil, nil
+	}
+
+	authz, err := c.getAuthorizations(order)
+	if err != nil {
+		// If any challenge fails, return. Do not generate partial SAN certificates.
+		c.deactivateAuthorizations(order, alwaysDeactivateAuthorizations)
+		c.deleteOrderState(domains)
+		return nil, err
+	}
+
+	err = c.resolver.Solve(authz)
+	if err != nil {
+		// If any challenge fails, return. Do not generate partial SAN certificates.
+		c.deactivateAuthorizations(order, alwaysDeactivateAuthorizations)
+		c.deleteOrderState(domains)
+		return nil, err
+	}
+
+	log.Infof("[%s] acme: Validations succeeded; requesting certificates", strings.Join(domains, ", "))
+
+	return authz, nil
+}
+
+// addOrderFailures adds the error to the domains of the authorizations,
+// or to the domains of the order if the authorizations have not been solved (resumed order).
+func addOrderFailures(failures *obtainError, domains []string, authz []acme.Authorization, err error) {
+	if len(authz) == 0 {
+		failures.Add(strings.Join(domains, ", "), err)
+		return
+	}
+
+	for _, auth := range authz {
+		failures.Add(challenge.GetTargetedDomain(auth), err)
+	}
+}
+
+func (c *Certifier) getForOrder(domains []string, order acme.ExtendedOrder, state *OrderState, bundle bool, privateKey crypto.PrivateKey, mustStaple bool, chains chainOptions) (*Resource, error) {
+	if isOrderFinalized(order) {
+		// The order has been finalized before an interruption: the certificate matches the private key of the order.
+		return c.getForCSR(domains, order, state, bundle, nil, state.PrivateKey, chains)
+	}
+
+	if privateKey == nil {
+		privateKey = statePrivateKey(state)
+	}
+
 	if privateKey == nil {
 		var err error
 		privateKey, err = certcrypto.GeneratePrivateKey(c.options.KeyType)
@@ -318,24 +427,39 @@ func (c *Certifier) getForOrder(domains []string, order acme.ExtendedOrder, bund
 		return nil, err
 	}
 
-	return c.getForCSR(domains, order, bundle, csr, certcrypto.PEMEncode(privateKey), preferredChain)
+	return c.getForCSR(domains, order, state, bundle, csr, certcrypto.PEMEncode(privateKey), chains)
 }
 
-func (c *Certifier) getForCSR(domains []string, order acme.ExtendedOrder, bundle bool, csr, privateKeyPem []byte, preferredChain string) (*Resource, error) {
+func (c *Certifier) getForCSR(domains []string, order acme.ExtendedOrder, state *OrderState, bundle bool, csr, privateKeyPem []byte, chains chainOptions) (*Resource, error) {
+	certRes := &Resource{
+		Domain:     domains[0],
+		CertURL:    order.Certificate,
+		CADirURL:   c.core.GetDirectoryURL(),
+		Profile:    order.Profile,
+		PrivateKey: privateKeyPem,
+	}
+
+	if isOrderFinalized(order) {
+		return certRes, c.waitForCertificate(order, certRes, bundle, chains)
+	}
+
+	if state != nil {
+		// The private key and the CSR are saved before finalizing the order, to be able to resume it.
+		state.PrivateKey = privateKeyPem
+		state.CSR = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr})
+		c.saveOrderState(state)
+	}
+
 	respOrder, err := c.core.Orders.UpdateForCSR(order.Finalize, csr)
 	if err != nil {
 		return nil, err
 	}
 
-	certRes := &Resource{
-		Domain:     domains[0],
-		CertURL:    respOrder.Certificate,
-		PrivateKey: privateKeyPem,
-	}
+	certRes.CertURL = respOrder.Certificate
 
 	if respOrder.Status == acme.StatusValid {
 		// if the certificate is available right away, shortcut!
-		ok, errR := c.checkResponse(respOrder, certRes, bundle, preferredChain)
+		ok, errR := c.checkResponse(respOrder, certRes, bundle, chains)
 		if errR != nil {
 			return nil, errR
 		}
@@ -345,26 +469,29 @@ func (c *Certifier) getForCSR(domains []string, order acme.ExtendedOrder, bundle
 		}
 	}
 
+	return certRes, c.waitForCertificate(order, certRes, bundle, chains)
+}
+
+// waitForCertificate waits for the certificate of the order, and loads it into certRes.
+func (c *Certifier) waitForCertificate(order acme.ExtendedOrder, certRes *Resource, bundle bool, chains chainOptions) error {
 	timeout := c.options.Timeout
 	if c.options.Timeout <= 0 {
 		timeout = 30 * time.Second
 	}
 
-	err = wait.For("certificate", timeout, timeout/60, func() (bool, error) {
+	return wait.For("certificate", timeout, timeout/60, func() (bool, error) {
 		ord, errW := c.core.Orders.Get(order.Location)
 		if errW != nil {
 			return false, errW
 		}
 
-		done, errW := c.checkResponse(ord, certRes, bundle, preferredChain)
+		done, errW := c.checkResponse(ord, certRes, bundle, chains)
 		if errW != nil {
 			return false, errW
 		}
 
 		return done, nil
 	})
-
-	return certRes, err
 }
 
 // checkResponse checks to see if the certificate is ready and a link is contained in the response.
@@ -375,7 +502,9 @@ func (c *Certifier) getForCSR(domains []string, order acme.ExtendedOrder, bundle
 // The certRes input should already have the Domain (common name) field populated.
 //
 // If bundle is true, the certificate will be bundled with the issuer's cert.
-func (c *Certifier) checkResponse(order acme.ExtendedOrder, certRes *Resource, bundle bool, preferredChain string) (bool, error) {
+//
+// The chain is selected among the chains offered by the CA according to the chain options.
+func (c *Certifier) checkResponse(order acme.ExtendedOrder, certRes *Resource, bundle bool, chains chainOptions) (bool, error) {
 	valid, err := checkOrderStatus(order)
 	if err != nil || !valid {
 		return valid, err
@@ -386,37 +515,47 @@ func (c *Certifier) checkResponse(order acme.ExtendedOrder, certRes *Resource, b
 		return false, err
 	}
 
-	// Set the default certificate
-	certRes.IssuerCertificate = certs[order.Certificate].Issuer
-	certRes.Certificate = certs[order.Certificate].Cert
-	certRes.CertURL = order.Certificate
-	certRes.CertStableURL = order.Certificate
+	offered := newChains(certs, order.Certificate)
 
-	if preferredChain == "" {
-		log.Infof("[%s] Server responded with a certificate.", certRes.Domain)
+	// Use the default chain
+	selected := 0
 
-		return true, nil
-	}
-
-	for link, cert := range certs {
-		ok, err := hasPreferredChain(cert.Issuer, preferredChain)
+	if !chains.hasRules() {
+		log.Infof("[%s] Server responded with a certificate.", certRes.Domain)
+	} else {
+		index, ok, err := selectChain(offered, chains)
 		if err != nil {
 			return false, err
 		}
 
 		if ok {
-			log.Infof("[%s] Server responded with a certificate for the preferred certificate chains %q.", certRes.Domain, preferredChain)
+			log.Infof("[%s] Server responded with a certificate for the preferred certificate chains (%s).", certRes.Domain, chains)
 
-			certRes.IssuerCertificate = cert.Issuer
-			certRes.Certificate = cert.Cert
-			certRes.CertURL = link
-			certRes.CertStableURL = link
-
-			return true, nil
+			selected = index
+		} else {
+			log.Infof("lego has been configured to prefer certificate chains (%s), but no chain from the CA matched. Using the default certificate chain instead.", chains)
 		}
 	}
 
-	log.Infof("lego has been configured to prefer certificate chains with issuer %q, but no chain from the CA matched this issuer. Using the default certificate chain instead.", preferredChain)
+	certRes.IssuerCertificate = offered[selected].raw.Issuer
+	certRes.Certificate = offered[selected].raw.Cert
+	certRes.CertURL = offered[selected].url
+	certRes.CertStableURL = offered[selected].url
+	certRes.AlternateChains = nil
+
+	if chains.alternateChains {
+		for i, alt := range offered {
+			if i == selected {
+				continue
+			}
+
+			certRes.AlternateChains = append(certRes.AlternateChains, AlternateChain{
+				CertURL:           alt.url,
+				Certificate:       alt.raw.Cert,
+				IssuerCertificate: alt.raw.Issuer,
+			})
+		}
+	}
 
 	return true, nil
 }
@@ -451,12 +590,18 @@ type RenewOptions struct {
 	NotBefore time.Time
 	NotAfter  time.Time
 	// If true, the []byte contains both the issuer certificate and your issued certificate as a bundle.
-	Bundle                         bool
-	PreferredChain                 string
+	Bundle         bool
+	PreferredChain string
+	// The rules to select the certificate chain, in addition to PreferredChain.
+	ChainPreference *ChainPreference
+	// If true, the chains not selected are returned in Resource.AlternateChains.
+	AlternateChains                bool
 	Profile                        string
 	AlwaysDeactivateAuthorizations bool
 	// Not supported for CSR request.
 	MustStaple bool
+	// If true, the CAA records of the domains are checked before creating the order.
+	CAAPreflight bool
 }
 
 // Renew takes a Resource and tries to renew the certificate.
@@ -522,8 +667,11 @@ func (c *Certifier) RenewWithOptions(certRes Resource, options *RenewOptions) (*
 			request.NotAfter = options.NotAfter
 			request.Bundle = options.Bundle
 			request.PreferredChain = options.PreferredChain
+			request.ChainPreference = options.ChainPreference
+			request.AlternateChains = options.AlternateChains
 			request.Profile = options.Profile
 			request.AlwaysDeactivateAuthorizations = options.AlwaysDeactivateAuthorizations
+			request.CAAPreflight = options.CAAPreflight
 		}
 
 		return c.ObtainForCSR(request)
@@ -548,8 +696,11 @@ func (c *Certifier) RenewWithOptions(certRes Resource, options *RenewOptions) (*
 		request.NotAfter = options.NotAfter
 		request.Bundle = options.Bundle
 		request.PreferredChain = options.PreferredChain
+		request.ChainPreference = options.ChainPreference
+		request.AlternateChains = options.AlternateChains
 		request.Profile = options.Profile
 		request.AlwaysDeactivateAuthorizations = options.AlwaysDeactivateAuthorizations
+		request.CAAPreflight = options.CAAPreflight
 	}
 
 	return c.Obtain(request)
@@ -664,24 +815,10 @@ func (c *Certifier) Get(url string, bundle bool) (*Resource, error) {
 		IssuerCertificate: issuer,
 		CertURL:           url,
 		CertStableURL:     url,
+		CADirURL:          c.core.GetDirectoryURL(),
 	}, nil
 }
 
-func hasPreferredChain(issuer []byte, preferredChain string) (bool, error) {
-	certs, err := certcrypto.ParsePEMBundle(issuer)
-	if err != nil {
-		return false, err
-	}
-
-	topCert := certs[len(certs)-1]
-
-	if topCert.Issuer.CommonName == preferredChain {
-		return true, nil
-	}
-
-	return false, nil
-}
-
 func checkOrderStatus(order acme.ExtendedOrder) (bool, error) {
 	switch order.Status {
 	case acme.StatusValid:
@@ -701,6 +838,12 @@ func checkOrderStatus(order acme.ExtendedOrder) (bool, error) {
 func sanitizeDomain(domains []string) []string {
 	var sanitizedDomains []string
 	for _, domain := range domains {
+		// IP addresses (RFC 8738) are used in their canonical form.
+		if ip := certcrypto.NormalizeIdentifier(domain); certcrypto.IsIPAddress(ip) {
+			sanitizedDomains = append(sanitizedDomains, ip)
+			continue
+		}
+
 		sanitizedDomain, err := idna.ToASCII(domain)
 		if err != nil {
 			log.Infof("skip domain %q: unable to sanitize (punnycode): %v", domain, err)
@@ -0,0 +1,217 @@
+package certificate
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/certcrypto"
+)
+
+// ChainPreference the rules to select a certificate chain when the CA offers alternate chains.
+// - https://www.rfc-editor.org/rfc/rfc8555.html#section-7.4.2
+//
+// A chain is selected only if it matches all the defined rules.
+// If several chains match, the chain offered by default by the CA is preferred, unless Shortest is true.
+// If no chain matches, the default chain is used.
+type ChainPreference struct {
+	// SPKIFingerprints the SHA-256 fingerprints (hex) of the Subject Public Key Info of intermediate or root certificates.
+	// The chain must contain a certificate with one of these fingerprints
+	// (a cross-signed root certificate has the same fingerprint as the root certificate).
+	SPKIFingerprints []string
+
+	// KeyAlgorithm the algorithm of the keys used to sign all the issuer certificates of the chain (e.g. x509.ECDSA).
+	KeyAlgorithm x509.PublicKeyAlgorithm
+
+	// Shortest selects the chain with the fewest issuer certificates.
+	Shortest bool
+}
+
+// AlternateChain a certificate chain offered by the CA, and not selected.
+type AlternateChain struct {
+	CertURL           string `json:"certUrl"`
+	Certificate       []byte `json:"-"`
+	IssuerCertificate []byte `json:"-"`
+}
+
+// chainOptions the options used to select and to keep the certificate chains.
+type chainOptions struct {
+	// preferredChain the Subject Common Name of the issuer of the top certificate of the chain.
+	preferredChain  string
+	preference      *ChainPreference
+	alternateChains bool
+}
+
+func newChainOptions(preferredChain string, preference *ChainPreference, alternateChains bool) chainOptions {
+	return chainOptions{
+		preferredChain:  preferredChain,
+		preference:      preference,
+		alternateChains: alternateChains,
+	}
+}
+
+func (o chainOptions) hasRules() bool {
+	return o.preferredChain != "" || o.preference != nil
+}
+
+func (o chainOptions) String() string {
+	var rules []string
+
+	if o.preferredChain != "" {
+		rules = append(rules, fmt.Sprintf("issuer %q", o.preferredChain))
+	}
+
+	if o.preference != nil {
+		if len(o.preference.SPKIFingerprints) > 0 {
+			rules = append(rules, fmt.Sprintf("SPKI fingerprints %s", strings.Join(o.preference.SPKIFingerprints, ", ")))
+		}
+
+		if o.preference.KeyAlgorithm != x509.UnknownPublicKeyAlgorithm {
+			rules = append(rules, fmt.Sprintf("key algorithm %s", o.preference.KeyAlgorithm))
+		}
+
+		if o.preference.Shortest {
+			rules = append(rules, "shortest")
+		}
+	}
+
+	return strings.Join(rules, ", ")
+}
+
+// chain a certificate chain offered by the CA.
+type chain struct {
+	url     string
+	raw     *acme.RawCertificate
+	issuers []*x509.Certificate
+}
+
+// newChains returns the chains offered by the CA: the default chain first, then the alternate chains sorted by URL.
+func newChains(certs map[string]*acme.RawCertificate, defaultURL string) []chain {
+	chains := []chain{{url: defaultURL, raw: certs[defaultURL]}}
+
+	var links []string
+
+	for link := range certs {
+		if link != defaultURL {
+			links = append(links, link)
+		}
+	}
+
+	sort.Strings(links)
+
+	for _, link := range links {
+		chains = append(chains, chain{url: link, raw: certs[link]})
+	}
+
+	return chains
+}
+
+// selectChain returns the index of the chain matching the options, and false if no chain matches.
+func selectChain(chains []chain, opts chainOptions) (int, bool, error) {
+	selected := -1
+
+	for i := range chains {
+		issuers, err := certcrypto.ParsePEMBundle(chains[i].raw.Issuer)
+		if err != nil {
+			return 0, false, err
+		}
+
+		chains[i].issuers = issuers
+
+		if !opts.matches(chains[i]) {
+			continue
+		}
+
+		if selected == -1 {
+			selected = i
+		}
+
+		if opts.preference == nil || !opts.preference.Shortest {
+			break
+		}
+
+		if len(issuers) < len(chains[selected].issuers) {
+			selected = i
+		}
+	}
+
+	if selected == -1 {
+		return 0, false, nil
+	}
+
+	return selected, true, nil
+}
+
+func (o chainOptions) matches(c chain) bool {
+	if len(c.issuers) == 0 {
+		return false
+	}
+
+	if o.preferredChain != "" && c.issuers[len(c.issuers)-1].Issuer.CommonName != o.preferredChain {
+		return false
+	}
+
+	if o.preference == nil {
+		return true
+	}
+
+	if len(o.preference.SPKIFingerprints) > 0 && !containsSPKIFingerprint(c.issuers, o.preference.SPKIFingerprints) {
+		return false
+	}
+
+	if o.preference.KeyAlgorithm != x509.UnknownPublicKeyAlgorithm {
+		for _, issuer := range c.issuers {
+			if signatureKeyAlgorithm(issuer.SignatureAlgorithm) != o.preference.KeyAlgorithm {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func containsSPKIFingerprint(certs []*x509.Certificate, fingerprints []string) bool {
+	for _, cert := range certs {
+		if slices.ContainsFunc(fingerprints, func(fingerprint string) bool {
+			return strings.EqualFold(normalizeFingerprint(fingerprint), SPKIFingerprint(cert))
+		}) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SPKIFingerprint returns the SHA-256 fingerprint (hex) of the Subject Public Key Info of the certificate.
+func SPKIFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeFingerprint removes the separators of a fingerprint (e.g. `AB:CD:EF`).
+func normalizeFingerprint(fingerprint string) string {
+	return strings.NewReplacer(":", "", " ", "").Replace(strings.TrimSpace(fingerprint))
+}
+
+// signatureKeyAlgorithm returns the algorithm of the key used to create the signature.
+func signatureKeyAlgorithm(algorithm x509.SignatureAlgorithm) x509.PublicKeyAlgorithm {
+	switch algorithm {
+	case x509.MD2WithRSA, x509.MD5WithRSA, x509.SHA1WithRSA, x509.SHA256WithRSA, x509.SHA384WithRSA, x509.SHA512WithRSA,
+		x509.SHA256WithRSAPSS, x509.SHA384WithRSAPSS, x509.SHA512WithRSAPSS:
+		return x509.RSA
+	case x509.ECDSAWithSHA1, x509.ECDSAWithSHA256, x509.ECDSAWithSHA384, x509.ECDSAWithSHA512:
+		return x509.ECDSA
+	case x509.PureEd25519:
+		return x509.Ed25519
+	case x509.DSAWithSHA1, x509.DSAWithSHA256:
+		return x509.DSA
+	default:
+		return x509.UnknownPublicKeyAlgorithm
+	}
+}
@@ -0,0 +1,164 @@
+package certificate
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_selectChain(t *testing.T) {
+	rootRSAKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	rootECKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	intermediateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	rootRSA := createCACertificate(t, "Root RSA", rootRSAKey, nil, rootRSAKey)
+	rootEC := createCACertificate(t, "Root EC", rootECKey, nil, rootECKey)
+	crossSignedRootEC := createCACertificate(t, "Root EC", rootECKey, rootRSA, rootRSAKey)
+	intermediate := createCACertificate(t, "Intermediate EC", intermediateKey, rootEC, rootECKey)
+
+	// The default chain is the longest: Intermediate EC -> Root EC (cross-signed) -> Root RSA.
+	chains := []chain{
+		{url: "https://example.com/cert", raw: &acme.RawCertificate{Issuer: pemChain(intermediate, crossSignedRootEC)}},
+		{url: "https://example.com/cert/1", raw: &acme.RawCertificate{Issuer: pemChain(intermediate)}},
+	}
+
+	testCases := []struct {
+		desc          string
+		opts          chainOptions
+		expected      int
+		expectedMatch bool
+	}{
+		{
+			desc:          "issuer common name",
+			opts:          chainOptions{preferredChain: "Root EC"},
+			expected:      1,
+			expectedMatch: true,
+		},
+		{
+			desc:          "unknown issuer common name",
+			opts:          chainOptions{preferredChain: "Root X"},
+			expectedMatch: false,
+		},
+		{
+			desc:          "SPKI fingerprint",
+			opts:          chainOptions{preference: &ChainPreference{SPKIFingerprints: []string{SPKIFingerprint(rootEC)}}},
+			expected:      0,
+			expectedMatch: true,
+		},
+		{
+			desc: "SPKI fingerprint with separators",
+			opts: chainOptions{preference: &ChainPreference{
+				SPKIFingerprints: []string{strings.ToUpper(colonSeparated(SPKIFingerprint(intermediate)))},
+			}},
+			expected:      0,
+			expectedMatch: true,
+		},
+		{
+			desc:          "unknown SPKI fingerprint",
+			opts:          chainOptions{preference: &ChainPreference{SPKIFingerprints: []string{SPKIFingerprint(rootRSA)}}},
+			expectedMatch: false,
+		},
+		{
+			desc:          "key algorithm ECDSA",
+			opts:          chainOptions{preference: &ChainPreference{KeyAlgorithm: x509.ECDSA}},
+			expected:      1,
+			expectedMatch: true,
+		},
+		{
+			desc:          "key algorithm RSA",
+			opts:          chainOptions{preference: &ChainPreference{KeyAlgorithm: x509.RSA}},
+			expectedMatch: false,
+		},
+		{
+			desc:          "shortest",
+			opts:          chainOptions{preference: &ChainPreference{Shortest: true}},
+			expected:      1,
+			expectedMatch: true,
+		},
+		{
+			desc:          "shortest with issuer common name",
+			opts:          chainOptions{preferredChain: "Root RSA", preference: &ChainPreference{Shortest: true}},
+			expected:      0,
+			expectedMatch: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			index, ok, err := selectChain(append([]chain(nil), chains...), test.opts)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedMatch, ok)
+			assert.Equal(t, test.expected, index)
+		})
+	}
+}
+
+func createCACertificate(t *testing.T, commonName string, key crypto.Signer, parent *x509.Certificate, parentKey crypto.Signer) *x509.Certificate {
+	t.Helper()
+
+	serialNumber, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	if parent == nil {
+		parent = template
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert
+}
+
+func pemChain(certs ...*x509.Certificate) []byte {
+	var data []byte
+
+	for _, cert := range certs {
+		data = append(data, certcrypto.PEMEncode(certcrypto.DERCertificateBytes(cert.Raw))...)
+	}
+
+	return data
+}
+
+func colonSeparated(fingerprint string) string {
+	var parts []string
+
+	for i := 0; i < len(fingerprint); i += 2 {
+		parts = append(parts, fingerprint[i:i+2])
+	}
+
+	return strings.Join(parts, ":")
+}
@@ -0,0 +1,100 @@
+package certificate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/log"
+)
+
+// PreAuthorize creates and validates an authorization for each domain, without creating an order.
+//
+// The valid authorizations are reused by the ACME server for the next orders containing the same identifiers,
+// until they expire.
+// The ACME server must support pre-authorization (`newAuthz` in the directory).
+// Wildcard domains cannot be pre-authorized.
+// - https://www.rfc-editor.org/rfc/rfc8555.html#section-7.4.1
+func (c *Certifier) PreAuthorize(domains []string) ([]acme.ExtendedAuthorization, error) {
+	if len(domains) == 0 {
+		return nil, errors.New("no domains to pre-authorize")
+	}
+
+	if c.core.GetDirectory().NewAuthzURL == "" {
+		return nil, errors.New("the ACME server does not support pre-authorization")
+	}
+
+	domains = sanitizeDomain(domains)
+
+	for _, domain := range domains {
+		if strings.HasPrefix(domain, "*.") {
+			return nil, fmt.Errorf("wildcard domains cannot be pre-authorized: %s", domain)
+		}
+	}
+
+	log.Infof("[%s] acme: Pre-authorizing domains", strings.Join(domains, ", "))
+
+	delay := time.Second / time.Duration(c.overallRequestLimit)
+
+	var authzs []acme.ExtendedAuthorization
+
+	failures := newObtainError()
+
+	for _, domain := range domains {
+		time.Sleep(delay)
+
+		authz, err := c.core.Authorizations.New(domain)
+		if err != nil {
+			failures.Add(domain, err)
+			continue
+		}
+
+		log.Infof("[%s] AuthURL: %s", domain, authz.Location)
+
+		authzs = append(authzs, authz)
+	}
+
+	err := failures.Join()
+	if err != nil {
+		c.deactivatePreAuthorizations(authzs)
+		return nil, err
+	}
+
+	var toSolve []acme.Authorization
+	for _, authz := range authzs {
+		toSolve = append(toSolve, authz.Authorization)
+	}
+
+	err = c.resolver.Solve(toSolve)
+	if err != nil {
+		c.deactivatePreAuthorizations(authzs)
+		return nil, err
+	}
+
+	log.Infof("[%s] acme: Pre-authorizations succeeded", strings.Join(domains, ", "))
+
+	// Gets the final state of the authorizations (status, expiration date).
+	for i, authz := range authzs {
+		updated, err := c.core.Authorizations.Get(authz.Location)
+		if err != nil {
+			log.Warnf("[%s] acme: unable to get the authorization: %v", authz.Identifier.Value, err)
+			continue
+		}
+
+		authzs[i].Authorization = updated
+	}
+
+	return authzs, nil
+}
+
+// deactivatePreAuthorizations deactivates the authorizations which are not valid.
+func (c *Certifier) deactivatePreAuthorizations(authzs []acme.ExtendedAuthorization) {
+	var authzURLs []string
+	for _, authz := range authzs {
+		authzURLs = append(authzURLs, authz.Location)
+	}
+
+	c.deactivateAuthorizations(acme.ExtendedOrder{Order: acme.Order{Authorizations: authzURLs}}, false)
+}
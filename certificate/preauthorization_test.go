@@ -0,0 +1,191 @@
+package certificate
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/acme/api"
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type resolverFuncMock func(authorizations []acme.Authorization) error
+
+func (f resolverFuncMock) Solve(authorizations []acme.Authorization) error {
+	return f(authorizations)
+}
+
+func TestCertifier_PreAuthorize(t *testing.T) {
+	statuses, core := setupPreAuthorizationTest(t)
+
+	resolver := resolverFuncMock(func(authorizations []acme.Authorization) error {
+		for _, authz := range authorizations {
+			assert.Equal(t, acme.StatusPending, authz.Status)
+			statuses.set(authz.Identifier.Value, acme.StatusValid)
+		}
+
+		return nil
+	})
+
+	certifier := NewCertifier(core, resolver, CertifierOptions{KeyType: certcrypto.RSA2048})
+
+	authzs, err := certifier.PreAuthorize([]string{"example.com", "192.0.2.1"})
+	require.NoError(t, err)
+
+	require.Len(t, authzs, 2)
+
+	assert.Equal(t, acme.Identifier{Type: "dns", Value: "example.com"}, authzs[0].Identifier)
+	assert.Equal(t, acme.StatusValid, authzs[0].Status)
+	assert.True(t, strings.HasSuffix(authzs[0].Location, "/authz/example.com"))
+
+	assert.Equal(t, acme.Identifier{Type: "ip", Value: "192.0.2.1"}, authzs[1].Identifier)
+	assert.Equal(t, acme.StatusValid, authzs[1].Status)
+}
+
+func TestCertifier_PreAuthorize_solveError(t *testing.T) {
+	statuses, core := setupPreAuthorizationTest(t)
+
+	resolver := resolverFuncMock(func(authorizations []acme.Authorization) error {
+		statuses.set(authorizations[0].Identifier.Value, acme.StatusValid)
+
+		return errors.New("OOPS")
+	})
+
+	certifier := NewCertifier(core, resolver, CertifierOptions{KeyType: certcrypto.RSA2048})
+
+	_, err := certifier.PreAuthorize([]string{"example.com", "example.org"})
+	require.EqualError(t, err, "OOPS")
+
+	// only the authorizations which are not valid are deactivated.
+	assert.Equal(t, acme.StatusValid, statuses.get("example.com"))
+	assert.Equal(t, acme.StatusDeactivated, statuses.get("example.org"))
+}
+
+func TestCertifier_PreAuthorize_wildcard(t *testing.T) {
+	_, core := setupPreAuthorizationTest(t)
+
+	certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: certcrypto.RSA2048})
+
+	_, err := certifier.PreAuthorize([]string{"example.com", "*.example.com"})
+	require.EqualError(t, err, "wildcard domains cannot be pre-authorized: *.example.com")
+}
+
+type authzStatuses struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func (s *authzStatuses) set(domain, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values[domain] = status
+}
+
+func (s *authzStatuses) get(domain string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.values[domain]
+}
+
+func setupPreAuthorizationTest(t *testing.T) (*authzStatuses, *api.Core) {
+	t.Helper()
+
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err, "Could not generate test key")
+
+	statuses := &authzStatuses{values: make(map[string]string)}
+
+	writeAuthz := func(w http.ResponseWriter, ident acme.Identifier) {
+		w.Header().Set("Location", apiURL+"/authz/"+ident.Value)
+
+		err := tester.WriteJSONResponse(w, acme.Authorization{
+			Status:     statuses.get(ident.Value),
+			Identifier: ident,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+
+	mux.HandleFunc("POST /newAuthz", func(w http.ResponseWriter, r *http.Request) {
+		body, err := readSignedBody(r, key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var msg acme.NewAuthorizationMessage
+		err = json.Unmarshal(body, &msg)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		statuses.set(msg.Identifier.Value, acme.StatusPending)
+
+		writeAuthz(w, msg.Identifier)
+	})
+
+	mux.HandleFunc("POST /authz/{domain}", func(w http.ResponseWriter, r *http.Request) {
+		body, err := readSignedBody(r, key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		domain := r.PathValue("domain")
+
+		if len(body) > 0 {
+			var update acme.Authorization
+			err = json.Unmarshal(body, &update)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			statuses.set(domain, update.Status)
+		}
+
+		ident := acme.Identifier{Type: "dns", Value: domain}
+		if net.ParseIP(domain) != nil {
+			ident.Type = "ip"
+		}
+
+		writeAuthz(w, ident)
+	})
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	return statuses, core
+}
+
+func readSignedBody(r *http.Request, privateKey *rsa.PrivateKey) ([]byte, error) {
+	reqBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	jws, err := jose.ParseSigned(string(reqBody), []jose.SignatureAlgorithm{jose.RS256})
+	if err != nil {
+		return nil, err
+	}
+
+	return jws.Verify(&jose.JSONWebKey{Key: privateKey.Public(), Algorithm: "RSA"})
+}
@@ -0,0 +1,38 @@
+package certificate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-acme/lego/v4/log"
+)
+
+// checkProfile checks that the profile is offered by the ACME server, and returns the profile to use.
+// If the ACME server doesn't support profiles, the profile is ignored.
+// - https://www.ietf.org/id/draft-aaron-acme-profiles-00.html#section-3
+func (c *Certifier) checkProfile(profile string) (string, error) {
+	if profile == "" {
+		return "", nil
+	}
+
+	profiles := c.core.GetDirectory().Meta.Profiles
+	if len(profiles) == 0 {
+		log.Warnf("acme: the ACME server doesn't support profiles: the profile %q is ignored", profile)
+		return "", nil
+	}
+
+	if _, ok := profiles[profile]; !ok {
+		var names []string
+		for name := range profiles {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		return "", fmt.Errorf("the profile %q is not offered by the ACME server (available profiles: %s)",
+			profile, strings.Join(names, ", "))
+	}
+
+	return profile, nil
+}
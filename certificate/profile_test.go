@@ -0,0 +1,92 @@
+package certificate
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/acme/api"
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertifier_checkProfile(t *testing.T) {
+	_, apiURL := tester.SetupFakeAPI(t)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: certcrypto.RSA2048})
+
+	testCases := []struct {
+		desc     string
+		profile  string
+		require  require.ErrorAssertionFunc
+		expected string
+	}{
+		{
+			desc:    "no profile",
+			require: require.NoError,
+		},
+		{
+			desc:     "offered profile",
+			profile:  "shortlived",
+			require:  require.NoError,
+			expected: "shortlived",
+		},
+		{
+			desc:    "unknown profile",
+			profile: "tlsserver",
+			require: func(t require.TestingT, err error, _ ...interface{}) {
+				require.EqualError(t, err, `the profile "tlsserver" is not offered by the ACME server (available profiles: classic, shortlived)`)
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			profile, err := certifier.checkProfile(test.profile)
+			test.require(t, err)
+
+			assert.Equal(t, test.expected, profile)
+		})
+	}
+}
+
+func TestCertifier_checkProfile_notSupported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		err := tester.WriteJSONResponse(w, acme.Directory{
+			NewNonceURL:   "http://example.com/nonce",
+			NewAccountURL: "http://example.com/account",
+			NewOrderURL:   "http://example.com/newOrder",
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	core, err := api.New(http.DefaultClient, "lego-test", server.URL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: certcrypto.RSA2048})
+
+	// the profile is ignored.
+	profile, err := certifier.checkProfile("shortlived")
+	require.NoError(t, err)
+
+	assert.Empty(t, profile)
+}
@@ -0,0 +1,205 @@
+package certificate
+
+import (
+	"crypto"
+	"slices"
+	"time"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/acme/api"
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/log"
+)
+
+// OrderState the state of an order in progress.
+// It allows to resume the order after an interruption, instead of creating a new order.
+type OrderState struct {
+	// Domains the domains of the order, as requested.
+	Domains []string `json:"domains"`
+
+	// OrderURL the URL of the order.
+	OrderURL string `json:"orderUrl"`
+
+	// DirectoryURL the URL of the directory of the ACME server.
+	DirectoryURL string `json:"directoryUrl,omitempty"`
+
+	// AccountURL the URL of the account used to create the order.
+	AccountURL string `json:"accountUrl,omitempty"`
+
+	// Options the options of the order, as requested.
+	Options OrderStateOptions `json:"options"`
+
+	// PrivateKey the PEM encoded private key of the certificate.
+	// Empty until the order is finalized, and when the certificate is obtained for a CSR.
+	PrivateKey []byte `json:"privateKey,omitempty"`
+
+	// CSR the PEM encoded CSR sent to finalize the order.
+	// Empty until the order is finalized.
+	CSR []byte `json:"csr,omitempty"`
+}
+
+// OrderStateOptions the options of an order in progress.
+// An order is only resumed if it has been created with the same options.
+type OrderStateOptions struct {
+	NotBefore      time.Time `json:"notBefore"`
+	NotAfter       time.Time `json:"notAfter"`
+	Profile        string    `json:"profile,omitempty"`
+	ReplacesCertID string    `json:"replacesCertId,omitempty"`
+}
+
+func newOrderStateOptions(opts *api.OrderOptions) OrderStateOptions {
+	if opts == nil {
+		return OrderStateOptions{}
+	}
+
+	return OrderStateOptions{
+		NotBefore:      opts.NotBefore,
+		NotAfter:       opts.NotAfter,
+		Profile:        opts.Profile,
+		ReplacesCertID: opts.ReplacesCertID,
+	}
+}
+
+// equal compares the options (the times are compared with time.Time.Equal).
+func (o OrderStateOptions) equal(other OrderStateOptions) bool {
+	return o.NotBefore.Equal(other.NotBefore) &&
+		o.NotAfter.Equal(other.NotAfter) &&
+		o.Profile == other.Profile &&
+		o.ReplacesCertID == other.ReplacesCertID
+}
+
+// OrderStore persists the state of the orders in progress.
+// The state of an order is saved when the order is created and before it is finalized,
+// and it is deleted when the certificate is obtained.
+//
+// A store is used by one ACME server and one account:
+// the states of the orders of other servers or accounts are ignored.
+type OrderStore interface {
+	// Load returns the state of the order in progress for the domains, or nil if there is none.
+	Load(domains []string) (*OrderState, error)
+	// Save saves the state of an order.
+	Save(state *OrderState) error
+	// Delete deletes the state of the order in progress for the domains.
+	Delete(domains []string) error
+}
+
+// resumeOrder gets the order in progress for the domains.
+// It returns nil if there is no order to resume,
+// or if the order in progress has been created with other options.
+func (c *Certifier) resumeOrder(domains []string, orderOpts *api.OrderOptions) (*OrderState, acme.ExtendedOrder) {
+	if c.options.OrderStore == nil {
+		return nil, acme.ExtendedOrder{}
+	}
+
+	state, err := c.options.OrderStore.Load(domains)
+	if err != nil {
+		log.Warnf("[%s] acme: unable to load the order in progress: %v", domains[0], err)
+		return nil, acme.ExtendedOrder{}
+	}
+
+	if state == nil || state.OrderURL == "" || !slices.Equal(state.Domains, domains) {
+		return nil, acme.ExtendedOrder{}
+	}
+
+	if state.DirectoryURL != c.core.GetDirectoryURL() || state.AccountURL != c.core.GetAccountURL() {
+		// The order belongs to another ACME server or another account.
+		return nil, acme.ExtendedOrder{}
+	}
+
+	if !state.Options.equal(newOrderStateOptions(orderOpts)) {
+		log.Infof("[%s] acme: the order in progress %s has been created with other options, a new order will be created", domains[0], state.OrderURL)
+		c.deleteOrderState(domains)
+
+		return nil, acme.ExtendedOrder{}
+	}
+
+	order, err := c.core.Orders.Get(state.OrderURL)
+	if err != nil {
+		log.Warnf("[%s] acme: unable to get the order in progress %s: %v", domains[0], state.OrderURL, err)
+		c.deleteOrderState(domains)
+
+		return nil, acme.ExtendedOrder{}
+	}
+
+	order.Location = state.OrderURL
+
+	switch order.Status {
+	case acme.StatusPending, acme.StatusReady:
+		// not yet finalized.
+
+	case acme.StatusProcessing, acme.StatusValid:
+		if len(state.CSR) == 0 {
+			log.Warnf("[%s] acme: the order in progress %s is finalized but its CSR is unknown", domains[0], state.OrderURL)
+			c.deleteOrderState(domains)
+
+			return nil, acme.ExtendedOrder{}
+		}
+
+	default:
+		log.Infof("[%s] acme: the order in progress %s is %s", domains[0], state.OrderURL, order.Status)
+		c.deleteOrderState(domains)
+
+		return nil, acme.ExtendedOrder{}
+	}
+
+	log.Infof("[%s] acme: Resuming the order %s (%s)", domains[0], state.OrderURL, order.Status)
+
+	return state, order
+}
+
+// newOrderState creates and saves the state of a new order.
+func (c *Certifier) newOrderState(domains []string, orderOpts *api.OrderOptions, order acme.ExtendedOrder) *OrderState {
+	state := &OrderState{
+		Domains:      domains,
+		OrderURL:     order.Location,
+		DirectoryURL: c.core.GetDirectoryURL(),
+		AccountURL:   c.core.GetAccountURL(),
+		Options:      newOrderStateOptions(orderOpts),
+	}
+
+	c.saveOrderState(state)
+
+	return state
+}
+
+func (c *Certifier) saveOrderState(state *OrderState) {
+	if c.options.OrderStore == nil {
+		return
+	}
+
+	err := c.options.OrderStore.Save(state)
+	if err != nil {
+		log.Warnf("[%s] acme: unable to save the order in progress: %v", state.Domains[0], err)
+	}
+}
+
+func (c *Certifier) deleteOrderState(domains []string) {
+	if c.options.OrderStore == nil {
+		return
+	}
+
+	err := c.options.OrderStore.Delete(domains)
+	if err != nil {
+		log.Warnf("[%s] acme: unable to delete the order in progress: %v", domains[0], err)
+	}
+}
+
+// isOrderFinalized returns true if the CSR has already been sent.
+func isOrderFinalized(order acme.ExtendedOrder) bool {
+	return order.Status == acme.StatusProcessing || order.Status == acme.StatusValid
+}
+
+// statePrivateKey returns the private key of the order in progress, if any.
+func statePrivateKey(state *OrderState) crypto.PrivateKey {
+	if state == nil || len(state.PrivateKey) == 0 {
+		return nil
+	}
+
+	privateKey, err := certcrypto.ParsePEMPrivateKey(state.PrivateKey)
+	if err != nil {
+		log.Warnf("[%s] acme: unable to parse the private key of the order in progress: %v", state.Domains[0], err)
+		return nil
+	}
+
+	return privateKey
+}
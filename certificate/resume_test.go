@@ -0,0 +1,262 @@
+package certificate
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/acme/api"
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type orderStoreMock struct {
+	mu     sync.Mutex
+	states map[string]*OrderState
+}
+
+func (s *orderStoreMock) Load(domains []string) (*OrderState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.states[domains[0]], nil
+}
+
+func (s *orderStoreMock) Save(state *OrderState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.states[state.Domains[0]] = state
+
+	return nil
+}
+
+func (s *orderStoreMock) Delete(domains []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.states, domains[0])
+
+	return nil
+}
+
+// fakeOrderServer an ACME server with a single order.
+type fakeOrderServer struct {
+	mu sync.Mutex
+
+	status    string
+	newOrders int
+	finalizes int
+}
+
+func (f *fakeOrderServer) order(apiURL string) acme.Order {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	order := acme.Order{
+		Status:      f.status,
+		Identifiers: []acme.Identifier{{Type: "dns", Value: "example.com"}},
+		Finalize:    apiURL + "/finalize",
+	}
+
+	switch f.status {
+	case acme.StatusValid:
+		order.Certificate = apiURL + "/certificate"
+	case acme.StatusProcessing:
+		// the certificate is issued after the first request.
+		f.status = acme.StatusValid
+	}
+
+	return order
+}
+
+func setupResumeTest(t *testing.T, store OrderStore) (*fakeOrderServer, *Certifier, string) {
+	t.Helper()
+
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	server := &fakeOrderServer{}
+
+	mux.HandleFunc("POST /newOrder", func(w http.ResponseWriter, _ *http.Request) {
+		server.mu.Lock()
+		server.newOrders++
+		server.status = acme.StatusReady
+		server.mu.Unlock()
+
+		w.Header().Set("Location", apiURL+"/order")
+
+		err := tester.WriteJSONResponse(w, server.order(apiURL))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	mux.HandleFunc("POST /order", func(w http.ResponseWriter, _ *http.Request) {
+		err := tester.WriteJSONResponse(w, server.order(apiURL))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	mux.HandleFunc("POST /finalize", func(w http.ResponseWriter, _ *http.Request) {
+		// the state must be saved before finalizing the order.
+		state, _ := store.Load([]string{"example.com"})
+		if state == nil || len(state.CSR) == 0 || len(state.PrivateKey) == 0 {
+			http.Error(w, "the state of the order is not saved", http.StatusBadRequest)
+			return
+		}
+
+		server.mu.Lock()
+		server.finalizes++
+		server.status = acme.StatusValid
+		server.mu.Unlock()
+
+		err := tester.WriteJSONResponse(w, server.order(apiURL))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	mux.HandleFunc("POST /certificate", func(w http.ResponseWriter, _ *http.Request) {
+		_, err := w.Write([]byte(certResponseMock))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err, "Could not generate test key")
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: certcrypto.RSA2048, OrderStore: store})
+
+	return server, certifier, apiURL
+}
+
+func TestCertifier_Obtain_newOrder(t *testing.T) {
+	store := &orderStoreMock{states: make(map[string]*OrderState)}
+
+	server, certifier, _ := setupResumeTest(t, store)
+
+	certRes, err := certifier.Obtain(ObtainRequest{Domains: []string{"example.com"}, Bundle: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, certResponseMock, string(certRes.Certificate))
+	assert.NotEmpty(t, certRes.PrivateKey)
+
+	assert.Equal(t, 1, server.newOrders)
+	assert.Equal(t, 1, server.finalizes)
+
+	// the state is deleted when the certificate is obtained.
+	assert.Empty(t, store.states)
+}
+
+func TestCertifier_Obtain_resume(t *testing.T) {
+	privateKey, err := certcrypto.GeneratePrivateKey(certcrypto.RSA2048)
+	require.NoError(t, err)
+
+	privateKeyPEM := certcrypto.PEMEncode(privateKey)
+
+	testCases := []struct {
+		desc              string
+		status            string
+		csr               []byte
+		directoryURL      string
+		options           OrderStateOptions
+		expectedNewOrders int
+		expectedFinalizes int
+	}{
+		{
+			desc:              "ready",
+			status:            acme.StatusReady,
+			expectedFinalizes: 1,
+		},
+		{
+			desc:   "valid",
+			status: acme.StatusValid,
+			csr:    []byte("CSR"),
+		},
+		{
+			desc:   "processing",
+			status: acme.StatusProcessing,
+			csr:    []byte("CSR"),
+		},
+		{
+			desc:              "invalid",
+			status:            acme.StatusInvalid,
+			expectedNewOrders: 1,
+			expectedFinalizes: 1,
+		},
+		{
+			desc:              "finalized without CSR",
+			status:            acme.StatusValid,
+			expectedNewOrders: 1,
+			expectedFinalizes: 1,
+		},
+		{
+			desc:              "other options",
+			status:            acme.StatusReady,
+			options:           OrderStateOptions{Profile: "shortlived"},
+			expectedNewOrders: 1,
+			expectedFinalizes: 1,
+		},
+		{
+			desc:              "other ACME server",
+			status:            acme.StatusValid,
+			csr:               []byte("CSR"),
+			directoryURL:      "https://acme.example.com/directory",
+			expectedNewOrders: 1,
+			expectedFinalizes: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			store := &orderStoreMock{states: make(map[string]*OrderState)}
+
+			server, certifier, apiURL := setupResumeTest(t, store)
+
+			server.status = test.status
+
+			directoryURL := apiURL + "/dir"
+			if test.directoryURL != "" {
+				directoryURL = test.directoryURL
+			}
+
+			store.states["example.com"] = &OrderState{
+				Domains:      []string{"example.com"},
+				OrderURL:     apiURL + "/order",
+				DirectoryURL: directoryURL,
+				Options:      test.options,
+				PrivateKey:   privateKeyPEM,
+				CSR:          test.csr,
+			}
+
+			certRes, err := certifier.Obtain(ObtainRequest{Domains: []string{"example.com"}, Bundle: true})
+			require.NoError(t, err)
+
+			assert.Equal(t, certResponseMock, string(certRes.Certificate))
+
+			if test.expectedNewOrders == 0 {
+				// the private key of the resumed order is used.
+				assert.Equal(t, privateKeyPEM, certRes.PrivateKey)
+			} else {
+				assert.NotEqual(t, privateKeyPEM, certRes.PrivateKey)
+			}
+
+			assert.Equal(t, test.expectedNewOrders, server.newOrders)
+			assert.Equal(t, test.expectedFinalizes, server.finalizes)
+
+			assert.Empty(t, store.states)
+		})
+	}
+}
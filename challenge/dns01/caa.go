@@ -0,0 +1,51 @@
+package dns01
+
+import (
+	"fmt"
+
+	"github.com/miekg/dns"
+)
+
+// FindCAAByFqdn returns the relevant CAA RRset of the given fqdn (RFC 8659 §3):
+// the CAA records of the fqdn, or of the closest parent domain having CAA records.
+// The CNAMEs are followed by the recursive nameservers.
+// An empty result means that there is no CAA restriction.
+func FindCAAByFqdn(fqdn string) ([]*dns.CAA, error) {
+	return FindCAAByFqdnCustom(fqdn, discoveryNameservers(fqdn))
+}
+
+// FindCAAByFqdnCustom returns the relevant CAA RRset of the given fqdn (RFC 8659 §3)
+// by using the given nameservers.
+func FindCAAByFqdnCustom(fqdn string, nameservers []string) ([]*dns.CAA, error) {
+	fqdn = ToFqdn(fqdn)
+
+	for _, index := range dns.Split(fqdn) {
+		domain := fqdn[index:]
+
+		r, err := dnsQuery(domain, dns.TypeCAA, nameservers, true)
+		if err != nil {
+			return nil, fmt.Errorf("[fqdn=%s] CAA lookup: %w", fqdn, err)
+		}
+
+		switch r.Rcode {
+		case dns.RcodeSuccess, dns.RcodeNameError:
+			// The CAA records of a CNAME target are part of the answer.
+			var records []*dns.CAA
+			for _, rr := range r.Answer {
+				if caa, ok := rr.(*dns.CAA); ok {
+					records = append(records, caa)
+				}
+			}
+
+			if len(records) > 0 {
+				return records, nil
+			}
+
+		default:
+			// RFC 8659 §3: the issuance must not proceed if the lookup fails.
+			return nil, &DNSError{Message: fmt.Sprintf("unexpected response for '%s'", domain), MsgOut: r}
+		}
+	}
+
+	return nil, nil
+}
@@ -61,6 +61,10 @@ func NewChallenge(core *api.Core, validate ValidateFunc, provider challenge.Prov
 		dnsTimeout: 10 * time.Second,
 	}
 
+	if p, ok := provider.(PropagationProvider); ok {
+		chlg.preCheck.propagationProvider = p
+	}
+
 	for _, opt := range opts {
 		err := opt(chlg)
 		if err != nil {
@@ -115,8 +119,23 @@ func (c *Challenge) Solve(authz acme.Authorization) error {
 		return err
 	}
 
-	info := GetChallengeInfo(authz.Identifier.Value, keyAuth)
+	err = c.waitForPropagation(domain, GetChallengeInfo(authz.Identifier.Value, keyAuth))
+	if err != nil {
+		return err
+	}
+
+	chlng.KeyAuthorization = keyAuth
+	return c.validate(c.core, domain, chlng)
+}
 
+// CheckPropagation waits for the propagation of the TXT record presented for the given domain and key authorization,
+// by using the configured propagation checks.
+// It doesn't contact the ACME server.
+func (c *Challenge) CheckPropagation(domain, keyAuth string) error {
+	return c.waitForPropagation(domain, GetChallengeInfo(domain, keyAuth))
+}
+
+func (c *Challenge) waitForPropagation(domain string, info ChallengeInfo) error {
 	var timeout, interval time.Duration
 	switch provider := c.provider.(type) {
 	case challenge.ProviderTimeout:
@@ -125,23 +144,19 @@ func (c *Challenge) Solve(authz acme.Authorization) error {
 		timeout, interval = DefaultPropagationTimeout, DefaultPollingInterval
 	}
 
-	log.Infof("[%s] acme: Checking DNS record propagation. [nameservers=%s]", domain, strings.Join(recursiveNameservers, ","))
+	log.Infof("[%s] acme: Checking DNS record propagation. [nameservers=%s]", domain, strings.Join(propagationNameservers(info.EffectiveFQDN), ","))
 
 	time.Sleep(interval)
 
-	err = wait.For("propagation", timeout, interval, func() (bool, error) {
+	return wait.For("propagation", timeout, interval, func() (bool, error) {
 		stop, errP := c.preCheck.call(domain, info.EffectiveFQDN, info.Value)
-		if !stop || errP != nil {
+		if errP != nil {
+			log.Infof("[%s] acme: Waiting for DNS record propagation: %v", domain, errP)
+		} else if !stop {
 			log.Infof("[%s] acme: Waiting for DNS record propagation.", domain)
 		}
 		return stop, errP
 	})
-	if err != nil {
-		return err
-	}
-
-	chlng.KeyAuthorization = keyAuth
-	return c.validate(c.core, domain, chlng)
 }
 
 // CleanUp cleans the challenge.
@@ -217,7 +232,7 @@ func getChallengeFQDN(domain string, followCNAME bool) string {
 	// recursion counter so it doesn't spin out of control
 	for range 50 {
 		// Keep following CNAMEs
-		r, err := dnsQuery(fqdn, dns.TypeCNAME, recursiveNameservers, true)
+		r, err := dnsQuery(fqdn, dns.TypeCNAME, discoveryNameservers(fqdn), true)
 
 		if err != nil || r.Rcode != dns.RcodeSuccess {
 			// No more CNAME records to follow, exit
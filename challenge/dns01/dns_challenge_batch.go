@@ -0,0 +1,161 @@
+package dns01
+
+import (
+	"fmt"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/log"
+)
+
+// BatchRecord contains the information about the TXT record of a challenge.
+type BatchRecord struct {
+	// Domain is the domain of the challenge (without the wildcard prefix).
+	Domain  string
+	Token   string
+	KeyAuth string
+
+	// Info contains the FQDN and the value of the TXT record.
+	Info ChallengeInfo
+}
+
+// BatchProvider allows for implementing a challenge.Provider
+// able to create and delete the TXT records of several challenges with one changeset per zone.
+// When a provider implements BatchProvider,
+// the records of all the authorizations of an order are grouped by zone,
+// and PresentBatch and CleanUpBatch are called once per zone instead of calling Present and CleanUp for each record.
+// If the provider is also sequential, the authorizations are solved one by one,
+// and PresentBatch and CleanUpBatch receive the records of one authorization at a time.
+// The zone is the FQDN of the authoritative zone.
+type BatchProvider interface {
+	challenge.Provider
+	PresentBatch(zone string, records []BatchRecord) error
+	CleanUpBatch(zone string, records []BatchRecord) error
+}
+
+// zoneBatch the records of a zone and the targeted domains associated with them.
+type zoneBatch struct {
+	zone    string
+	domains []string
+	records []BatchRecord
+}
+
+// PreSolveBatch submits the TXT records of several authorizations to the DNS provider.
+// If the provider implements BatchProvider, the records are submitted with one call per zone,
+// otherwise each record is submitted with PreSolve.
+// It returns the errors by targeted domain.
+func (c *Challenge) PreSolveBatch(authorizations []acme.Authorization) map[string]error {
+	provider, ok := c.provider.(BatchProvider)
+	if !ok {
+		failures := make(map[string]error)
+
+		for _, authz := range authorizations {
+			err := c.PreSolve(authz)
+			if err != nil {
+				failures[challenge.GetTargetedDomain(authz)] = err
+			}
+		}
+
+		return failures
+	}
+
+	batches, failures := c.groupByZone(authorizations)
+
+	for _, batch := range batches {
+		log.Infof("[%s] acme: Preparing to solve DNS-01 for %d records", batch.zone, len(batch.records))
+
+		err := provider.PresentBatch(batch.zone, batch.records)
+		if err != nil {
+			for _, domain := range batch.domains {
+				failures[domain] = fmt.Errorf("[%s] acme: error presenting token: %w", domain, err)
+			}
+		}
+	}
+
+	return failures
+}
+
+// CleanUpBatch cleans the challenges of several authorizations.
+// If the provider implements BatchProvider, the records are deleted with one call per zone,
+// otherwise each record is deleted with CleanUp.
+// It returns the errors by targeted domain.
+func (c *Challenge) CleanUpBatch(authorizations []acme.Authorization) map[string]error {
+	provider, ok := c.provider.(BatchProvider)
+	if !ok {
+		failures := make(map[string]error)
+
+		for _, authz := range authorizations {
+			err := c.CleanUp(authz)
+			if err != nil {
+				failures[challenge.GetTargetedDomain(authz)] = err
+			}
+		}
+
+		return failures
+	}
+
+	batches, failures := c.groupByZone(authorizations)
+
+	for _, batch := range batches {
+		log.Infof("[%s] acme: Cleaning DNS-01 challenge for %d records", batch.zone, len(batch.records))
+
+		err := provider.CleanUpBatch(batch.zone, batch.records)
+		if err != nil {
+			for _, domain := range batch.domains {
+				failures[domain] = err
+			}
+		}
+	}
+
+	return failures
+}
+
+// groupByZone groups the records of the authorizations by authoritative zone.
+// The batches are in the order of the authorizations.
+func (c *Challenge) groupByZone(authorizations []acme.Authorization) ([]*zoneBatch, map[string]error) {
+	failures := make(map[string]error)
+
+	var batches []*zoneBatch
+	index := make(map[string]*zoneBatch)
+
+	for _, authz := range authorizations {
+		domain := challenge.GetTargetedDomain(authz)
+
+		chlng, err := challenge.FindChallenge(challenge.DNS01, authz)
+		if err != nil {
+			failures[domain] = err
+			continue
+		}
+
+		keyAuth, err := c.core.GetKeyAuthorization(chlng.Token)
+		if err != nil {
+			failures[domain] = err
+			continue
+		}
+
+		info := GetChallengeInfo(authz.Identifier.Value, keyAuth)
+
+		zone, err := FindZoneByFqdn(info.EffectiveFQDN)
+		if err != nil {
+			failures[domain] = fmt.Errorf("[%s] acme: could not find zone: %w", domain, err)
+			continue
+		}
+
+		batch, ok := index[zone]
+		if !ok {
+			batch = &zoneBatch{zone: zone}
+			index[zone] = batch
+			batches = append(batches, batch)
+		}
+
+		batch.domains = append(batch.domains, domain)
+		batch.records = append(batch.records, BatchRecord{
+			Domain:  authz.Identifier.Value,
+			Token:   chlng.Token,
+			KeyAuth: keyAuth,
+			Info:    info,
+		})
+	}
+
+	return batches, failures
+}
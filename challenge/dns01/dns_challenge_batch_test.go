@@ -0,0 +1,201 @@
+package dns01
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/acme/api"
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type providerBatchMock struct {
+	providerMock
+
+	presentBatch, cleanUpBatch map[string]error
+
+	mu       sync.Mutex
+	presents map[string][]string
+	cleanUps map[string][]string
+}
+
+func (p *providerBatchMock) PresentBatch(zone string, records []BatchRecord) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, record := range records {
+		p.presents[zone] = append(p.presents[zone], record.Info.EffectiveFQDN)
+	}
+
+	return p.presentBatch[zone]
+}
+
+func (p *providerBatchMock) CleanUpBatch(zone string, records []BatchRecord) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, record := range records {
+		p.cleanUps[zone] = append(p.cleanUps[zone], record.Info.EffectiveFQDN)
+	}
+
+	return p.cleanUpBatch[zone]
+}
+
+func TestChallenge_PreSolveBatch(t *testing.T) {
+	setupSOATestServer(t, "example.com.", "example.org.")
+
+	core := setupBatchTestCore(t)
+
+	provider := &providerBatchMock{
+		presentBatch: map[string]error{"example.org.": errors.New("OOPS")},
+		presents:     make(map[string][]string),
+	}
+
+	chlg := NewChallenge(core, nil, provider)
+
+	failures := chlg.PreSolveBatch([]acme.Authorization{
+		createBatchTestAuthorization("example.com", false),
+		createBatchTestAuthorization("example.com", true),
+		createBatchTestAuthorization("www.example.com", false),
+		createBatchTestAuthorization("example.org", false),
+	})
+
+	expected := map[string][]string{
+		"example.com.": {"_acme-challenge.example.com.", "_acme-challenge.example.com.", "_acme-challenge.www.example.com."},
+		"example.org.": {"_acme-challenge.example.org."},
+	}
+
+	assert.Equal(t, expected, provider.presents)
+
+	require.Len(t, failures, 1)
+	require.EqualError(t, failures["example.org"], "[example.org] acme: error presenting token: OOPS")
+}
+
+func TestChallenge_CleanUpBatch(t *testing.T) {
+	setupSOATestServer(t, "example.com.", "example.org.")
+
+	core := setupBatchTestCore(t)
+
+	provider := &providerBatchMock{
+		cleanUpBatch: map[string]error{"example.com.": errors.New("OOPS")},
+		cleanUps:     make(map[string][]string),
+	}
+
+	chlg := NewChallenge(core, nil, provider)
+
+	failures := chlg.CleanUpBatch([]acme.Authorization{
+		createBatchTestAuthorization("example.com", true),
+		createBatchTestAuthorization("example.org", false),
+	})
+
+	expected := map[string][]string{
+		"example.com.": {"_acme-challenge.example.com."},
+		"example.org.": {"_acme-challenge.example.org."},
+	}
+
+	assert.Equal(t, expected, provider.cleanUps)
+
+	require.Len(t, failures, 1)
+	require.EqualError(t, failures["*.example.com"], "OOPS")
+}
+
+func TestChallenge_PreSolveBatch_notBatchProvider(t *testing.T) {
+	core := setupBatchTestCore(t)
+
+	chlg := NewChallenge(core, nil, &providerMock{present: errors.New("OOPS")})
+
+	failures := chlg.PreSolveBatch([]acme.Authorization{
+		createBatchTestAuthorization("example.com", false),
+		createBatchTestAuthorization("example.org", false),
+	})
+
+	require.Len(t, failures, 2)
+	require.EqualError(t, failures["example.com"], "[example.com] acme: error presenting token: OOPS")
+	require.EqualError(t, failures["example.org"], "[example.org] acme: error presenting token: OOPS")
+}
+
+func setupBatchTestCore(t *testing.T) *api.Core {
+	t.Helper()
+
+	_, apiURL := tester.SetupFakeAPI(t)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	return core
+}
+
+func createBatchTestAuthorization(domain string, wildcard bool) acme.Authorization {
+	return acme.Authorization{
+		Identifier: acme.Identifier{Value: domain},
+		Wildcard:   wildcard,
+		Challenges: []acme.Challenge{
+			{Type: challenge.DNS01.String(), Token: "token-" + domain},
+		},
+	}
+}
+
+// setupSOATestServer runs a DNS server which knows only the SOA records of the given zones,
+// and uses it as recursive nameserver.
+func setupSOATestServer(t *testing.T, zones ...string) {
+	t.Helper()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &dns.Server{
+		PacketConn: pc,
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+			m := new(dns.Msg)
+			m.SetReply(req)
+
+			q := req.Question[0]
+			for _, zone := range zones {
+				if q.Qtype == dns.TypeSOA && q.Name == zone {
+					m.Answer = []dns.RR{&dns.SOA{
+						Hdr:    dns.RR_Header{Name: zone, Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: 60},
+						Ns:     "ns1." + zone,
+						Mbox:   "hostmaster." + zone,
+						Serial: 1,
+					}}
+				}
+			}
+
+			_ = w.WriteMsg(m)
+		}),
+	}
+
+	waitLock := sync.Mutex{}
+	waitLock.Lock()
+	server.NotifyStartedFunc = waitLock.Unlock
+
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+
+	waitLock.Lock()
+
+	original := recursiveNameservers
+
+	t.Cleanup(func() {
+		_ = server.Shutdown()
+		recursiveNameservers = original
+		ClearFqdnCache()
+	})
+
+	recursiveNameservers = []string{pc.LocalAddr().String()}
+
+	ClearFqdnCache()
+}
@@ -0,0 +1,482 @@
+package dns01
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// rootTrustAnchors are the DS records of the root zone KSKs (KSK-2017 and KSK-2024).
+// https://data.iana.org/root-anchors/root-anchors.xml
+var rootTrustAnchors = []string{
+	". 0 IN DS 20326 8 2 E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBC683457104237C7F8EC8D",
+	". 0 IN DS 38696 8 2 683D2D0ACB8C9B712A1948B27F741219298D0A450D612C483AF444A4C0FB2B16",
+}
+
+// dnssecValidator is used to validate DNS responses (nil: DNSSEC validation disabled).
+var dnssecValidator *validator
+
+// ValidateDNSSEC enables the DNSSEC validation of the responses used to find zones,
+// look up the authoritative nameservers, follow CNAMEs and check the propagation.
+// Every answer must be part of a chain of trust starting at one of the trust anchors:
+// an unsigned or bogus answer is an error, and a negative answer (NXDOMAIN or NODATA) must be proven by NSEC or NSEC3 records.
+// The DS and DNSKEY records are fetched from the propagation nameservers of the zone (see AddZoneNameservers).
+// If no trust anchor is provided, the root zone trust anchors are used.
+func ValidateDNSSEC(anchors []dns.RR) ChallengeOption {
+	return func(_ *Challenge) error {
+		v, err := newValidator(anchors)
+		if err != nil {
+			return err
+		}
+
+		dnssecValidator = v
+
+		return nil
+	}
+}
+
+// DefaultTrustAnchors returns the trust anchors of the root zone.
+func DefaultTrustAnchors() []dns.RR {
+	var anchors []dns.RR
+	for _, s := range rootTrustAnchors {
+		rr, err := dns.NewRR(s)
+		if err != nil {
+			// the built-in trust anchors are always valid.
+			panic(err)
+		}
+
+		anchors = append(anchors, rr)
+	}
+
+	return anchors
+}
+
+// ReadTrustAnchors reads DS or DNSKEY records from a file in the zone file format.
+func ReadTrustAnchors(filename string) ([]dns.RR, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	return ParseTrustAnchors(file, filename)
+}
+
+// ParseTrustAnchors parses DS or DNSKEY records in the zone file format.
+func ParseTrustAnchors(r io.Reader, filename string) ([]dns.RR, error) {
+	var anchors []dns.RR
+
+	zp := dns.NewZoneParser(r, ".", filename)
+	for rr, ok := zp.Next(); ok; rr, ok = zp.Next() {
+		switch rr.(type) {
+		case *dns.DS, *dns.DNSKEY:
+			anchors = append(anchors, rr)
+		default:
+			return nil, fmt.Errorf("unsupported trust anchor type: %s", dns.TypeToString[rr.Header().Rrtype])
+		}
+	}
+
+	if err := zp.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(anchors) == 0 {
+		return nil, errors.New("no trust anchor found")
+	}
+
+	return anchors, nil
+}
+
+// DNSSECError error related to the DNSSEC validation.
+type DNSSECError struct {
+	Message string
+	Name    string
+	Type    uint16
+	NS      string
+	Err     error
+}
+
+func (d *DNSSECError) Error() string {
+	var details []string
+	if d.NS != "" {
+		details = append(details, "ns="+d.NS)
+	}
+
+	if d.Name != "" {
+		details = append(details, fmt.Sprintf("rrset='%s %s'", d.Name, dns.TypeToString[d.Type]))
+	}
+
+	msg := "DNSSEC validation failed"
+	if d.Message != "" {
+		msg += ": " + d.Message
+	}
+
+	if d.Err != nil {
+		msg += ": " + d.Err.Error()
+	}
+
+	if len(details) > 0 {
+		msg += " [" + strings.Join(details, ", ") + "]"
+	}
+
+	return msg
+}
+
+func (d *DNSSECError) Unwrap() error {
+	return d.Err
+}
+
+// zoneKeysEntry holds the validated DNSKEYs of a zone.
+type zoneKeysEntry struct {
+	keys    []*dns.DNSKEY
+	expires time.Time
+}
+
+type validator struct {
+	// trust anchors indexed by zone.
+	anchors map[string][]*dns.DS
+
+	// validated DNSKEYs indexed by zone.
+	keys *sync.Map
+}
+
+func newValidator(anchors []dns.RR) (*validator, error) {
+	if len(anchors) == 0 {
+		anchors = DefaultTrustAnchors()
+	}
+
+	v := &validator{
+		anchors: make(map[string][]*dns.DS),
+		keys:    &sync.Map{},
+	}
+
+	for _, anchor := range anchors {
+		var ds *dns.DS
+
+		switch rr := anchor.(type) {
+		case *dns.DS:
+			ds = rr
+		case *dns.DNSKEY:
+			ds = rr.ToDS(dns.SHA256)
+			if ds == nil {
+				return nil, fmt.Errorf("invalid DNSKEY trust anchor: %s", rr)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported trust anchor type: %s", dns.TypeToString[anchor.Header().Rrtype])
+		}
+
+		zone := dns.CanonicalName(ds.Hdr.Name)
+		v.anchors[zone] = append(v.anchors[zone], ds)
+	}
+
+	return v, nil
+}
+
+// validateMsg validates the answer section of a response,
+// or the authority section (SOA, NSEC, NSEC3) of a negative response (NXDOMAIN or NODATA).
+// A negative response must contain the NSEC or NSEC3 records proving the denial of existence.
+// The DS and DNSKEY records of the chain of trust are fetched from the nameservers used for the propagation checks of the queried name.
+func (v *validator) validateMsg(msg *dns.Msg) error {
+	if msg.Rcode != dns.RcodeSuccess && msg.Rcode != dns.RcodeNameError {
+		return nil
+	}
+
+	nameservers := recursiveNameservers
+	if len(msg.Question) > 0 {
+		nameservers = propagationNameservers(msg.Question[0].Name)
+	}
+
+	if len(msg.Answer) > 0 {
+		return v.validateRRs(msg.Answer, nameservers)
+	}
+
+	if len(msg.Ns) == 0 {
+		return &DNSSECError{Message: "no records to validate in the response"}
+	}
+
+	err := v.validateRRs(msg.Ns, nameservers)
+	if err != nil {
+		return err
+	}
+
+	return verifyDenial(msg)
+}
+
+// validateRRs validates all the RRsets contained in a list of records.
+func (v *validator) validateRRs(rrs []dns.RR, nameservers []string) error {
+	type setKey struct {
+		name  string
+		rtype uint16
+	}
+
+	var order []setKey
+	sets := make(map[setKey][]dns.RR)
+	sigs := make(map[setKey][]*dns.RRSIG)
+
+	for _, rr := range rrs {
+		if sig, ok := rr.(*dns.RRSIG); ok {
+			k := setKey{name: dns.CanonicalName(sig.Hdr.Name), rtype: sig.TypeCovered}
+			sigs[k] = append(sigs[k], sig)
+			continue
+		}
+
+		if rr.Header().Rrtype == dns.TypeOPT {
+			continue
+		}
+
+		k := setKey{name: dns.CanonicalName(rr.Header().Name), rtype: rr.Header().Rrtype}
+		if _, ok := sets[k]; !ok {
+			order = append(order, k)
+		}
+
+		sets[k] = append(sets[k], rr)
+	}
+
+	for _, k := range order {
+		err := v.validateRRset(sets[k], sigs[k], nameservers)
+		if err != nil {
+			return &DNSSECError{Name: k.name, Type: k.rtype, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// validateRRset checks that at least one of the signatures of the RRset is valid
+// and has been made by a key of a zone that is part of the chain of trust.
+func (v *validator) validateRRset(rrset []dns.RR, sigs []*dns.RRSIG, nameservers []string) error {
+	if len(sigs) == 0 {
+		return errors.New("unsigned RRset")
+	}
+
+	owner := rrset[0].Header().Name
+
+	var errAll error
+
+	for _, sig := range sigs {
+		if !dns.IsSubDomain(sig.SignerName, owner) {
+			errAll = errors.Join(errAll, fmt.Errorf("signer %s is not a parent of %s", sig.SignerName, owner))
+			continue
+		}
+
+		if !sig.ValidityPeriod(time.Time{}) {
+			errAll = errors.Join(errAll, fmt.Errorf("signature of %s (key tag %d) is expired or not yet valid", sig.SignerName, sig.KeyTag))
+			continue
+		}
+
+		keys, err := v.zoneKeys(sig.SignerName, nameservers)
+		if err != nil {
+			return err
+		}
+
+		err = verifySignature(sig, keys, rrset)
+		if err == nil {
+			return nil
+		}
+
+		errAll = errors.Join(errAll, err)
+	}
+
+	return fmt.Errorf("bogus RRset: %w", errAll)
+}
+
+// zoneKeys returns the DNSKEYs of a zone after validating them against the trust anchors,
+// or against the DS records published in the parent zone.
+// The keys are cached by zone and nameservers: the views of a split-horizon zone can have different keys.
+func (v *validator) zoneKeys(zone string, nameservers []string) ([]*dns.DNSKEY, error) {
+	zone = dns.CanonicalName(zone)
+
+	cacheKey := soaCacheKey(zone, nameservers)
+
+	entAny, ok := v.keys.Load(cacheKey)
+	if ok && entAny != nil {
+		ent, ok1 := entAny.(*zoneKeysEntry)
+		if ok1 && time.Now().Before(ent.expires) {
+			return ent.keys, nil
+		}
+	}
+
+	dss, err := v.delegationSigners(zone, nameservers)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := validatorQuery(zone, dns.TypeDNSKEY, nameservers)
+	if err != nil {
+		return nil, &DNSSECError{Message: "DNSKEY query failed", Name: zone, Type: dns.TypeDNSKEY, Err: err}
+	}
+
+	var keys []*dns.DNSKEY
+	var rrset []dns.RR
+	var sigs []*dns.RRSIG
+
+	for _, rr := range r.Answer {
+		switch record := rr.(type) {
+		case *dns.DNSKEY:
+			if strings.EqualFold(record.Hdr.Name, zone) {
+				keys = append(keys, record)
+				rrset = append(rrset, record)
+			}
+		case *dns.RRSIG:
+			if record.TypeCovered == dns.TypeDNSKEY && strings.EqualFold(record.Hdr.Name, zone) {
+				sigs = append(sigs, record)
+			}
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, &DNSSECError{Message: "no DNSKEY found", Name: zone, Type: dns.TypeDNSKEY}
+	}
+
+	// the DNSKEY RRset must be signed by one of the keys referenced by a DS record.
+	var secureEntryPoints []*dns.DNSKEY
+	for _, key := range keys {
+		if matchDS(key, dss) {
+			secureEntryPoints = append(secureEntryPoints, key)
+		}
+	}
+
+	if len(secureEntryPoints) == 0 {
+		return nil, &DNSSECError{Message: "no DNSKEY matches the DS records", Name: zone, Type: dns.TypeDNSKEY}
+	}
+
+	var errAll error
+
+	for _, sig := range sigs {
+		if !sig.ValidityPeriod(time.Time{}) {
+			continue
+		}
+
+		err = verifySignature(sig, secureEntryPoints, rrset)
+		if err != nil {
+			errAll = errors.Join(errAll, err)
+			continue
+		}
+
+		ttl := time.Duration(rrset[0].Header().Ttl) * time.Second
+		v.keys.Store(cacheKey, &zoneKeysEntry{keys: keys, expires: time.Now().Add(ttl)})
+
+		return keys, nil
+	}
+
+	return nil, &DNSSECError{Message: "bogus DNSKEY RRset", Name: zone, Type: dns.TypeDNSKEY, Err: errAll}
+}
+
+// delegationSigners returns the trust anchors of a zone,
+// or the validated DS records of the zone published in the parent zone.
+func (v *validator) delegationSigners(zone string, nameservers []string) ([]*dns.DS, error) {
+	if anchors, ok := v.anchors[zone]; ok {
+		return anchors, nil
+	}
+
+	if zone == "." {
+		return nil, &DNSSECError{Message: "no trust anchor for the root zone", Name: zone, Type: dns.TypeDS}
+	}
+
+	r, err := validatorQuery(zone, dns.TypeDS, nameservers)
+	if err != nil {
+		return nil, &DNSSECError{Message: "DS query failed", Name: zone, Type: dns.TypeDS, Err: err}
+	}
+
+	var dss []*dns.DS
+	var rrs []dns.RR
+
+	for _, rr := range r.Answer {
+		switch record := rr.(type) {
+		case *dns.DS:
+			if strings.EqualFold(record.Hdr.Name, zone) {
+				dss = append(dss, record)
+				rrs = append(rrs, record)
+			}
+		case *dns.RRSIG:
+			if record.TypeCovered == dns.TypeDS && strings.EqualFold(record.Hdr.Name, zone) {
+				rrs = append(rrs, record)
+			}
+		}
+	}
+
+	if len(dss) == 0 {
+		return nil, &DNSSECError{Message: "no DS record found (insecure delegation)", Name: zone, Type: dns.TypeDS}
+	}
+
+	// The DS RRset is signed by the parent zone.
+	err = v.validateRRs(rrs, nameservers)
+	if err != nil {
+		return nil, err
+	}
+
+	return dss, nil
+}
+
+func verifySignature(sig *dns.RRSIG, keys []*dns.DNSKEY, rrset []dns.RR) error {
+	var errAll error
+
+	for _, key := range keys {
+		if key.KeyTag() != sig.KeyTag || key.Algorithm != sig.Algorithm {
+			continue
+		}
+
+		err := sig.Verify(key, rrset)
+		if err == nil {
+			return nil
+		}
+
+		errAll = errors.Join(errAll, fmt.Errorf("key tag %d: %w", sig.KeyTag, err))
+	}
+
+	if errAll == nil {
+		return fmt.Errorf("no DNSKEY of %s with the key tag %d", sig.SignerName, sig.KeyTag)
+	}
+
+	return errAll
+}
+
+func matchDS(key *dns.DNSKEY, dss []*dns.DS) bool {
+	for _, ds := range dss {
+		if key.KeyTag() != ds.KeyTag || key.Algorithm != ds.Algorithm {
+			continue
+		}
+
+		keyDS := key.ToDS(ds.DigestType)
+		if keyDS != nil && strings.EqualFold(keyDS.Digest, ds.Digest) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validatorQuery sends a DNSSEC query to the nameservers without validating the response.
+func validatorQuery(fqdn string, rtype uint16, nameservers []string) (*dns.Msg, error) {
+	m := createDNSMsg(fqdn, rtype, true)
+
+	var errAll error
+
+	for _, ns := range nameservers {
+		r, err := sendDNSQuery(m, ns)
+		if err != nil {
+			errAll = errors.Join(errAll, err)
+			continue
+		}
+
+		if r.Rcode != dns.RcodeSuccess {
+			errAll = errors.Join(errAll, &DNSError{Message: "unexpected response", NS: ns, MsgIn: m, MsgOut: r})
+			continue
+		}
+
+		return r, nil
+	}
+
+	if errAll == nil {
+		return nil, &DNSError{Message: "empty list of nameservers"}
+	}
+
+	return nil, errAll
+}
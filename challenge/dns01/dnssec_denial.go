@@ -0,0 +1,203 @@
+package dns01
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// verifyDenial checks that the NSEC or NSEC3 records of a negative response (already validated)
+// prove that the queried name (NXDOMAIN) or type (NODATA) doesn't exist.
+// - https://www.rfc-editor.org/rfc/rfc4035.html#section-5.4
+// - https://www.rfc-editor.org/rfc/rfc5155.html#section-8
+func verifyDenial(msg *dns.Msg) error {
+	if len(msg.Question) == 0 {
+		return &DNSSECError{Message: "no question in the negative response"}
+	}
+
+	question := msg.Question[0]
+
+	var nsecs []*dns.NSEC
+	var nsec3s []*dns.NSEC3
+
+	for _, rr := range msg.Ns {
+		switch record := rr.(type) {
+		case *dns.NSEC:
+			nsecs = append(nsecs, record)
+		case *dns.NSEC3:
+			nsec3s = append(nsec3s, record)
+		}
+	}
+
+	nxdomain := msg.Rcode == dns.RcodeNameError
+
+	var err error
+
+	switch {
+	case len(nsecs) > 0:
+		err = verifyNSEC(nsecs, dns.CanonicalName(question.Name), question.Qtype, nxdomain)
+	case len(nsec3s) > 0:
+		err = verifyNSEC3(nsec3s, dns.CanonicalName(question.Name), question.Qtype, nxdomain)
+	default:
+		err = errors.New("no NSEC or NSEC3 record")
+	}
+
+	if err != nil {
+		return &DNSSECError{Message: "denial of existence not proven", Name: question.Name, Type: question.Qtype, Err: err}
+	}
+
+	return nil
+}
+
+// verifyNSEC checks the NSEC proof of a negative response.
+// NODATA: an NSEC record of the name without the type, or covering an empty non-terminal.
+// NXDOMAIN: an NSEC record covering the name, and an NSEC record covering the wildcard of the closest encloser.
+func verifyNSEC(nsecs []*dns.NSEC, qname string, qtype uint16, nxdomain bool) error {
+	if !nxdomain {
+		for _, nsec := range nsecs {
+			if dns.CanonicalName(nsec.Hdr.Name) == qname {
+				return checkTypeBitMap(nsec.TypeBitMap, qtype)
+			}
+		}
+
+		for _, nsec := range nsecs {
+			if nsecCovers(nsec, qname) && dns.IsSubDomain(qname, dns.CanonicalName(nsec.NextDomain)) {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("no NSEC record proves that the type %s of %s doesn't exist", dns.TypeToString[qtype], qname)
+	}
+
+	idx := slices.IndexFunc(nsecs, func(nsec *dns.NSEC) bool { return nsecCovers(nsec, qname) })
+	if idx < 0 {
+		return fmt.Errorf("no NSEC record proves that %s doesn't exist", qname)
+	}
+
+	wildcard := wildcardName(nsecClosestEncloser(qname, nsecs[idx]))
+
+	if !slices.ContainsFunc(nsecs, func(nsec *dns.NSEC) bool { return nsecCovers(nsec, wildcard) }) {
+		return fmt.Errorf("no NSEC record proves that %s doesn't exist", wildcard)
+	}
+
+	return nil
+}
+
+// verifyNSEC3 checks the NSEC3 proof of a negative response.
+// NODATA: an NSEC3 record matching the name without the type.
+// NXDOMAIN: the closest encloser proof, and an NSEC3 record covering the wildcard of the closest encloser.
+func verifyNSEC3(nsec3s []*dns.NSEC3, qname string, qtype uint16, nxdomain bool) error {
+	if !nxdomain {
+		for _, nsec3 := range nsec3s {
+			if nsec3.Match(qname) {
+				return checkTypeBitMap(nsec3.TypeBitMap, qtype)
+			}
+		}
+
+		return fmt.Errorf("no NSEC3 record proves that the type %s of %s doesn't exist", dns.TypeToString[qtype], qname)
+	}
+
+	matches := func(name string) bool {
+		return slices.ContainsFunc(nsec3s, func(nsec3 *dns.NSEC3) bool { return nsec3.Match(name) })
+	}
+
+	covers := func(name string) bool {
+		return slices.ContainsFunc(nsec3s, func(nsec3 *dns.NSEC3) bool { return nsec3.Cover(name) })
+	}
+
+	// https://www.rfc-editor.org/rfc/rfc5155.html#section-8.3
+	closestEncloser, nextCloser := "", ""
+
+	for name := qname; ; name = parentName(name) {
+		if matches(name) {
+			closestEncloser = name
+			break
+		}
+
+		if name == "." {
+			break
+		}
+
+		nextCloser = name
+	}
+
+	switch {
+	case closestEncloser == "":
+		return fmt.Errorf("no NSEC3 record matches the closest encloser of %s", qname)
+	case nextCloser == "":
+		return fmt.Errorf("an NSEC3 record matches %s", qname)
+	case !covers(nextCloser):
+		return fmt.Errorf("no NSEC3 record proves that %s doesn't exist", nextCloser)
+	case !covers(wildcardName(closestEncloser)):
+		return fmt.Errorf("no NSEC3 record proves that %s doesn't exist", wildcardName(closestEncloser))
+	default:
+		return nil
+	}
+}
+
+// checkTypeBitMap checks that the type (or a CNAME) is not in the type bit map of the NSEC/NSEC3 record of a name.
+func checkTypeBitMap(bitmap []uint16, qtype uint16) error {
+	if slices.Contains(bitmap, qtype) || slices.Contains(bitmap, dns.TypeCNAME) {
+		return fmt.Errorf("the type bit map contains %s or CNAME", dns.TypeToString[qtype])
+	}
+
+	return nil
+}
+
+// nsecCovers returns true if the name is strictly between the owner and the next name of the NSEC record.
+func nsecCovers(nsec *dns.NSEC, name string) bool {
+	owner := nsec.Hdr.Name
+	next := nsec.NextDomain
+
+	if canonicalCompare(owner, next) < 0 {
+		return canonicalCompare(owner, name) < 0 && canonicalCompare(name, next) < 0
+	}
+
+	// the last NSEC record of the zone: the next name is the apex.
+	return canonicalCompare(owner, name) < 0 || canonicalCompare(name, next) < 0
+}
+
+// nsecClosestEncloser returns the closest encloser of a name covered by the NSEC record:
+// the longest common ancestor of the name with the owner or the next name of the NSEC record.
+func nsecClosestEncloser(qname string, nsec *dns.NSEC) string {
+	n := max(dns.CompareDomainName(qname, nsec.Hdr.Name), dns.CompareDomainName(qname, nsec.NextDomain))
+
+	labels := dns.SplitDomainName(qname)
+
+	return dns.Fqdn(strings.Join(labels[len(labels)-n:], "."))
+}
+
+// canonicalCompare compares two names in the canonical DNS name order.
+// - https://www.rfc-editor.org/rfc/rfc4034.html#section-6.1
+func canonicalCompare(a, b string) int {
+	la := dns.SplitDomainName(dns.CanonicalName(a))
+	lb := dns.SplitDomainName(dns.CanonicalName(b))
+
+	for i, j := len(la)-1, len(lb)-1; i >= 0 && j >= 0; i, j = i-1, j-1 {
+		if c := strings.Compare(la[i], lb[j]); c != 0 {
+			return c
+		}
+	}
+
+	return len(la) - len(lb)
+}
+
+func parentName(name string) string {
+	labels := dns.Split(name)
+	if len(labels) < 2 {
+		return "."
+	}
+
+	return name[labels[1]:]
+}
+
+func wildcardName(name string) string {
+	if name == "." {
+		return "*."
+	}
+
+	return "*." + name
+}
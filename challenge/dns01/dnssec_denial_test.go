@@ -0,0 +1,234 @@
+package dns01
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_verifyNSEC(t *testing.T) {
+	nsec := func(owner, next string, types ...uint16) *dns.NSEC {
+		return &dns.NSEC{
+			Hdr:        dns.RR_Header{Name: owner, Rrtype: dns.TypeNSEC, Class: dns.ClassINET},
+			NextDomain: next,
+			TypeBitMap: types,
+		}
+	}
+
+	apex := nsec("example.com.", "a.example.com.", dns.TypeSOA, dns.TypeNS)
+	a := nsec("a.example.com.", "www.example.com.", dns.TypeA)
+	www := nsec("www.example.com.", "example.com.", dns.TypeA)
+
+	testCases := []struct {
+		desc     string
+		nsecs    []*dns.NSEC
+		qname    string
+		qtype    uint16
+		nxdomain bool
+		err      string
+	}{
+		{
+			desc:     "NXDOMAIN",
+			nsecs:    []*dns.NSEC{apex, a},
+			qname:    "b.example.com.",
+			qtype:    dns.TypeTXT,
+			nxdomain: true,
+		},
+		{
+			desc:     "NXDOMAIN after the last name",
+			nsecs:    []*dns.NSEC{apex, www},
+			qname:    "z.example.com.",
+			qtype:    dns.TypeTXT,
+			nxdomain: true,
+		},
+		{
+			desc:     "NXDOMAIN without wildcard proof",
+			nsecs:    []*dns.NSEC{a},
+			qname:    "b.example.com.",
+			qtype:    dns.TypeTXT,
+			nxdomain: true,
+			err:      "no NSEC record proves that *.example.com. doesn't exist",
+		},
+		{
+			desc:     "NXDOMAIN for an existing name",
+			nsecs:    []*dns.NSEC{apex, a, www},
+			qname:    "www.example.com.",
+			qtype:    dns.TypeTXT,
+			nxdomain: true,
+			err:      "no NSEC record proves that www.example.com. doesn't exist",
+		},
+		{
+			desc:  "NODATA",
+			nsecs: []*dns.NSEC{www},
+			qname: "www.example.com.",
+			qtype: dns.TypeTXT,
+		},
+		{
+			desc:  "NODATA for an existing type",
+			nsecs: []*dns.NSEC{nsec("www.example.com.", "example.com.", dns.TypeA, dns.TypeTXT)},
+			qname: "www.example.com.",
+			qtype: dns.TypeTXT,
+			err:   "the type bit map contains TXT or CNAME",
+		},
+		{
+			desc:  "NODATA for a CNAME",
+			nsecs: []*dns.NSEC{nsec("www.example.com.", "example.com.", dns.TypeCNAME)},
+			qname: "www.example.com.",
+			qtype: dns.TypeTXT,
+			err:   "the type bit map contains TXT or CNAME",
+		},
+		{
+			desc:  "NODATA for an empty non-terminal",
+			nsecs: []*dns.NSEC{nsec("example.com.", "x.sub.example.com.", dns.TypeSOA)},
+			qname: "sub.example.com.",
+			qtype: dns.TypeSOA,
+		},
+		{
+			desc:  "NODATA without proof",
+			nsecs: []*dns.NSEC{a},
+			qname: "b.example.com.",
+			qtype: dns.TypeTXT,
+			err:   "no NSEC record proves that the type TXT of b.example.com. doesn't exist",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := verifyNSEC(test.nsecs, test.qname, test.qtype, test.nxdomain)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func Test_verifyNSEC3(t *testing.T) {
+	chain := newNSEC3Chain("example.com.", map[string][]uint16{
+		"example.com.":     {dns.TypeSOA, dns.TypeNS},
+		"www.example.com.": {dns.TypeA},
+	})
+
+	testCases := []struct {
+		desc     string
+		nsec3s   []*dns.NSEC3
+		qname    string
+		qtype    uint16
+		nxdomain bool
+		err      string
+	}{
+		{
+			desc:     "NXDOMAIN",
+			nsec3s:   chain,
+			qname:    "foo.example.com.",
+			qtype:    dns.TypeTXT,
+			nxdomain: true,
+		},
+		{
+			desc:     "NXDOMAIN for an existing name",
+			nsec3s:   chain,
+			qname:    "www.example.com.",
+			qtype:    dns.TypeTXT,
+			nxdomain: true,
+			err:      "an NSEC3 record matches www.example.com.",
+		},
+		{
+			desc:     "NXDOMAIN without closest encloser",
+			nsec3s:   newNSEC3Chain("example.com.", map[string][]uint16{"www.example.com.": {dns.TypeA}}),
+			qname:    "foo.example.com.",
+			qtype:    dns.TypeTXT,
+			nxdomain: true,
+			err:      "no NSEC3 record matches the closest encloser of foo.example.com.",
+		},
+		{
+			desc:   "NODATA",
+			nsec3s: chain,
+			qname:  "www.example.com.",
+			qtype:  dns.TypeTXT,
+		},
+		{
+			desc:   "NODATA for an existing type",
+			nsec3s: chain,
+			qname:  "www.example.com.",
+			qtype:  dns.TypeA,
+			err:    "the type bit map contains A or CNAME",
+		},
+		{
+			desc:   "NODATA without proof",
+			nsec3s: chain,
+			qname:  "foo.example.com.",
+			qtype:  dns.TypeTXT,
+			err:    "no NSEC3 record proves that the type TXT of foo.example.com. doesn't exist",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := verifyNSEC3(test.nsec3s, test.qname, test.qtype, test.nxdomain)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func Test_canonicalCompare(t *testing.T) {
+	// https://www.rfc-editor.org/rfc/rfc4034.html#section-6.1
+	names := []string{
+		"example.",
+		"a.example.",
+		"yljkjljk.a.example.",
+		"Z.a.example.",
+		"zABC.a.EXAMPLE.",
+		"z.example.",
+		"*.z.example.",
+	}
+
+	for i := 1; i < len(names); i++ {
+		assert.Negative(t, canonicalCompare(names[i-1], names[i]), "%s < %s", names[i-1], names[i])
+		assert.Positive(t, canonicalCompare(names[i], names[i-1]), "%s > %s", names[i], names[i-1])
+	}
+
+	assert.Zero(t, canonicalCompare("Example.com.", "example.COM."))
+}
+
+// newNSEC3Chain creates the NSEC3 chain (SHA-1, no iteration, no salt) of the names of a zone.
+func newNSEC3Chain(zone string, names map[string][]uint16) []*dns.NSEC3 {
+	type hashed struct {
+		hash  string
+		types []uint16
+	}
+
+	var entries []hashed
+	for name, types := range names {
+		entries = append(entries, hashed{hash: dns.HashName(name, dns.SHA1, 0, ""), types: types})
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].hash < entries[j].hash })
+
+	var chain []*dns.NSEC3
+
+	for i, entry := range entries {
+		chain = append(chain, &dns.NSEC3{
+			Hdr:        dns.RR_Header{Name: strings.ToLower(entry.hash) + "." + zone, Rrtype: dns.TypeNSEC3, Class: dns.ClassINET},
+			Hash:       dns.SHA1,
+			NextDomain: entries[(i+1)%len(entries)].hash,
+			TypeBitMap: entry.types,
+		})
+	}
+
+	return chain
+}
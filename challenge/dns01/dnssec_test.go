@@ -0,0 +1,359 @@
+package dns01
+
+import (
+	"crypto"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTrustAnchors(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		data     string
+		expected int
+		err      string
+	}{
+		{
+			desc:     "DS",
+			data:     ". 0 IN DS 20326 8 2 E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBC683457104237C7F8EC8D",
+			expected: 1,
+		},
+		{
+			desc: "DS and DNSKEY",
+			data: `. 0 IN DS 20326 8 2 E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBC683457104237C7F8EC8D
+example.com. 3600 IN DNSKEY 257 3 13 mdsswUyr3DPW132mOi8V9xESWE8jTo0dxCjjnopKl+GqJxpVXckHAeF+KkxLbxILfDLUT0rAK9iUzy1L53eKGQ==`,
+			expected: 2,
+		},
+		{
+			desc: "unsupported type",
+			data: "example.com. 3600 IN TXT foo",
+			err:  "unsupported trust anchor type: TXT",
+		},
+		{
+			desc: "empty",
+			data: "",
+			err:  "no trust anchor found",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			anchors, err := ParseTrustAnchors(strings.NewReader(test.data), "test")
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Len(t, anchors, test.expected)
+		})
+	}
+}
+
+func TestDefaultTrustAnchors(t *testing.T) {
+	anchors := DefaultTrustAnchors()
+
+	require.Len(t, anchors, 2)
+
+	for _, anchor := range anchors {
+		ds, ok := anchor.(*dns.DS)
+		require.True(t, ok)
+		assert.Equal(t, ".", ds.Hdr.Name)
+	}
+}
+
+func TestValidator_validateMsg(t *testing.T) {
+	zone := newSignedTestZone(t, "example.com.")
+
+	addr := runDNSSECTestServer(t, zone)
+
+	original := recursiveNameservers
+	t.Cleanup(func() { recursiveNameservers = original })
+
+	recursiveNameservers = []string{addr}
+
+	txt := zone.rr(t, `_acme-challenge.example.com. 120 IN TXT "value"`)
+
+	testCases := []struct {
+		desc    string
+		anchors []dns.RR
+		answer  func() []dns.RR
+		err     string
+	}{
+		{
+			desc:    "signed answer",
+			anchors: []dns.RR{zone.ksk},
+			answer: func() []dns.RR {
+				return []dns.RR{txt, zone.sign(t, zone.zsk, zone.zskPriv, txt)}
+			},
+		},
+		{
+			desc:    "DS trust anchor",
+			anchors: []dns.RR{zone.ksk.ToDS(dns.SHA256)},
+			answer: func() []dns.RR {
+				return []dns.RR{txt, zone.sign(t, zone.zsk, zone.zskPriv, txt)}
+			},
+		},
+		{
+			desc:    "unsigned answer",
+			anchors: []dns.RR{zone.ksk},
+			answer: func() []dns.RR {
+				return []dns.RR{txt}
+			},
+			err: "DNSSEC validation failed: unsigned RRset [rrset='_acme-challenge.example.com. TXT']",
+		},
+		{
+			desc:    "bogus answer",
+			anchors: []dns.RR{zone.ksk},
+			answer: func() []dns.RR {
+				sig := zone.sign(t, zone.zsk, zone.zskPriv, txt)
+				spoofed := zone.rr(t, `_acme-challenge.example.com. 120 IN TXT "spoofed"`)
+
+				return []dns.RR{spoofed, sig}
+			},
+			err: "DNSSEC validation failed: bogus RRset: key tag",
+		},
+		{
+			desc:    "unknown trust anchor",
+			anchors: DefaultTrustAnchors(),
+			answer: func() []dns.RR {
+				return []dns.RR{txt, zone.sign(t, zone.zsk, zone.zskPriv, txt)}
+			},
+			err: "DNSSEC validation failed: DS query failed",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			v, err := newValidator(test.anchors)
+			require.NoError(t, err)
+
+			msg := new(dns.Msg)
+			msg.SetQuestion("_acme-challenge.example.com.", dns.TypeTXT)
+			msg.Answer = test.answer()
+
+			err = v.validateMsg(msg)
+			if test.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestValidator_validateMsg_negative(t *testing.T) {
+	zone := newSignedTestZone(t, "example.com.")
+
+	addr := runDNSSECTestServer(t, zone)
+
+	original := recursiveNameservers
+	t.Cleanup(func() { recursiveNameservers = original })
+
+	recursiveNameservers = []string{addr}
+
+	soa := zone.rr(t, "example.com. 3600 IN SOA ns1.example.com. admin.example.com. 1 7200 3600 1209600 300")
+	apex := zone.rr(t, "example.com. 300 IN NSEC a.example.com. SOA NS RRSIG NSEC DNSKEY")
+	a := zone.rr(t, "a.example.com. 300 IN NSEC www.example.com. A RRSIG NSEC")
+
+	signed := func(rrs ...dns.RR) []dns.RR {
+		var result []dns.RR
+		for _, rr := range rrs {
+			result = append(result, rr, zone.sign(t, zone.zsk, zone.zskPriv, rr))
+		}
+
+		return result
+	}
+
+	testCases := []struct {
+		desc  string
+		rcode int
+		ns    []dns.RR
+		err   string
+	}{
+		{
+			desc:  "NXDOMAIN with proof",
+			rcode: dns.RcodeNameError,
+			ns:    signed(soa, apex, a),
+		},
+		{
+			desc:  "NXDOMAIN without proof",
+			rcode: dns.RcodeNameError,
+			ns:    signed(soa),
+			err:   "DNSSEC validation failed: denial of existence not proven: no NSEC or NSEC3 record [rrset='b.example.com. TXT']",
+		},
+		{
+			desc:  "NODATA replayed from another name",
+			rcode: dns.RcodeSuccess,
+			ns:    signed(soa, a),
+			err:   "DNSSEC validation failed: denial of existence not proven: no NSEC record proves that the type TXT of b.example.com. doesn't exist [rrset='b.example.com. TXT']",
+		},
+		{
+			desc:  "unsigned NSEC",
+			rcode: dns.RcodeNameError,
+			ns:    append(signed(soa, apex), a),
+			err:   "DNSSEC validation failed: unsigned RRset [rrset='a.example.com. NSEC']",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			v, err := newValidator([]dns.RR{zone.ksk})
+			require.NoError(t, err)
+
+			msg := new(dns.Msg)
+			msg.SetQuestion("b.example.com.", dns.TypeTXT)
+			msg.Rcode = test.rcode
+			msg.Ns = test.ns
+
+			err = v.validateMsg(msg)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestValidator_validateMsg_zoneNameservers(t *testing.T) {
+	zone := newSignedTestZone(t, "example.com.")
+
+	addr := runDNSSECTestServer(t, zone)
+
+	original := recursiveNameservers
+	t.Cleanup(func() {
+		recursiveNameservers = original
+		zoneNameservers.clear()
+	})
+
+	// the default recursive nameservers don't know the zone (other view).
+	recursiveNameservers = []string{"127.0.0.1:1"}
+
+	zoneNameservers.add("example.com.", nil, []string{addr})
+
+	v, err := newValidator([]dns.RR{zone.ksk})
+	require.NoError(t, err)
+
+	txt := zone.rr(t, `_acme-challenge.example.com. 120 IN TXT "value"`)
+
+	msg := new(dns.Msg)
+	msg.SetQuestion("_acme-challenge.example.com.", dns.TypeTXT)
+	msg.Answer = []dns.RR{txt, zone.sign(t, zone.zsk, zone.zskPriv, txt)}
+
+	require.NoError(t, v.validateMsg(msg))
+}
+
+type signedTestZone struct {
+	name    string
+	ksk     *dns.DNSKEY
+	kskPriv crypto.Signer
+	zsk     *dns.DNSKEY
+	zskPriv crypto.Signer
+}
+
+func newSignedTestZone(t *testing.T, name string) *signedTestZone {
+	t.Helper()
+
+	zone := &signedTestZone{name: name}
+
+	zone.ksk, zone.kskPriv = generateTestKey(t, name, 257)
+	zone.zsk, zone.zskPriv = generateTestKey(t, name, 256)
+
+	return zone
+}
+
+func (z *signedTestZone) rr(t *testing.T, s string) dns.RR {
+	t.Helper()
+
+	rr, err := dns.NewRR(s)
+	require.NoError(t, err)
+
+	return rr
+}
+
+func (z *signedTestZone) sign(t *testing.T, key *dns.DNSKEY, priv crypto.Signer, rrset ...dns.RR) *dns.RRSIG {
+	t.Helper()
+
+	sig := &dns.RRSIG{
+		Hdr:        dns.RR_Header{Name: rrset[0].Header().Name, Rrtype: dns.TypeRRSIG, Class: dns.ClassINET, Ttl: rrset[0].Header().Ttl},
+		Algorithm:  key.Algorithm,
+		Inception:  uint32(time.Now().Add(-time.Hour).Unix()),
+		Expiration: uint32(time.Now().Add(time.Hour).Unix()),
+		KeyTag:     key.KeyTag(),
+		SignerName: z.name,
+	}
+
+	require.NoError(t, sig.Sign(priv, rrset))
+
+	return sig
+}
+
+func generateTestKey(t *testing.T, zone string, flags uint16) (*dns.DNSKEY, crypto.Signer) {
+	t.Helper()
+
+	key := &dns.DNSKEY{
+		Hdr:       dns.RR_Header{Name: zone, Rrtype: dns.TypeDNSKEY, Class: dns.ClassINET, Ttl: 3600},
+		Flags:     flags,
+		Protocol:  3,
+		Algorithm: dns.ECDSAP256SHA256,
+	}
+
+	priv, err := key.Generate(256)
+	require.NoError(t, err)
+
+	signer, ok := priv.(crypto.Signer)
+	require.True(t, ok)
+
+	return key, signer
+}
+
+// runDNSSECTestServer serves the signed DNSKEY RRset of the zone.
+func runDNSSECTestServer(t *testing.T, zone *signedTestZone) string {
+	t.Helper()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &dns.Server{PacketConn: pc}
+
+	server.Handler = dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(req)
+
+		switch {
+		case req.Question[0].Qtype == dns.TypeDNSKEY && req.Question[0].Name == zone.name:
+			m.Answer = []dns.RR{zone.ksk, zone.zsk, zone.sign(t, zone.ksk, zone.kskPriv, zone.ksk, zone.zsk)}
+		default:
+			m.Rcode = dns.RcodeNameError
+		}
+
+		_ = w.WriteMsg(m)
+	})
+
+	waitLock := sync.Mutex{}
+	waitLock.Lock()
+	server.NotifyStartedFunc = waitLock.Unlock
+
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+
+	waitLock.Lock()
+
+	t.Cleanup(func() { _ = server.Shutdown() })
+
+	return pc.LocalAddr().String()
+}
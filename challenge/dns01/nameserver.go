@@ -3,7 +3,6 @@ package dns01
 import (
 	"errors"
 	"fmt"
-	"net"
 	"os"
 	"slices"
 	"strconv"
@@ -79,29 +78,31 @@ func getNameservers(path string, defaults []string) []string {
 	return ParseNameservers(config.Servers)
 }
 
+// ParseNameservers ensures that all the nameservers have a port number.
+// The nameservers can be `host[:port]`, `tls://host[:port]` (DNS-over-TLS),
+// or `https://host/path` (DNS-over-HTTPS).
 func ParseNameservers(servers []string) []string {
 	var resolvers []string
 	for _, resolver := range servers {
 		// ensure all servers have a port number
-		if _, _, err := net.SplitHostPort(resolver); err != nil {
-			resolvers = append(resolvers, net.JoinHostPort(resolver, "53"))
-		} else {
-			resolvers = append(resolvers, resolver)
-		}
+		resolvers = append(resolvers, parseNameserver(resolver))
 	}
 	return resolvers
 }
 
 // lookupNameservers returns the authoritative nameservers for the given fqdn.
+// The zone and its nameservers are resolved with the propagation nameservers of the fqdn.
 func lookupNameservers(fqdn string) ([]string, error) {
 	var authoritativeNss []string
 
-	zone, err := FindZoneByFqdn(fqdn)
+	nameservers := propagationNameservers(fqdn)
+
+	zone, err := FindZoneByFqdnCustom(fqdn, nameservers)
 	if err != nil {
 		return nil, fmt.Errorf("could not find zone: %w", err)
 	}
 
-	r, err := dnsQuery(zone, dns.TypeNS, recursiveNameservers, true)
+	r, err := dnsQuery(zone, dns.TypeNS, nameservers, true)
 	if err != nil {
 		return nil, fmt.Errorf("NS call failed: %w", err)
 	}
@@ -122,7 +123,7 @@ func lookupNameservers(fqdn string) ([]string, error) {
 // FindPrimaryNsByFqdn determines the primary nameserver of the zone apex for the given fqdn
 // by recursing up the domain labels until the nameserver returns a SOA record in the answer section.
 func FindPrimaryNsByFqdn(fqdn string) (string, error) {
-	return FindPrimaryNsByFqdnCustom(fqdn, recursiveNameservers)
+	return FindPrimaryNsByFqdnCustom(fqdn, discoveryNameservers(fqdn))
 }
 
 // FindPrimaryNsByFqdnCustom determines the primary nameserver of the zone apex for the given fqdn
@@ -138,7 +139,7 @@ func FindPrimaryNsByFqdnCustom(fqdn string, nameservers []string) (string, error
 // FindZoneByFqdn determines the zone apex for the given fqdn
 // by recursing up the domain labels until the nameserver returns a SOA record in the answer section.
 func FindZoneByFqdn(fqdn string) (string, error) {
-	return FindZoneByFqdnCustom(fqdn, recursiveNameservers)
+	return FindZoneByFqdnCustom(fqdn, discoveryNameservers(fqdn))
 }
 
 // FindZoneByFqdnCustom determines the zone apex for the given fqdn
@@ -153,7 +154,9 @@ func FindZoneByFqdnCustom(fqdn string, nameservers []string) (string, error) {
 
 func lookupSoaByFqdn(fqdn string, nameservers []string) (*soaCacheEntry, error) {
 	// Do we have it cached and is it still fresh?
-	entAny, ok := fqdnSoaCache.Load(fqdn)
+	key := soaCacheKey(fqdn, nameservers)
+
+	entAny, ok := fqdnSoaCache.Load(key)
 	if ok && entAny != nil {
 		ent, ok1 := entAny.(*soaCacheEntry)
 		if ok1 && !ent.isExpired() {
@@ -166,7 +169,7 @@ func lookupSoaByFqdn(fqdn string, nameservers []string) (*soaCacheEntry, error)
 		return nil, err
 	}
 
-	fqdnSoaCache.Store(fqdn, ent)
+	fqdnSoaCache.Store(key, ent)
 
 	return ent, nil
 }
@@ -181,6 +184,11 @@ func fetchSoaByFqdn(fqdn string, nameservers []string) (*soaCacheEntry, error) {
 
 		r, err = dnsQuery(domain, dns.TypeSOA, nameservers, true)
 		if err != nil {
+			var dnssecErr *DNSSECError
+			if errors.As(err, &dnssecErr) {
+				return nil, err
+			}
+
 			continue
 		}
 
@@ -238,6 +246,10 @@ func dnsQuery(fqdn string, rtype uint16, nameservers []string, recursive bool) (
 
 	for _, ns := range nameservers {
 		r, err = sendDNSQuery(m, ns)
+		if err == nil && dnssecValidator != nil {
+			err = validateResponse(r, ns)
+		}
+
 		if err == nil && len(r.Answer) > 0 {
 			break
 		}
@@ -255,7 +267,7 @@ func dnsQuery(fqdn string, rtype uint16, nameservers []string, recursive bool) (
 func createDNSMsg(fqdn string, rtype uint16, recursive bool) *dns.Msg {
 	m := new(dns.Msg)
 	m.SetQuestion(fqdn, rtype)
-	m.SetEdns0(4096, false)
+	m.SetEdns0(4096, dnssecValidator != nil)
 
 	if !recursive {
 		m.RecursionDesired = false
@@ -265,6 +277,24 @@ func createDNSMsg(fqdn string, rtype uint16, recursive bool) *dns.Msg {
 }
 
 func sendDNSQuery(m *dns.Msg, ns string) (*dns.Msg, error) {
+	switch {
+	case strings.HasPrefix(ns, dohScheme):
+		r, err := sendDoHQuery(m, ns)
+		if err != nil {
+			return r, &DNSError{Message: "DNS-over-HTTPS call error", MsgIn: m, NS: ns, Err: err}
+		}
+
+		return r, nil
+
+	case strings.HasPrefix(ns, dotScheme):
+		r, err := sendDoTQuery(m, strings.TrimPrefix(ns, dotScheme))
+		if err != nil {
+			return r, &DNSError{Message: "DNS-over-TLS call error", MsgIn: m, NS: ns, Err: err}
+		}
+
+		return r, nil
+	}
+
 	if ok, _ := strconv.ParseBool(os.Getenv("LEGO_EXPERIMENTAL_DNS_TCP_ONLY")); ok {
 		tcp := &dns.Client{Net: "tcp", Timeout: dnsTimeout}
 		r, _, err := tcp.Exchange(m, ns)
@@ -291,6 +321,21 @@ func sendDNSQuery(m *dns.Msg, ns string) (*dns.Msg, error) {
 	return r, nil
 }
 
+// validateResponse validates the DNSSEC chain of trust of a response.
+func validateResponse(r *dns.Msg, ns string) error {
+	err := dnssecValidator.validateMsg(r)
+	if err == nil {
+		return nil
+	}
+
+	var dnssecErr *DNSSECError
+	if errors.As(err, &dnssecErr) && dnssecErr.NS == "" {
+		dnssecErr.NS = ns
+	}
+
+	return err
+}
+
 // DNSError error related to DNS calls.
 type DNSError struct {
 	Message string
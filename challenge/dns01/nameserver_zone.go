@@ -0,0 +1,104 @@
+package dns01
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/miekg/dns"
+)
+
+// zoneNameservers are the per-zone overrides of the recursive nameservers (split-horizon DNS).
+var zoneNameservers = &zoneNameserversRegistry{}
+
+// AddZoneNameservers overrides the recursive nameservers for the domains inside a zone (split-horizon DNS).
+// The discovery nameservers are used to find the zone apex (SOA) and to follow the CNAMEs.
+// The propagation nameservers are used to check the propagation of the TXT record.
+// An empty list means that the default recursive nameservers are used.
+// When several zones match a domain, the most specific zone wins.
+func AddZoneNameservers(zone string, discovery, propagation []string) ChallengeOption {
+	return func(_ *Challenge) error {
+		if zone == "" {
+			return errors.New("empty zone")
+		}
+
+		zoneNameservers.add(zone, ParseNameservers(discovery), ParseNameservers(propagation))
+
+		return nil
+	}
+}
+
+// discoveryNameservers returns the nameservers used to find the zone of a domain.
+func discoveryNameservers(fqdn string) []string {
+	if zn := zoneNameservers.find(fqdn); zn != nil && len(zn.discovery) > 0 {
+		return zn.discovery
+	}
+
+	return recursiveNameservers
+}
+
+// propagationNameservers returns the nameservers used to check the propagation of a record.
+func propagationNameservers(fqdn string) []string {
+	if zn := zoneNameservers.find(fqdn); zn != nil && len(zn.propagation) > 0 {
+		return zn.propagation
+	}
+
+	return recursiveNameservers
+}
+
+type zoneNameserversEntry struct {
+	zone        string
+	discovery   []string
+	propagation []string
+}
+
+type zoneNameserversRegistry struct {
+	mu      sync.RWMutex
+	entries []*zoneNameserversEntry
+}
+
+func (r *zoneNameserversRegistry) add(zone string, discovery, propagation []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	zone = dns.CanonicalName(zone)
+
+	r.entries = slices.DeleteFunc(r.entries, func(e *zoneNameserversEntry) bool {
+		return e.zone == zone
+	})
+
+	r.entries = append(r.entries, &zoneNameserversEntry{zone: zone, discovery: discovery, propagation: propagation})
+
+	// the most specific zones first.
+	slices.SortStableFunc(r.entries, func(a, b *zoneNameserversEntry) int {
+		return dns.CountLabel(b.zone) - dns.CountLabel(a.zone)
+	})
+}
+
+func (r *zoneNameserversRegistry) find(fqdn string) *zoneNameserversEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	fqdn = dns.CanonicalName(fqdn)
+
+	for _, e := range r.entries {
+		if dns.IsSubDomain(e.zone, fqdn) {
+			return e
+		}
+	}
+
+	return nil
+}
+
+func (r *zoneNameserversRegistry) clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = nil
+}
+
+// soaCacheKey returns the key of the SOA cache: the same domain can have different zones depending on the nameservers.
+func soaCacheKey(fqdn string, nameservers []string) string {
+	return fqdn + "@" + strings.Join(nameservers, ",")
+}
@@ -0,0 +1,78 @@
+package dns01
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddZoneNameservers(t *testing.T) {
+	original := recursiveNameservers
+	t.Cleanup(func() {
+		recursiveNameservers = original
+		zoneNameservers.clear()
+	})
+
+	recursiveNameservers = []string{"192.0.2.1:53"}
+
+	require.NoError(t, AddZoneNameservers("example.com", []string{"10.0.0.1"}, []string{"tls://dns.example.net"})(nil))
+	require.NoError(t, AddZoneNameservers("corp.example.com.", []string{"10.0.0.2"}, nil)(nil))
+
+	testCases := []struct {
+		desc        string
+		fqdn        string
+		discovery   []string
+		propagation []string
+	}{
+		{
+			desc:        "no override",
+			fqdn:        "_acme-challenge.example.org.",
+			discovery:   []string{"192.0.2.1:53"},
+			propagation: []string{"192.0.2.1:53"},
+		},
+		{
+			desc:        "zone apex",
+			fqdn:        "example.com.",
+			discovery:   []string{"10.0.0.1:53"},
+			propagation: []string{"tls://dns.example.net:853"},
+		},
+		{
+			desc:        "subdomain",
+			fqdn:        "_acme-challenge.www.example.com.",
+			discovery:   []string{"10.0.0.1:53"},
+			propagation: []string{"tls://dns.example.net:853"},
+		},
+		{
+			desc:        "most specific zone without propagation nameservers",
+			fqdn:        "_acme-challenge.app.CORP.example.com.",
+			discovery:   []string{"10.0.0.2:53"},
+			propagation: []string{"192.0.2.1:53"},
+		},
+		{
+			desc:        "not a label boundary",
+			fqdn:        "_acme-challenge.notexample.com.",
+			discovery:   []string{"192.0.2.1:53"},
+			propagation: []string{"192.0.2.1:53"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.discovery, discoveryNameservers(test.fqdn))
+			assert.Equal(t, test.propagation, propagationNameservers(test.fqdn))
+		})
+	}
+}
+
+func TestAddZoneNameservers_emptyZone(t *testing.T) {
+	err := AddZoneNameservers("", []string{"10.0.0.1"}, nil)(nil)
+	require.EqualError(t, err, "empty zone")
+}
+
+func Test_soaCacheKey(t *testing.T) {
+	internal := soaCacheKey("www.example.com.", []string{"10.0.0.1:53"})
+	public := soaCacheKey("www.example.com.", []string{"192.0.2.1:53"})
+
+	assert.NotEqual(t, internal, public)
+}
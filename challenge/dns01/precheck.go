@@ -1,9 +1,11 @@
 package dns01
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/miekg/dns"
@@ -65,6 +67,10 @@ type preCheck struct {
 
 	// require the TXT record to be propagated to all recursive name servers
 	requireRecursiveNssPropagation bool
+
+	// the DNS provider reporting the propagation of the TXT record, and how it is used.
+	propagationProvider PropagationProvider
+	propagationPolicy   PropagationPolicy
 }
 
 func newPreCheck() preCheck {
@@ -75,16 +81,29 @@ func newPreCheck() preCheck {
 
 func (p preCheck) call(domain, fqdn, value string) (bool, error) {
 	if p.checkFunc == nil {
-		return p.checkDNSPropagation(fqdn, value)
+		return p.checkPropagation(fqdn, value)
+	}
+
+	return p.checkFunc(domain, fqdn, value, p.checkPropagation)
+}
+
+// checkPropagation checks the propagation of the TXT record with the DNS provider (depending on the policy),
+// then with the nameservers.
+func (p preCheck) checkPropagation(fqdn, value string) (bool, error) {
+	propagated, done, err := p.checkProviderPropagation(fqdn, value)
+	if done || err != nil {
+		return propagated, err
 	}
 
-	return p.checkFunc(domain, fqdn, value, p.checkDNSPropagation)
+	return p.checkDNSPropagation(fqdn, value)
 }
 
 // checkDNSPropagation checks if the expected TXT record has been propagated to all authoritative nameservers.
 func (p preCheck) checkDNSPropagation(fqdn, value string) (bool, error) {
+	nameservers := propagationNameservers(fqdn)
+
 	// Initial attempt to resolve at the recursive NS (require to get CNAME)
-	r, err := dnsQuery(fqdn, dns.TypeTXT, recursiveNameservers, true)
+	r, err := dnsQuery(fqdn, dns.TypeTXT, nameservers, true)
 	if err != nil {
 		return false, fmt.Errorf("initial recursive nameserver: %w", err)
 	}
@@ -94,7 +113,7 @@ func (p preCheck) checkDNSPropagation(fqdn, value string) (bool, error) {
 	}
 
 	if p.requireRecursiveNssPropagation {
-		_, err = checkNameserversPropagation(fqdn, value, recursiveNameservers, false)
+		_, err = checkNameserversPropagation(fqdn, value, propagationNameservers(fqdn), false)
 		if err != nil {
 			return false, fmt.Errorf("recursive nameservers: %w", err)
 		}
@@ -117,40 +136,163 @@ func (p preCheck) checkDNSPropagation(fqdn, value string) (bool, error) {
 	return found, nil
 }
 
-// checkNameserversPropagation queries each of the given nameservers for the expected TXT record.
+// checkNameserversPropagation queries concurrently each of the given nameservers for the expected TXT record.
+// When addPort is true, the nameservers are host names resolved to all their IPv4 and IPv6 addresses,
+// and each address is queried.
+// The returned error reports every server (and address) that does not serve the expected TXT record yet.
 func checkNameserversPropagation(fqdn, value string, nameservers []string, addPort bool) (bool, error) {
+	var targets []nsTarget
+
+	var results []nsResult
+
 	for _, ns := range nameservers {
-		if addPort {
-			ns = net.JoinHostPort(ns, "53")
+		if !addPort {
+			targets = append(targets, nsTarget{name: ns, addr: ns})
+			continue
 		}
 
-		r, err := dnsQuery(fqdn, dns.TypeTXT, []string{ns}, false)
+		addrs, err := lookupNameserverAddresses(ns)
 		if err != nil {
-			return false, err
+			results = append(results, nsResult{target: nsTarget{name: ns}, err: err})
+			continue
 		}
 
-		if r.Rcode != dns.RcodeSuccess {
-			return false, fmt.Errorf("NS %s returned %s for %s", ns, dns.RcodeToString[r.Rcode], fqdn)
+		for _, addr := range addrs {
+			targets = append(targets, nsTarget{name: ns, addr: net.JoinHostPort(addr, "53")})
+		}
+	}
+
+	results = append(results, queryNameserversPropagation(fqdn, value, targets)...)
+
+	var lagging []string
+	for _, result := range results {
+		if result.err != nil {
+			lagging = append(lagging, result.String())
+		}
+	}
+
+	if len(lagging) > 0 {
+		return false, &PropagationError{FQDN: fqdn, Value: value, Total: len(results), Lagging: lagging}
+	}
+
+	return true, nil
+}
+
+// queryNameserversPropagation queries concurrently the targets for the expected TXT record.
+func queryNameserversPropagation(fqdn, value string, targets []nsTarget) []nsResult {
+	results := make([]nsResult, len(targets))
+
+	var wg sync.WaitGroup
+
+	for i, target := range targets {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			results[i] = nsResult{target: target, err: checkNameserverPropagation(fqdn, value, target.addr)}
+		}()
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// checkNameserverPropagation queries a nameserver for the expected TXT record.
+func checkNameserverPropagation(fqdn, value, ns string) error {
+	r, err := dnsQuery(fqdn, dns.TypeTXT, []string{ns}, false)
+	if err != nil {
+		return err
+	}
+
+	if r.Rcode != dns.RcodeSuccess {
+		return fmt.Errorf("returned %s for %s", dns.RcodeToString[r.Rcode], fqdn)
+	}
+
+	var records []string
+
+	for _, rr := range r.Answer {
+		if txt, ok := rr.(*dns.TXT); ok {
+			record := strings.Join(txt.Txt, "")
+			records = append(records, record)
+			if record == value {
+				return nil
+			}
 		}
+	}
 
-		var records []string
+	return fmt.Errorf("did not return the expected TXT record: %s", strings.Join(records, " ,"))
+}
+
+// lookupNameserverAddresses resolves the IPv4 and IPv6 addresses of a nameserver.
+func lookupNameserverAddresses(ns string) ([]string, error) {
+	if ip := net.ParseIP(ns); ip != nil {
+		return []string{ns}, nil
+	}
+
+	fqdn := ToFqdn(ns)
+
+	var addrs []string
+	var errAll error
+
+	for _, rtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		r, err := dnsQuery(fqdn, rtype, propagationNameservers(fqdn), true)
+		if err != nil {
+			errAll = errors.Join(errAll, err)
+			continue
+		}
 
-		var found bool
 		for _, rr := range r.Answer {
-			if txt, ok := rr.(*dns.TXT); ok {
-				record := strings.Join(txt.Txt, "")
-				records = append(records, record)
-				if record == value {
-					found = true
-					break
-				}
+			switch record := rr.(type) {
+			case *dns.A:
+				addrs = append(addrs, record.A.String())
+			case *dns.AAAA:
+				addrs = append(addrs, record.AAAA.String())
 			}
 		}
+	}
 
-		if !found {
-			return false, fmt.Errorf("NS %s did not return the expected TXT record [fqdn: %s, value: %s]: %s", ns, fqdn, value, strings.Join(records, " ,"))
+	if len(addrs) == 0 {
+		if errAll != nil {
+			return nil, fmt.Errorf("could not resolve the addresses: %w", errAll)
 		}
+
+		return nil, errors.New("could not resolve the addresses")
 	}
 
-	return true, nil
+	return addrs, nil
+}
+
+type nsTarget struct {
+	name string
+	addr string
+}
+
+type nsResult struct {
+	target nsTarget
+	err    error
+}
+
+func (r nsResult) String() string {
+	if r.target.addr == "" || r.target.addr == r.target.name {
+		return fmt.Sprintf("NS %s %v", r.target.name, r.err)
+	}
+
+	return fmt.Sprintf("NS %s (%s) %v", r.target.name, r.target.addr, r.err)
+}
+
+// PropagationError reports the nameservers that do not serve the expected TXT record yet.
+type PropagationError struct {
+	FQDN  string
+	Value string
+	// Total is the number of queried nameservers (or nameserver addresses).
+	Total int
+	// Lagging describes each nameserver (or nameserver address) that is not up to date.
+	Lagging []string
+}
+
+func (e *PropagationError) Error() string {
+	return fmt.Sprintf("%d/%d nameservers did not return the expected TXT record [fqdn: %s, value: %s]: %s",
+		len(e.Lagging), e.Total, e.FQDN, e.Value, strings.Join(e.Lagging, "; "))
 }
@@ -1,8 +1,11 @@
 package dns01
 
 import (
+	"net"
+	"sync"
 	"testing"
 
+	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -112,3 +115,66 @@ func TestCheckAuthoritativeNssErr(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckNameserversPropagation_lagging(t *testing.T) {
+	upToDate := runTXTTestServer(t, "expected")
+	stale := runTXTTestServer(t, "old")
+
+	ok, err := checkNameserversPropagation("_acme-challenge.example.com.", "expected", []string{upToDate, stale}, false)
+	require.Error(t, err)
+	assert.False(t, ok)
+
+	var propagationErr *PropagationError
+	require.ErrorAs(t, err, &propagationErr)
+
+	assert.Equal(t, 2, propagationErr.Total)
+	require.Len(t, propagationErr.Lagging, 1)
+	assert.Equal(t, "NS "+stale+" did not return the expected TXT record: old", propagationErr.Lagging[0])
+
+	ok, err = checkNameserversPropagation("_acme-challenge.example.com.", "expected", []string{upToDate, upToDate}, false)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func Test_lookupNameserverAddresses_ip(t *testing.T) {
+	addrs, err := lookupNameserverAddresses("2001:db8::1")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"2001:db8::1"}, addrs)
+}
+
+// runTXTTestServer runs a DNS server that answers to all the TXT queries with the given value.
+func runTXTTestServer(t *testing.T, value string) string {
+	t.Helper()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &dns.Server{
+		PacketConn: pc,
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+			m := new(dns.Msg)
+			m.SetReply(req)
+			m.Answer = []dns.RR{&dns.TXT{
+				Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 0},
+				Txt: []string{value},
+			}}
+
+			_ = w.WriteMsg(m)
+		}),
+	}
+
+	waitLock := sync.Mutex{}
+	waitLock.Lock()
+	server.NotifyStartedFunc = waitLock.Unlock
+
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+
+	waitLock.Lock()
+
+	t.Cleanup(func() { _ = server.Shutdown() })
+
+	return pc.LocalAddr().String()
+}
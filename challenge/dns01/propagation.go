@@ -0,0 +1,79 @@
+package dns01
+
+import (
+	"fmt"
+	"slices"
+)
+
+// PropagationProvider allows for implementing a DNS provider able to report,
+// from its API, whether a TXT record presented for the DNS-01 challenge is served by all the authoritative nameservers
+// (e.g. the status of the change).
+// A DNS provider that cannot confirm it for every authoritative nameserver (secondaries, anycast edges)
+// must not implement this interface.
+type PropagationProvider interface {
+	IsPropagated(fqdn, value string) (bool, error)
+}
+
+// PropagationPolicy defines how the propagation of the TXT records is checked,
+// when the DNS provider implements PropagationProvider.
+type PropagationPolicy string
+
+// Propagation policies.
+const (
+	// PropagationPolicyDNS only queries the nameservers (default).
+	PropagationPolicyDNS PropagationPolicy = "dns"
+	// PropagationPolicyProviderFirst waits for the DNS provider to report the propagation, then queries the nameservers.
+	PropagationPolicyProviderFirst PropagationPolicy = "provider-first"
+	// PropagationPolicyProvider only relies on the DNS provider, the nameservers are not queried.
+	PropagationPolicyProvider PropagationPolicy = "provider"
+)
+
+// ParsePropagationPolicy parses a propagation policy.
+func ParsePropagationPolicy(value string) (PropagationPolicy, error) {
+	policy := PropagationPolicy(value)
+
+	if !slices.Contains([]PropagationPolicy{PropagationPolicyDNS, PropagationPolicyProviderFirst, PropagationPolicyProvider}, policy) {
+		return "", fmt.Errorf("unsupported propagation policy: %q", value)
+	}
+
+	return policy, nil
+}
+
+// SetPropagationPolicy defines how the propagation of the TXT records is checked.
+// The policy is only applied when the DNS provider implements PropagationProvider,
+// the nameservers are queried otherwise.
+func SetPropagationPolicy(policy PropagationPolicy) ChallengeOption {
+	return func(chlg *Challenge) error {
+		chlg.preCheck.propagationPolicy = policy
+		return nil
+	}
+}
+
+// SetPropagationProvider defines the DNS provider reporting the propagation of the TXT records.
+// By default, the DNS provider of the challenge is used if it implements PropagationProvider.
+// Useful when the DNS provider of the challenge is wrapped.
+func SetPropagationProvider(provider PropagationProvider) ChallengeOption {
+	return func(chlg *Challenge) error {
+		chlg.preCheck.propagationProvider = provider
+		return nil
+	}
+}
+
+// checkProviderPropagation asks the DNS provider if the TXT record is propagated.
+// Returns true if the propagation check is done (depending on the policy).
+func (p preCheck) checkProviderPropagation(fqdn, value string) (propagated, done bool, err error) {
+	if p.propagationProvider == nil || p.propagationPolicy == "" || p.propagationPolicy == PropagationPolicyDNS {
+		return false, false, nil
+	}
+
+	propagated, err = p.propagationProvider.IsPropagated(fqdn, value)
+	if err != nil {
+		return false, true, fmt.Errorf("provider: %w", err)
+	}
+
+	if !propagated {
+		return false, true, nil
+	}
+
+	return true, p.propagationPolicy == PropagationPolicyProvider, nil
+}
@@ -0,0 +1,138 @@
+package dns01
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePropagationProvider struct {
+	propagated bool
+	err        error
+	calls      int
+}
+
+func (f *fakePropagationProvider) IsPropagated(_, _ string) (bool, error) {
+	f.calls++
+
+	return f.propagated, f.err
+}
+
+func TestParsePropagationPolicy(t *testing.T) {
+	for _, value := range []string{"dns", "provider-first", "provider"} {
+		policy, err := ParsePropagationPolicy(value)
+		require.NoError(t, err)
+
+		assert.Equal(t, PropagationPolicy(value), policy)
+	}
+
+	_, err := ParsePropagationPolicy("foo")
+	require.EqualError(t, err, `unsupported propagation policy: "foo"`)
+}
+
+func Test_preCheck_checkProviderPropagation(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		policy             PropagationPolicy
+		provider           *fakePropagationProvider
+		expectedPropagated bool
+		expectedDone       bool
+		expectedErr        string
+		expectedCalls      int
+	}{
+		{
+			desc:     "DNS policy",
+			policy:   PropagationPolicyDNS,
+			provider: &fakePropagationProvider{propagated: true},
+		},
+		{
+			desc:   "no provider",
+			policy: PropagationPolicyProvider,
+		},
+		{
+			desc:               "provider policy: propagated",
+			policy:             PropagationPolicyProvider,
+			provider:           &fakePropagationProvider{propagated: true},
+			expectedPropagated: true,
+			expectedDone:       true,
+			expectedCalls:      1,
+		},
+		{
+			desc:          "provider policy: not propagated",
+			policy:        PropagationPolicyProvider,
+			provider:      &fakePropagationProvider{},
+			expectedDone:  true,
+			expectedCalls: 1,
+		},
+		{
+			desc:               "provider-first policy: propagated",
+			policy:             PropagationPolicyProviderFirst,
+			provider:           &fakePropagationProvider{propagated: true},
+			expectedPropagated: true,
+			expectedCalls:      1,
+		},
+		{
+			desc:          "provider-first policy: not propagated",
+			policy:        PropagationPolicyProviderFirst,
+			provider:      &fakePropagationProvider{},
+			expectedDone:  true,
+			expectedCalls: 1,
+		},
+		{
+			desc:          "provider error",
+			policy:        PropagationPolicyProviderFirst,
+			provider:      &fakePropagationProvider{err: errors.New("boom")},
+			expectedDone:  true,
+			expectedErr:   "provider: boom",
+			expectedCalls: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			check := newPreCheck()
+			check.propagationPolicy = test.policy
+
+			if test.provider != nil {
+				check.propagationProvider = test.provider
+			}
+
+			propagated, done, err := check.checkProviderPropagation("_acme-challenge.example.com.", "value")
+
+			if test.expectedErr != "" {
+				require.EqualError(t, err, test.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, test.expectedPropagated, propagated)
+			assert.Equal(t, test.expectedDone, done)
+
+			if test.provider != nil {
+				assert.Equal(t, test.expectedCalls, test.provider.calls)
+			}
+		})
+	}
+}
+
+func Test_preCheck_call_providerPolicy(t *testing.T) {
+	provider := &fakePropagationProvider{propagated: true}
+
+	chlg := NewChallenge(nil, nil, &propagationProviderMock{fakePropagationProvider: provider}, SetPropagationPolicy(PropagationPolicyProvider))
+
+	// The nameservers are not queried.
+	propagated, err := chlg.preCheck.call("example.com", "_acme-challenge.example.com.", "value")
+	require.NoError(t, err)
+
+	assert.True(t, propagated)
+	assert.Equal(t, 1, provider.calls)
+}
+
+type propagationProviderMock struct {
+	providerMock
+	*fakePropagationProvider
+}
@@ -0,0 +1,237 @@
+package dns01
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	// dohScheme is the prefix of the DNS-over-HTTPS (RFC 8484) resolvers.
+	dohScheme = "https://"
+
+	// dotScheme is the prefix of the DNS-over-TLS (RFC 7858) resolvers.
+	dotScheme = "tls://"
+
+	dotDefaultPort = "853"
+
+	dohMediaType = "application/dns-message"
+)
+
+// dnsRootCAs are the root certificates used to verify the DNS-over-HTTPS and DNS-over-TLS resolvers
+// (nil: the system root certificates).
+var dnsRootCAs *x509.CertPool
+
+var (
+	dohClientMu sync.Mutex
+	dohClient   *http.Client
+)
+
+var dotConns = &dotPool{conns: make(map[string][]*dns.Conn)}
+
+// AddDNSRootCAs defines the root certificates used to verify the DNS-over-HTTPS and DNS-over-TLS resolvers.
+func AddDNSRootCAs(pool *x509.CertPool) ChallengeOption {
+	return func(_ *Challenge) error {
+		dnsRootCAs = pool
+
+		dohClientMu.Lock()
+		dohClient = nil
+		dohClientMu.Unlock()
+
+		dotConns.closeAll()
+
+		return nil
+	}
+}
+
+// parseNameserver ensures that a nameserver has a port number, or is a valid DNS-over-HTTPS URL.
+func parseNameserver(resolver string) string {
+	switch {
+	case strings.HasPrefix(resolver, dohScheme):
+		return resolver
+
+	case strings.HasPrefix(resolver, dotScheme):
+		host := strings.TrimPrefix(resolver, dotScheme)
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			return dotScheme + net.JoinHostPort(host, dotDefaultPort)
+		}
+
+		return resolver
+
+	default:
+		if _, _, err := net.SplitHostPort(resolver); err != nil {
+			return net.JoinHostPort(resolver, "53")
+		}
+
+		return resolver
+	}
+}
+
+func newDNSTLSConfig(serverName string) *tls.Config {
+	return &tls.Config{
+		ServerName: serverName,
+		RootCAs:    dnsRootCAs,
+		MinVersion: tls.VersionTLS12,
+	}
+}
+
+// sendDoHQuery sends a DNS query to a DNS-over-HTTPS resolver (RFC 8484).
+func sendDoHQuery(m *dns.Msg, endpoint string) (*dns.Msg, error) {
+	// RFC 8484 §4.1: the DNS ID should be 0 to maximize the cache friendliness.
+	query := m.Copy()
+	query.Id = 0
+
+	raw, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", dohMediaType)
+	req.Header.Set("Accept", dohMediaType)
+
+	resp, err := getDoHClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, dns.MaxMsgSize))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: [status code: %d] body: %s", resp.StatusCode, string(body))
+	}
+
+	r := new(dns.Msg)
+
+	err = r.Unpack(body)
+	if err != nil {
+		return nil, fmt.Errorf("unpack response: %w", err)
+	}
+
+	r.Id = m.Id
+
+	return r, nil
+}
+
+func getDoHClient() *http.Client {
+	dohClientMu.Lock()
+	defer dohClientMu.Unlock()
+
+	if dohClient != nil && dohClient.Timeout == dnsTimeout {
+		return dohClient
+	}
+
+	dohClient = &http.Client{
+		Timeout: dnsTimeout,
+		Transport: &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			TLSClientConfig:     newDNSTLSConfig(""),
+			ForceAttemptHTTP2:   true,
+			MaxIdleConnsPerHost: 4,
+		},
+	}
+
+	return dohClient
+}
+
+// sendDoTQuery sends a DNS query to a DNS-over-TLS resolver (RFC 7858).
+// The connections are reused between the queries.
+func sendDoTQuery(m *dns.Msg, addr string) (*dns.Msg, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &dns.Client{Net: "tcp-tls", Timeout: dnsTimeout, TLSConfig: newDNSTLSConfig(host)}
+
+	conn, reused, err := dotConns.get(client, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	r, _, err := client.ExchangeWithConn(m, conn)
+	if err != nil && reused {
+		// The resolver may have closed the idle connection.
+		_ = conn.Close()
+
+		conn, err = client.Dial(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		r, _, err = client.ExchangeWithConn(m, conn)
+	}
+
+	if err != nil {
+		_ = conn.Close()
+		return r, err
+	}
+
+	dotConns.put(addr, conn)
+
+	return r, nil
+}
+
+// dotPool holds the idle DNS-over-TLS connections indexed by address.
+type dotPool struct {
+	mu    sync.Mutex
+	conns map[string][]*dns.Conn
+}
+
+func (p *dotPool) get(client *dns.Client, addr string) (*dns.Conn, bool, error) {
+	p.mu.Lock()
+
+	if idle := p.conns[addr]; len(idle) > 0 {
+		conn := idle[len(idle)-1]
+		p.conns[addr] = idle[:len(idle)-1]
+		p.mu.Unlock()
+
+		return conn, true, nil
+	}
+
+	p.mu.Unlock()
+
+	conn, err := client.Dial(addr)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return conn, false, nil
+}
+
+func (p *dotPool) put(addr string, conn *dns.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.conns[addr] = append(p.conns[addr], conn)
+}
+
+func (p *dotPool) closeAll() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for addr, conns := range p.conns {
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+
+		delete(p.conns, addr)
+	}
+}
@@ -0,0 +1,206 @@
+package dns01
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNameservers(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		servers  []string
+		expected []string
+	}{
+		{
+			desc:     "without port",
+			servers:  []string{"8.8.8.8", "2001:4860:4860::8888"},
+			expected: []string{"8.8.8.8:53", "[2001:4860:4860::8888]:53"},
+		},
+		{
+			desc:     "with port",
+			servers:  []string{"8.8.8.8:5353"},
+			expected: []string{"8.8.8.8:5353"},
+		},
+		{
+			desc:     "DNS-over-TLS",
+			servers:  []string{"tls://dns.google", "tls://1.1.1.1:8853"},
+			expected: []string{"tls://dns.google:853", "tls://1.1.1.1:8853"},
+		},
+		{
+			desc:     "DNS-over-HTTPS",
+			servers:  []string{"https://dns.google/dns-query"},
+			expected: []string{"https://dns.google/dns-query"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, ParseNameservers(test.servers))
+		})
+	}
+}
+
+func Test_sendDNSQuery_doh(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost || req.Header.Get("Content-Type") != dohMediaType {
+			http.Error(rw, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		raw, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		m := new(dns.Msg)
+		if err = m.Unpack(raw); err != nil || m.Id != 0 {
+			http.Error(rw, "invalid message", http.StatusBadRequest)
+			return
+		}
+
+		resp, err := helloReply(m).Pack()
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		rw.Header().Set("Content-Type", dohMediaType)
+		_, _ = rw.Write(resp)
+	}))
+	t.Cleanup(server.Close)
+
+	setupDNSRootCAs(t, server.Certificate())
+
+	m := createDNSMsg("example.com.", dns.TypeTXT, true)
+
+	r, err := sendDNSQuery(m, server.URL+"/dns-query")
+	require.NoError(t, err)
+
+	assert.Equal(t, m.Id, r.Id)
+	require.Len(t, r.Answer, 1)
+	assert.Equal(t, []string{"Hello world"}, r.Answer[0].(*dns.TXT).Txt)
+}
+
+func Test_sendDNSQuery_dot(t *testing.T) {
+	cert := generateTestCertificate(t)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	require.NoError(t, err)
+
+	var accepted atomic.Int32
+
+	server := &dns.Server{
+		Listener: &countingListener{Listener: listener, accepted: &accepted},
+		Net:      "tcp-tls",
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+			_ = w.WriteMsg(helloReply(req))
+		}),
+	}
+
+	waitLock := sync.Mutex{}
+	waitLock.Lock()
+	server.NotifyStartedFunc = waitLock.Unlock
+
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+
+	waitLock.Lock()
+	t.Cleanup(func() { _ = server.Shutdown() })
+
+	setupDNSRootCAs(t, cert.Leaf)
+
+	ns := ParseNameservers([]string{"tls://" + listener.Addr().String()})[0]
+
+	for range 3 {
+		r, err := sendDNSQuery(createDNSMsg("example.com.", dns.TypeTXT, true), ns)
+		require.NoError(t, err)
+
+		require.Len(t, r.Answer, 1)
+		assert.Equal(t, []string{"Hello world"}, r.Answer[0].(*dns.TXT).Txt)
+	}
+
+	// the connection is reused.
+	assert.Equal(t, int32(1), accepted.Load())
+}
+
+func setupDNSRootCAs(t *testing.T, cert *x509.Certificate) {
+	t.Helper()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	require.NoError(t, AddDNSRootCAs(pool)(nil))
+
+	t.Cleanup(func() {
+		require.NoError(t, AddDNSRootCAs(nil)(nil))
+	})
+}
+
+func helloReply(req *dns.Msg) *dns.Msg {
+	m := new(dns.Msg)
+	m.SetReply(req)
+	m.Answer = []dns.RR{&dns.TXT{
+		Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 0},
+		Txt: []string{"Hello world"},
+	}}
+
+	return m
+}
+
+func generateTestCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	require.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: privateKey, Leaf: leaf}
+}
+
+type countingListener struct {
+	net.Listener
+	accepted *atomic.Int32
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		l.accepted.Add(1)
+	}
+
+	return conn, err
+}
@@ -2,6 +2,7 @@ package http01
 
 import (
 	"fmt"
+	"net"
 	"net/http"
 	"net/netip"
 	"strings"
@@ -186,10 +187,31 @@ func isWS(r rune) bool {
 }
 
 func matchDomain(src, domain string) bool {
-	addr, err := netip.ParseAddr(domain)
-	if err == nil && addr.Is6() {
-		domain = "[" + domain + "]"
+	addr, err := parseAddr(domain)
+	if err != nil {
+		return strings.HasPrefix(src, domain)
+	}
+
+	// IP address (RFC 8738): the host must be the same IP address, with an optional port.
+	host := src
+	if h, _, errS := net.SplitHostPort(src); errS == nil {
+		host = h
+	}
+
+	srcAddr, err := parseAddr(host)
+	if err != nil {
+		return false
+	}
+
+	return srcAddr == addr
+}
+
+// parseAddr parses an IP address, with or without brackets.
+func parseAddr(value string) (netip.Addr, error) {
+	addr, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(value, "["), "]"))
+	if err != nil {
+		return netip.Addr{}, err
 	}
 
-	return strings.HasPrefix(src, domain)
+	return addr.Unmap(), nil
 }
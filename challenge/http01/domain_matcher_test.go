@@ -125,6 +125,36 @@ func Test_hostMatcher_matches(t *testing.T) {
 			req:      httptest.NewRequest(http.MethodGet, "http://[2001:db8::1]", nil),
 			expected: assert.True,
 		},
+		{
+			desc:     "ipv4 with port",
+			domain:   "127.0.0.1",
+			req:      httptest.NewRequest(http.MethodGet, "http://127.0.0.1:5002", nil),
+			expected: assert.True,
+		},
+		{
+			desc:     "ipv6 with port",
+			domain:   "2001:db8::1",
+			req:      httptest.NewRequest(http.MethodGet, "http://[2001:db8::1]:5002", nil),
+			expected: assert.True,
+		},
+		{
+			desc:     "ipv6 non-canonical",
+			domain:   "2001:db8::1",
+			req:      httptest.NewRequest(http.MethodGet, "http://[2001:DB8:0::1]", nil),
+			expected: assert.True,
+		},
+		{
+			desc:     "ipv4 prefix",
+			domain:   "127.0.0.1",
+			req:      httptest.NewRequest(http.MethodGet, "http://127.0.0.12", nil),
+			expected: assert.False,
+		},
+		{
+			desc:     "other ipv6",
+			domain:   "2001:db8::1",
+			req:      httptest.NewRequest(http.MethodGet, "http://[2001:db8::10]", nil),
+			expected: assert.False,
+		},
 	}
 
 	for _, test := range testCases {
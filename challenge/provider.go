@@ -26,3 +26,14 @@ type ProviderTimeout interface {
 	Provider
 	Timeout() (timeout, interval time.Duration)
 }
+
+// ProviderValidator allows for implementing a Provider
+// able to check its configuration before solving any challenge.
+// Validate must verify that the credentials are accepted by the API
+// and that at least one zone (or domain) is accessible with them.
+// It is intended to be called once, right after the creation of the Provider,
+// to report a misconfiguration early instead of during the first Present.
+type ProviderValidator interface {
+	Provider
+	Validate() error
+}
@@ -25,6 +25,17 @@ type cleanup interface {
 	CleanUp(authorization acme.Authorization) error
 }
 
+// Interface for challenges like dns, where we can set the records of ALL challenges at once.
+// This allows the providers to group the records, and saves a lot of API calls.
+type batchPreSolver interface {
+	PreSolveBatch(authorizations []acme.Authorization) map[string]error
+}
+
+// Interface for challenges like dns, where we can delete the records of ALL challenges at once.
+type batchCleanup interface {
+	CleanUpBatch(authorizations []acme.Authorization) map[string]error
+}
+
 type sequential interface {
 	Sequential() (bool, time.Duration)
 }
@@ -45,6 +56,11 @@ func NewProber(solverManager *SolverManager) *Prober {
 	}
 }
 
+// ChallengeTypes returns the challenge types that can be solved.
+func (p *Prober) ChallengeTypes() []challenge.Type {
+	return p.solverManager.ChallengeTypes()
+}
+
 // Solve Looks through the challenge combinations to find a solvable match.
 // Then solves the challenges in series and returns.
 func (p *Prober) Solve(authorizations []acme.Authorization) error {
@@ -98,17 +114,15 @@ func sequentialSolve(authSolvers []*selectedAuthSolver, failures obtainError) {
 		// Submit the challenge
 		domain := challenge.GetTargetedDomain(authSolver.authz)
 
-		if solvr, ok := authSolver.solver.(preSolver); ok {
-			err := solvr.PreSolve(authSolver.authz)
-			if err != nil {
-				failures[domain] = err
-				cleanUp(authSolver.solver, authSolver.authz)
-				continue
-			}
+		err := preSolve(authSolver.solver, authSolver.authz)
+		if err != nil {
+			failures[domain] = err
+			cleanUp(authSolver.solver, authSolver.authz)
+			continue
 		}
 
 		// Solve challenge
-		err := authSolver.solver.Solve(authSolver.authz)
+		err = authSolver.solver.Solve(authSolver.authz)
 		if err != nil {
 			failures[domain] = err
 			cleanUp(authSolver.solver, authSolver.authz)
@@ -131,6 +145,10 @@ func parallelSolve(authSolvers []*selectedAuthSolver, failures obtainError) {
 	// For all valid preSolvers, first submit the challenges, so they have max time to propagate
 	for _, authSolver := range authSolvers {
 		authz := authSolver.authz
+		if _, ok := authSolver.solver.(batchPreSolver); ok {
+			continue
+		}
+
 		if solvr, ok := authSolver.solver.(preSolver); ok {
 			err := solvr.PreSolve(authz)
 			if err != nil {
@@ -139,11 +157,32 @@ func parallelSolve(authSolvers []*selectedAuthSolver, failures obtainError) {
 		}
 	}
 
+	// The batchPreSolvers receive all their challenges at once.
+	for _, batch := range groupBySolver(authSolvers) {
+		if solvr, ok := batch.solver.(batchPreSolver); ok {
+			for domain, err := range solvr.PreSolveBatch(batch.authzs) {
+				failures[domain] = err
+			}
+		}
+	}
+
 	defer func() {
 		// Clean all created TXT records
 		for _, authSolver := range authSolvers {
+			if _, ok := authSolver.solver.(batchCleanup); ok {
+				continue
+			}
+
 			cleanUp(authSolver.solver, authSolver.authz)
 		}
+
+		for _, batch := range groupBySolver(authSolvers) {
+			if solvr, ok := batch.solver.(batchCleanup); ok {
+				for domain, err := range solvr.CleanUpBatch(batch.authzs) {
+					log.Warnf("[%s] acme: cleaning up failed: %v ", domain, err)
+				}
+			}
+		}
 	}()
 
 	// Finally solve all challenges for real
@@ -162,7 +201,55 @@ func parallelSolve(authSolvers []*selectedAuthSolver, failures obtainError) {
 	}
 }
 
+// solverBatch the authorizations associated with a solver.
+type solverBatch struct {
+	solver solver
+	authzs []acme.Authorization
+}
+
+// groupBySolver groups the authorizations by solver, in the order of the authorizations.
+func groupBySolver(authSolvers []*selectedAuthSolver) []*solverBatch {
+	var batches []*solverBatch
+	index := make(map[solver]*solverBatch)
+
+	for _, authSolver := range authSolvers {
+		batch, ok := index[authSolver.solver]
+		if !ok {
+			batch = &solverBatch{solver: authSolver.solver}
+			index[authSolver.solver] = batch
+			batches = append(batches, batch)
+		}
+
+		batch.authzs = append(batch.authzs, authSolver.authz)
+	}
+
+	return batches
+}
+
+// preSolve submits the challenge of an authorization.
+// The batchPreSolvers receive the authorization as a batch of one.
+func preSolve(solvr solver, authz acme.Authorization) error {
+	switch s := solvr.(type) {
+	case batchPreSolver:
+		return s.PreSolveBatch([]acme.Authorization{authz})[challenge.GetTargetedDomain(authz)]
+	case preSolver:
+		return s.PreSolve(authz)
+	default:
+		return nil
+	}
+}
+
+// cleanUp cleans the challenge of an authorization.
+// The batchCleanups receive the authorization as a batch of one.
 func cleanUp(solvr solver, authz acme.Authorization) {
+	if solvr, ok := solvr.(batchCleanup); ok {
+		for domain, err := range solvr.CleanUpBatch([]acme.Authorization{authz}) {
+			log.Warnf("[%s] acme: cleaning up failed: %v ", domain, err)
+		}
+
+		return
+	}
+
 	if solvr, ok := solvr.(cleanup); ok {
 		domain := challenge.GetTargetedDomain(authz)
 		err := solvr.CleanUp(authz)
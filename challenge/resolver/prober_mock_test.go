@@ -25,6 +25,49 @@ func (s *preSolverMock) CleanUp(authorization acme.Authorization) error {
 	return s.cleanUp[authorization.Identifier.Value]
 }
 
+type batchSolverMock struct {
+	preSolverMock
+
+	preSolveBatchCalls [][]string
+	cleanUpBatchCalls  [][]string
+}
+
+func (s *batchSolverMock) PreSolveBatch(authorizations []acme.Authorization) map[string]error {
+	failures := make(map[string]error)
+
+	var domains []string
+	for _, authz := range authorizations {
+		domains = append(domains, authz.Identifier.Value)
+
+		if err := s.preSolve[authz.Identifier.Value]; err != nil {
+			failures[authz.Identifier.Value] = err
+		}
+	}
+
+	s.preSolveBatchCalls = append(s.preSolveBatchCalls, domains)
+
+	return failures
+}
+
+func (s *batchSolverMock) CleanUpBatch(authorizations []acme.Authorization) map[string]error {
+	var domains []string
+	for _, authz := range authorizations {
+		domains = append(domains, authz.Identifier.Value)
+	}
+
+	s.cleanUpBatchCalls = append(s.cleanUpBatchCalls, domains)
+
+	return nil
+}
+
+type sequentialBatchSolverMock struct {
+	batchSolverMock
+}
+
+func (s *sequentialBatchSolverMock) Sequential() (bool, time.Duration) {
+	return true, 0
+}
+
 func createStubAuthorizationHTTP01(domain, status string) acme.Authorization {
 	return acme.Authorization{
 		Status:  status,
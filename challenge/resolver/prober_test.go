@@ -6,6 +6,7 @@ import (
 
 	"github.com/go-acme/lego/v4/acme"
 	"github.com/go-acme/lego/v4/challenge"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -115,3 +116,63 @@ func TestProber_Solve(t *testing.T) {
 		})
 	}
 }
+
+func TestProber_Solve_batch(t *testing.T) {
+	solvr := &batchSolverMock{
+		preSolverMock: preSolverMock{
+			preSolve: map[string]error{
+				"acme.wtf": errors.New("preSolve error acme.wtf"),
+			},
+			solve: map[string]error{
+				"acme.wtf": errors.New("solve error acme.wtf"),
+			},
+			cleanUp: map[string]error{},
+		},
+	}
+
+	prober := &Prober{
+		solverManager: &SolverManager{solvers: map[challenge.Type]solver{challenge.HTTP01: solvr}},
+	}
+
+	err := prober.Solve([]acme.Authorization{
+		createStubAuthorizationHTTP01("acme.wtf", acme.StatusProcessing),
+		createStubAuthorizationHTTP01("lego.wtf", acme.StatusProcessing),
+		createStubAuthorizationHTTP01("mydomain.wtf", acme.StatusValid),
+	})
+	require.EqualError(t, err, `error: one or more domains had a problem:
+[acme.wtf] preSolve error acme.wtf
+`)
+
+	assert.Equal(t, [][]string{{"acme.wtf", "lego.wtf"}}, solvr.preSolveBatchCalls)
+	assert.Equal(t, [][]string{{"acme.wtf", "lego.wtf"}}, solvr.cleanUpBatchCalls)
+}
+
+func TestProber_Solve_sequentialBatch(t *testing.T) {
+	solvr := &sequentialBatchSolverMock{
+		batchSolverMock: batchSolverMock{
+			preSolverMock: preSolverMock{
+				preSolve: map[string]error{
+					"acme.wtf": errors.New("preSolve error acme.wtf"),
+				},
+				solve:   map[string]error{},
+				cleanUp: map[string]error{},
+			},
+		},
+	}
+
+	prober := &Prober{
+		solverManager: &SolverManager{solvers: map[challenge.Type]solver{challenge.HTTP01: solvr}},
+	}
+
+	err := prober.Solve([]acme.Authorization{
+		createStubAuthorizationHTTP01("acme.wtf", acme.StatusProcessing),
+		createStubAuthorizationHTTP01("lego.wtf", acme.StatusProcessing),
+	})
+	require.EqualError(t, err, `error: one or more domains had a problem:
+[acme.wtf] preSolve error acme.wtf
+`)
+
+	// the authorizations are solved one by one.
+	assert.Equal(t, [][]string{{"acme.wtf"}, {"lego.wtf"}}, solvr.preSolveBatchCalls)
+	assert.Equal(t, [][]string{{"acme.wtf"}, {"lego.wtf"}}, solvr.cleanUpBatchCalls)
+}
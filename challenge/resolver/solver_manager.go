@@ -53,6 +53,19 @@ func (c *SolverManager) SetDNS01Provider(p challenge.Provider, opts ...dns01.Cha
 	return nil
 }
 
+// ChallengeTypes returns the challenge types of the available solvers, in the order of preference.
+func (c *SolverManager) ChallengeTypes() []challenge.Type {
+	var types []challenge.Type
+	for t := range c.solvers {
+		types = append(types, t)
+	}
+
+	// same order as chooseSolver.
+	sort.Slice(types, func(i, j int) bool { return types[i] > types[j] })
+
+	return types
+}
+
 // Remove removes a challenge type from the available solvers.
 func (c *SolverManager) Remove(chlgType challenge.Type) {
 	delete(c.solvers, chlgType)
@@ -7,12 +7,14 @@ import (
 	"crypto/x509/pkix"
 	"encoding/asn1"
 	"fmt"
+	"strings"
 
 	"github.com/go-acme/lego/v4/acme"
 	"github.com/go-acme/lego/v4/acme/api"
 	"github.com/go-acme/lego/v4/certcrypto"
 	"github.com/go-acme/lego/v4/challenge"
 	"github.com/go-acme/lego/v4/log"
+	"github.com/miekg/dns"
 )
 
 // idPeAcmeIdentifierV1 is the SMI Security for PKIX Certification Extension OID referencing the ACME extension.
@@ -70,6 +72,22 @@ func (c *Challenge) Solve(authz acme.Authorization) error {
 	return c.validate(c.core, domain, chlng)
 }
 
+// ServerName returns the server name (SNI) sent by the ACME server to validate the identifier.
+// For an IP address, the server name is the reverse DNS name of the address (`in-addr.arpa` or `ip6.arpa`).
+// Reference: https://www.rfc-editor.org/rfc/rfc8738.html#section-6
+func ServerName(identifier string) string {
+	if !certcrypto.IsIPAddress(identifier) {
+		return identifier
+	}
+
+	reverse, err := dns.ReverseAddr(identifier)
+	if err != nil {
+		return identifier
+	}
+
+	return strings.TrimSuffix(reverse, ".")
+}
+
 // ChallengeBlocks returns PEM blocks (certPEMBlock, keyPEMBlock) with the acmeValidation-v1 extension
 // and domain name for the `tls-alpn-01` challenge.
 func ChallengeBlocks(domain, keyAuth string) ([]byte, []byte, error) {
@@ -15,7 +15,6 @@ import (
 	"github.com/go-acme/lego/v4/acme/api"
 	"github.com/go-acme/lego/v4/challenge"
 	"github.com/go-acme/lego/v4/platform/tester"
-	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -127,7 +126,7 @@ func TestChallengeIPaddress(t *testing.T) {
 
 	domain := "127.0.0.1"
 	port := "24457"
-	rd, _ := dns.ReverseAddr(domain)
+	rd := ServerName(domain)
 
 	mockValidate := func(_ *api.Core, _ string, chlng acme.Challenge) error {
 		conn, err := tls.Dial("tcp", net.JoinHostPort(domain, port), &tls.Config{
@@ -191,3 +190,22 @@ func TestChallengeIPaddress(t *testing.T) {
 
 	require.NoError(t, solver.Solve(authz))
 }
+
+func TestServerName(t *testing.T) {
+	testCases := []struct {
+		identifier string
+		expected   string
+	}{
+		{identifier: "example.com", expected: "example.com"},
+		{identifier: "192.0.2.1", expected: "1.2.0.192.in-addr.arpa"},
+		{identifier: "2001:db8::1", expected: "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.identifier, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, ServerName(test.identifier))
+		})
+	}
+}
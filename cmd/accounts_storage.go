@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"encoding/pem"
 	"errors"
+	"fmt"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -59,6 +60,7 @@ const (
 //	     └── "path" option
 type AccountsStorage struct {
 	userID          string
+	server          string
 	rootPath        string
 	rootUserPath    string
 	keysPath        string
@@ -68,10 +70,18 @@ type AccountsStorage struct {
 
 // NewAccountsStorage Creates a new AccountsStorage.
 func NewAccountsStorage(ctx *cli.Context) *AccountsStorage {
+	return newAccountsStorage(ctx, ctx.String(flgServer))
+}
+
+// newAccountsStorage Creates a new AccountsStorage for the accounts of an ACME server.
+func newAccountsStorage(ctx *cli.Context, server string) *AccountsStorage {
 	// TODO: move to account struct? Currently MUST pass email.
-	email := getEmail(ctx)
+	return newUserAccountsStorage(ctx, server, getEmail(ctx))
+}
 
-	serverURL, err := url.Parse(ctx.String(flgServer))
+// newUserAccountsStorage Creates a new AccountsStorage for the account of a user on an ACME server.
+func newUserAccountsStorage(ctx *cli.Context, server, email string) *AccountsStorage {
+	serverURL, err := url.Parse(server)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -83,6 +93,7 @@ func NewAccountsStorage(ctx *cli.Context) *AccountsStorage {
 
 	return &AccountsStorage{
 		userID:          email,
+		server:          server,
 		rootPath:        rootPath,
 		rootUserPath:    rootUserPath,
 		keysPath:        filepath.Join(rootUserPath, baseKeysFolderName),
@@ -137,7 +148,7 @@ func (s *AccountsStorage) LoadAccount(privateKey crypto.PrivateKey) *Account {
 	account.key = privateKey
 
 	if account.Registration == nil || account.Registration.Body.Status == "" {
-		reg, err := tryRecoverRegistration(s.ctx, privateKey)
+		reg, err := tryRecoverRegistration(s.ctx, s.server, privateKey)
 		if err != nil {
 			log.Fatalf("Could not load account for %s. Registration is nil: %#v", s.userID, err)
 		}
@@ -152,8 +163,18 @@ func (s *AccountsStorage) LoadAccount(privateKey crypto.PrivateKey) *Account {
 	return &account
 }
 
+// ExistsPrivateKey returns true if the private key of the account exists.
+func (s *AccountsStorage) ExistsPrivateKey() bool {
+	if _, err := os.Stat(s.getPrivateKeyPath()); os.IsNotExist(err) {
+		return false
+	} else if err != nil {
+		log.Fatal(err)
+	}
+	return true
+}
+
 func (s *AccountsStorage) GetPrivateKey(keyType certcrypto.KeyType) crypto.PrivateKey {
-	accKeyPath := filepath.Join(s.keysPath, s.userID+".key")
+	accKeyPath := s.getPrivateKeyPath()
 
 	if _, err := os.Stat(accKeyPath); os.IsNotExist(err) {
 		log.Printf("No key found for account %s. Generating a %s key.", s.userID, keyType)
@@ -176,6 +197,22 @@ func (s *AccountsStorage) GetPrivateKey(keyType certcrypto.KeyType) crypto.Priva
 	return privateKey
 }
 
+// SavePrivateKey saves an existing private key as the key of the account.
+func (s *AccountsStorage) SavePrivateKey(privateKey crypto.PrivateKey) error {
+	block := certcrypto.PEMBlock(privateKey)
+	if block == nil {
+		return fmt.Errorf("unsupported private key type: %T", privateKey)
+	}
+
+	s.createKeysFolder()
+
+	return os.WriteFile(s.getPrivateKeyPath(), pem.EncodeToMemory(block), filePerm)
+}
+
+func (s *AccountsStorage) getPrivateKeyPath() string {
+	return filepath.Join(s.keysPath, s.userID+".key")
+}
+
 func (s *AccountsStorage) createKeysFolder() {
 	if err := createNonExistingFolder(s.keysPath); err != nil {
 		log.Fatalf("Could not check/create directory for account %s: %v", s.userID, err)
@@ -221,10 +258,10 @@ func loadPrivateKey(file string) (crypto.PrivateKey, error) {
 	return nil, errors.New("unknown private key type")
 }
 
-func tryRecoverRegistration(ctx *cli.Context, privateKey crypto.PrivateKey) (*registration.Resource, error) {
+func tryRecoverRegistration(ctx *cli.Context, server string, privateKey crypto.PrivateKey) (*registration.Resource, error) {
 	// couldn't load account but got a key. Try to look the account up.
 	config := lego.NewConfig(&Account{key: privateKey})
-	config.CADirURL = ctx.String(flgServer)
+	config.CADirURL = server
 	config.UserAgent = getUserAgent(ctx)
 
 	client, err := lego.NewClient(config)
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -34,8 +35,14 @@ const (
 	pemExt      = ".pem"
 	pfxExt      = ".pfx"
 	resourceExt = ".json"
+	nextKeyExt  = ".next.key"
+	tlsaExt     = ".tlsa.json"
 )
 
+// alternateChainPattern matches the extensions of the files of the alternate chains (e.g. `.alt1.crt`, `.alt1.issuer.crt`).
+// The extensions cannot be confused with the files of another domain: no TLD matches `alt<N>`.
+var alternateChainPattern = regexp.MustCompile(`^\.alt\d+(\.issuer)?\.crt$`)
+
 // CertificatesStorage a certificates' storage.
 //
 // rootPath:
@@ -115,6 +122,11 @@ func (s *CertificatesStorage) SaveResource(certRes *certificate.Resource) {
 		}
 	}
 
+	err = s.WriteAlternateChains(domain, certRes)
+	if err != nil {
+		log.Fatalf("Unable to save the alternate chains for domain %s\n\t%v", domain, err)
+	}
+
 	// if we were given a CSR, we don't know the private key
 	if certRes.PrivateKey != nil {
 		err = s.WriteCertificateFiles(domain, certRes)
@@ -151,6 +163,23 @@ func (s *CertificatesStorage) ReadResource(domain string) certificate.Resource {
 	return resource
 }
 
+// readResourceFile reads the meta data of a certificate from a resource file.
+func readResourceFile(filename string) (certificate.Resource, error) {
+	var resource certificate.Resource
+
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		return resource, err
+	}
+
+	err = json.Unmarshal(raw, &resource)
+	if err != nil {
+		return resource, fmt.Errorf("unmarshal %s: %w", filename, err)
+	}
+
+	return resource, nil
+}
+
 func (s *CertificatesStorage) ExistsFile(domain, extension string) bool {
 	filePath := s.GetFileName(domain, extension)
 
@@ -182,16 +211,57 @@ func (s *CertificatesStorage) ReadCertificate(domain, extension string) ([]*x509
 }
 
 func (s *CertificatesStorage) WriteFile(domain, extension string, data []byte) error {
-	var baseFileName string
-	if s.filename != "" {
-		baseFileName = s.filename
-	} else {
-		baseFileName = sanitizedDomain(domain)
+	filePath := filepath.Join(s.rootPath, s.getBaseFileName(domain)+extension)
+
+	return os.WriteFile(filePath, data, filePerm)
+}
+
+// WriteAlternateChains writes the alternate chains (`<domain>.alt<N>.crt` and `<domain>.alt<N>.issuer.crt`),
+// and removes the alternate chains of the previous certificate.
+func (s *CertificatesStorage) WriteAlternateChains(domain string, certRes *certificate.Resource) error {
+	baseFilename := filepath.Join(s.rootPath, s.getBaseFileName(domain))
+
+	matches, err := filepath.Glob(baseFilename + ".alt*")
+	if err != nil {
+		return err
 	}
 
-	filePath := filepath.Join(s.rootPath, baseFileName+extension)
+	for _, oldFile := range matches {
+		if !isAlternateChainFile(baseFilename, oldFile) {
+			continue
+		}
 
-	return os.WriteFile(filePath, data, filePerm)
+		err = os.Remove(oldFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, alt := range certRes.AlternateChains {
+		ext := ".alt" + strconv.Itoa(i+1)
+
+		err = s.WriteFile(domain, ext+certExt, alt.Certificate)
+		if err != nil {
+			return err
+		}
+
+		if alt.IssuerCertificate != nil {
+			err = s.WriteFile(domain, ext+issuerExt, alt.IssuerCertificate)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *CertificatesStorage) getBaseFileName(domain string) string {
+	if s.filename != "" {
+		return s.filename
+	}
+
+	return sanitizedDomain(domain)
 }
 
 func (s *CertificatesStorage) WriteCertificateFiles(domain string, certRes *certificate.Resource) error {
@@ -278,7 +348,9 @@ func (s *CertificatesStorage) MoveToArchive(domain string) error {
 	}
 
 	for _, oldFile := range matches {
-		if strings.TrimSuffix(oldFile, filepath.Ext(oldFile)) != baseFilename && oldFile != baseFilename+issuerExt {
+		if strings.TrimSuffix(oldFile, filepath.Ext(oldFile)) != baseFilename && oldFile != baseFilename+issuerExt &&
+			oldFile != baseFilename+nextKeyExt && oldFile != baseFilename+tlsaExt &&
+			!isAlternateChainFile(baseFilename, oldFile) {
 			continue
 		}
 
@@ -295,6 +367,20 @@ func (s *CertificatesStorage) MoveToArchive(domain string) error {
 	return nil
 }
 
+// isAlternateChainFile returns true if the file contains an alternate chain of the certificate.
+func isAlternateChainFile(baseFilename, file string) bool {
+	ext, found := strings.CutPrefix(file, baseFilename)
+
+	return found && alternateChainPattern.MatchString(ext)
+}
+
+// isAlternateChainCertificate returns true if the file is the certificate of an alternate chain.
+func isAlternateChainCertificate(filename string) bool {
+	base := strings.TrimSuffix(filename, certExt)
+
+	return isAlternateChainFile(strings.TrimSuffix(base, filepath.Ext(base)), filename)
+}
+
 func getCertificateChain(certRes *certificate.Resource) ([]*x509.Certificate, error) {
 	chainCertPemBlock, rest := pem.Decode(certRes.IssuerCertificate)
 	if chainCertPemBlock == nil {
@@ -333,6 +419,9 @@ func getPFXEncoder(pfxFormat string) (*pkcs12.Encoder, error) {
 
 // sanitizedDomain Make sure no funny chars are in the cert names (like wildcards ;)).
 func sanitizedDomain(domain string) string {
+	// IP addresses are named from their canonical form (e.g. `2001:db8::1` becomes `2001-db8--1`).
+	domain = certcrypto.NormalizeIdentifier(domain)
+
 	safe, err := idna.ToASCII(strings.NewReplacer(":", "-", "*", "_").Replace(domain))
 	if err != nil {
 		log.Fatal(err)
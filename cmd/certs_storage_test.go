@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"testing"
 
+	"github.com/go-acme/lego/v4/certificate"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -98,12 +99,52 @@ func TestCertificatesStorage_MoveToArchive_ambiguousDomain(t *testing.T) {
 	assert.Regexp(t, `\d+\.`+regexp.QuoteMeta(domain), archive[0].Name())
 }
 
+func TestCertificatesStorage_WriteAlternateChains(t *testing.T) {
+	domain := "example.com"
+
+	storage := CertificatesStorage{rootPath: t.TempDir()}
+
+	// alternate chains of the previous certificate, and of another domain.
+	generateTestFiles(t, storage.rootPath, domain)
+	otherDomainFiles := generateTestFiles(t, storage.rootPath, domain+".alt2.example.org")
+
+	for _, ext := range []string{".alt2" + certExt, ".alt2" + issuerExt} {
+		err := os.WriteFile(filepath.Join(storage.rootPath, domain+ext), []byte("test"), 0o666)
+		require.NoError(t, err)
+	}
+
+	certRes := &certificate.Resource{
+		Domain: domain,
+		AlternateChains: []certificate.AlternateChain{
+			{CertURL: "https://example.com/cert/1", Certificate: []byte("cert"), IssuerCertificate: []byte("issuer")},
+		},
+	}
+
+	err := storage.WriteAlternateChains(domain, certRes)
+	require.NoError(t, err)
+
+	assert.NoFileExists(t, filepath.Join(storage.rootPath, domain+".alt2"+certExt))
+	assert.NoFileExists(t, filepath.Join(storage.rootPath, domain+".alt2"+issuerExt))
+
+	for _, file := range otherDomainFiles {
+		assert.FileExists(t, file)
+	}
+
+	cert, err := os.ReadFile(filepath.Join(storage.rootPath, domain+".alt1"+certExt))
+	require.NoError(t, err)
+	assert.Equal(t, "cert", string(cert))
+
+	issuer, err := os.ReadFile(filepath.Join(storage.rootPath, domain+".alt1"+issuerExt))
+	require.NoError(t, err)
+	assert.Equal(t, "issuer", string(issuer))
+}
+
 func generateTestFiles(t *testing.T, dir, domain string) []string {
 	t.Helper()
 
 	var filenames []string
 
-	for _, ext := range []string{issuerExt, certExt, keyExt, pemExt, pfxExt, resourceExt} {
+	for _, ext := range []string{issuerExt, certExt, keyExt, pemExt, pfxExt, resourceExt, nextKeyExt, tlsaExt, ".alt1" + certExt, ".alt1" + issuerExt} {
 		filename := filepath.Join(dir, domain+ext)
 		err := os.WriteFile(filename, []byte("test"), 0o666)
 		require.NoError(t, err)
@@ -113,3 +154,25 @@ func generateTestFiles(t *testing.T, dir, domain string) []string {
 
 	return filenames
 }
+
+func Test_sanitizedDomain(t *testing.T) {
+	testCases := []struct {
+		domain   string
+		expected string
+	}{
+		{domain: "example.com", expected: "example.com"},
+		{domain: "*.example.com", expected: "_.example.com"},
+		{domain: "192.0.2.1", expected: "192.0.2.1"},
+		{domain: "2001:db8::1", expected: "2001-db8--1"},
+		{domain: "2001:DB8:0::1", expected: "2001-db8--1"},
+		{domain: "[2001:db8::1]", expected: "2001-db8--1"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.domain, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, sanitizedDomain(test.domain))
+		})
+	}
+}
@@ -10,5 +10,11 @@ func CreateCommands() []*cli.Command {
 		createRenew(),
 		createDNSHelp(),
 		createList(),
+		createCheck(),
+		createCleanup(),
+		createPreAuthorize(),
+		createProfiles(),
+		createAccounts(),
+		createImport(),
 	}
 }
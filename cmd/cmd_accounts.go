@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/lego"
+	"github.com/go-acme/lego/v4/log"
+	"github.com/urfave/cli/v2"
+)
+
+// Flag names.
+const (
+	flgContact = "contact"
+	flgClear   = "clear"
+	flgYes     = "yes"
+)
+
+func createAccounts() *cli.Command {
+	return &cli.Command{
+		Name:  "accounts",
+		Usage: "Manage the accounts (defined by --email and --server).",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "list",
+				Usage:  "Display the accounts stored in the --path directory.",
+				Action: listAccount,
+				Flags: []cli.Flag{
+					// fake email, needed by NewAccountsStorage
+					&cli.StringFlag{
+						Name:   flgEmail,
+						Value:  "unknown",
+						Hidden: true,
+					},
+				},
+			},
+			{
+				Name:   "show",
+				Usage:  "Display the account, as known by the ACME server.",
+				Action: showAccount,
+			},
+			{
+				Name:   "update-contact",
+				Usage:  "Replace the contacts of the account.",
+				Action: updateAccountContact,
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:  flgContact,
+						Usage: "Contact URI of the account (e.g. mailto:admin@example.com), an email address is converted to a mailto URI. Supports multiple values.",
+					},
+					&cli.BoolFlag{
+						Name:  flgClear,
+						Usage: "Remove all the contacts of the account.",
+					},
+				},
+			},
+			{
+				Name:   "deactivate",
+				Usage:  "Deactivate the account. The deactivation cannot be undone.",
+				Action: deactivateAccount,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  flgYes,
+						Usage: "Do not ask for confirmation.",
+					},
+				},
+			},
+			{
+				Name:   "recover",
+				Usage:  "Recover the account from its private key, when the account file is lost.",
+				Action: recoverAccount,
+			},
+		},
+	}
+}
+
+func showAccount(ctx *cli.Context) error {
+	accountsStorage, account, client := setupRegisteredAccount(ctx)
+
+	reg, err := client.Registration.QueryRegistration()
+	if err != nil {
+		log.Fatalf("Could not query the account %s: %v", account.Email, userActionRequired(err))
+	}
+
+	account.Registration = reg
+
+	err = accountsStorage.Save(account)
+	if err != nil {
+		log.Fatalf("Could not save the account %s: %v", account.Email, err)
+	}
+
+	displayAccount(accountsStorage, account)
+
+	return nil
+}
+
+func updateAccountContact(ctx *cli.Context) error {
+	if !ctx.IsSet(flgContact) && !ctx.Bool(flgClear) {
+		log.Fatalf("Please specify --%s or --%s", flgContact, flgClear)
+	}
+
+	var contacts []string
+	for _, contact := range ctx.StringSlice(flgContact) {
+		contacts = append(contacts, toContactURI(contact))
+	}
+
+	accountsStorage, account, client := setupRegisteredAccount(ctx)
+
+	reg, err := client.Registration.UpdateContact(contacts)
+	if err != nil {
+		log.Fatalf("Could not update the contacts of the account %s: %v", account.Email, userActionRequired(err))
+	}
+
+	account.Registration = reg
+
+	err = accountsStorage.Save(account)
+	if err != nil {
+		log.Fatalf("Could not save the account %s: %v", account.Email, err)
+	}
+
+	displayAccount(accountsStorage, account)
+
+	return nil
+}
+
+func deactivateAccount(ctx *cli.Context) error {
+	accountsStorage, account, client := setupRegisteredAccount(ctx)
+
+	if !ctx.Bool(flgYes) && !confirm(fmt.Sprintf("Deactivate the account %s (%s)? The deactivation cannot be undone. y/N", account.Email, account.Registration.URI)) {
+		return nil
+	}
+
+	err := client.Registration.DeleteRegistration()
+	if err != nil {
+		log.Fatalf("Could not deactivate the account %s: %v", account.Email, userActionRequired(err))
+	}
+
+	account.Registration.Body.Status = acme.StatusDeactivated
+
+	err = accountsStorage.Save(account)
+	if err != nil {
+		log.Fatalf("Could not save the account %s: %v", account.Email, err)
+	}
+
+	displayAccount(accountsStorage, account)
+
+	return nil
+}
+
+func recoverAccount(ctx *cli.Context) error {
+	accountsStorage := NewAccountsStorage(ctx)
+
+	if !accountsStorage.ExistsPrivateKey() {
+		log.Fatalf("No key found for account %s in %s.", accountsStorage.GetUserID(), accountsStorage.GetRootUserPath())
+	}
+
+	privateKey := accountsStorage.GetPrivateKey(getKeyType(ctx))
+
+	reg, err := tryRecoverRegistration(ctx, ctx.String(flgServer), privateKey)
+	if err != nil {
+		log.Fatalf("Could not recover the account %s: %v", accountsStorage.GetUserID(), userActionRequired(err))
+	}
+
+	account := &Account{Email: accountsStorage.GetUserID(), Registration: reg, key: privateKey}
+
+	err = accountsStorage.Save(account)
+	if err != nil {
+		log.Fatalf("Could not save the account %s: %v", account.Email, err)
+	}
+
+	displayAccount(accountsStorage, account)
+
+	return nil
+}
+
+// setupRegisteredAccount loads the account defined by --email and --server, and creates its client.
+func setupRegisteredAccount(ctx *cli.Context) (*AccountsStorage, *Account, *lego.Client) {
+	accountsStorage := NewAccountsStorage(ctx)
+
+	if !accountsStorage.ExistsAccountFilePath() {
+		log.Fatalf("Account %s not found in %s. Use 'accounts recover' to recover an account from its private key.",
+			accountsStorage.GetUserID(), accountsStorage.GetRootUserPath())
+	}
+
+	account, keyType := setupAccount(ctx, accountsStorage)
+
+	if account.Registration == nil {
+		log.Fatalf("Account %s is not registered. Use 'run' to register a new account.\n", account.Email)
+	}
+
+	return accountsStorage, account, newClient(ctx, getServer(ctx), account, keyType)
+}
+
+func displayAccount(accountsStorage *AccountsStorage, account *Account) {
+	fmt.Println("Account:", account.Email)
+	fmt.Println("  URL:", account.Registration.URI)
+	fmt.Println("  Status:", account.Registration.Body.Status)
+	fmt.Println("  Contacts:", strings.Join(account.Registration.Body.Contact, ", "))
+
+	if account.Registration.Body.Orders != "" {
+		fmt.Println("  Orders:", account.Registration.Body.Orders)
+	}
+
+	fmt.Println("  Path:", accountsStorage.GetRootUserPath())
+}
+
+// toContactURI converts an email address to a mailto URI.
+func toContactURI(contact string) string {
+	if strings.Contains(contact, ":") {
+		return contact
+	}
+
+	return "mailto:" + contact
+}
+
+// userActionRequired adds the URL to visit when the CA requires an action from the user (e.g. to agree to updated terms of service).
+// - https://www.rfc-editor.org/rfc/rfc8555.html#section-7.3.3
+func userActionRequired(err error) error {
+	var problem *acme.ProblemDetails
+	if !errors.As(err, &problem) || problem.Type != acme.UserActionRequiredErr || problem.Instance == "" {
+		return err
+	}
+
+	return fmt.Errorf("the CA requires an action from you (e.g. to agree to updated terms of service), please visit %s: %w", problem.Instance, err)
+}
+
+func confirm(question string) bool {
+	reader := bufio.NewReader(os.Stdin)
+
+	fmt.Println(question)
+
+	text, err := reader.ReadString('\n')
+	if err != nil {
+		log.Fatalf("Could not read from the console: %v", err)
+	}
+
+	text = strings.ToLower(strings.TrimSpace(text))
+
+	return text == "y" || text == "yes"
+}
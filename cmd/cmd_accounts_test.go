@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_toContactURI(t *testing.T) {
+	assert.Equal(t, "mailto:admin@example.com", toContactURI("admin@example.com"))
+	assert.Equal(t, "mailto:admin@example.com", toContactURI("mailto:admin@example.com"))
+	assert.Equal(t, "tel:+33123456789", toContactURI("tel:+33123456789"))
+}
+
+func Test_userActionRequired(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		err      error
+		expected string
+	}{
+		{
+			desc: "user action required",
+			err: fmt.Errorf("wrapped: %w", &acme.ProblemDetails{
+				Type:       acme.UserActionRequiredErr,
+				Detail:     "Terms of service have changed",
+				HTTPStatus: 403,
+				Instance:   "https://example.com/acme/agreement",
+			}),
+			expected: "the CA requires an action from you (e.g. to agree to updated terms of service), please visit https://example.com/acme/agreement: " +
+				"wrapped: acme: error: 403 :: urn:ietf:params:acme:error:userActionRequired :: Terms of service have changed, url: https://example.com/acme/agreement",
+		},
+		{
+			desc: "other problem",
+			err: &acme.ProblemDetails{
+				Type:       acme.ServerInternalErr,
+				HTTPStatus: 500,
+			},
+			expected: "acme: error: 500 :: urn:ietf:params:acme:error:serverInternal :: ",
+		},
+		{
+			desc:     "other error",
+			err:      errors.New("oops"),
+			expected: "oops",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := userActionRequired(test.err)
+			require.Error(t, err)
+
+			assert.EqualError(t, err, test.expected)
+		})
+	}
+}
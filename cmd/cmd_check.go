@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/asn1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/go-acme/lego/v4/challenge/http01"
+	"github.com/go-acme/lego/v4/challenge/tlsalpn01"
+	"github.com/go-acme/lego/v4/log"
+	"github.com/urfave/cli/v2"
+)
+
+// idPeAcmeIdentifierV1 is the SMI Security for PKIX Certification Extension OID referencing the ACME extension.
+// Reference: https://www.rfc-editor.org/rfc/rfc8737.html#section-6.1
+var idPeAcmeIdentifierV1 = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 31}
+
+const checkTimeout = 30 * time.Second
+
+func createCheck() *cli.Command {
+	return &cli.Command{
+		Name:  "check",
+		Usage: "Check the challenge setup (DNS provider, HTTP-01 or TLS-ALPN-01 server) without contacting the ACME server.",
+		Before: func(ctx *cli.Context) error {
+			if len(ctx.StringSlice(flgDomains)) == 0 {
+				log.Fatalf("Please specify --%s/-d", flgDomains)
+			}
+
+			if !ctx.Bool(flgHTTP) && !ctx.Bool(flgTLS) && !ctx.IsSet(flgDNS) {
+				log.Fatalf("No challenge selected. You must specify at least one challenge: `--%s`, `--%s`, `--%s`.", flgHTTP, flgTLS, flgDNS)
+			}
+
+			return nil
+		},
+		Action: check,
+	}
+}
+
+func check(ctx *cli.Context) error {
+	token, err := generateCheckToken()
+	if err != nil {
+		return err
+	}
+
+	// The key authorization is not related to an account: the ACME server is not contacted.
+	keyAuth := token + ".lego-check"
+
+	reporter := &checkReporter{}
+
+	var httpProvider, tlsProvider challenge.Provider
+	if ctx.Bool(flgHTTP) {
+		httpProvider = setupHTTPProvider(ctx)
+	}
+
+	if ctx.Bool(flgTLS) {
+		tlsProvider = setupTLSProvider(ctx)
+	}
+
+	var dnsChallenge *dns01.Challenge
+	var dnsProvider challenge.Provider
+	if ctx.IsSet(flgDNS) {
+		var opts []dns01.ChallengeOption
+
+		dnsProvider, opts, err = setupDNSProvider(ctx)
+		if reporter.step(ctx.String(flgDNS), challenge.DNS01, "create the DNS provider", err) &&
+			checkDNSProvider(reporter, ctx.String(flgDNS), dnsProvider) {
+			dnsChallenge = dns01.NewChallenge(nil, nil, dnsProvider, opts...)
+		}
+	}
+
+	for _, domain := range getDomains(ctx) {
+		if httpProvider != nil {
+			checkHTTP(reporter, httpProvider, domain, token, keyAuth)
+		}
+
+		if tlsProvider != nil {
+			checkTLSALPN(reporter, tlsProvider, domain, token, keyAuth)
+		}
+
+		if dnsChallenge != nil {
+			checkDNS(reporter, dnsProvider, dnsChallenge, domain, token, keyAuth)
+		}
+	}
+
+	if reporter.failed {
+		return errors.New("one or more checks failed")
+	}
+
+	return nil
+}
+
+func checkDNSProvider(reporter *checkReporter, name string, provider challenge.Provider) bool {
+	if _, ok := provider.(challenge.ProviderValidator); !ok {
+		reporter.skip(name, challenge.DNS01, "the DNS provider doesn't support the validation of the credentials")
+		return true
+	}
+
+	return reporter.step(name, challenge.DNS01, "validate the credentials and the zone access", validateDNSProvider(provider))
+}
+
+func checkDNS(reporter *checkReporter, provider challenge.Provider, chlg *dns01.Challenge, domain, token, keyAuth string) {
+	if certcrypto.IsIPAddress(domain) {
+		reporter.skip(domain, challenge.DNS01, "IP addresses cannot be validated with DNS-01")
+		return
+	}
+
+	// the TXT record of a wildcard domain is the TXT record of the base domain.
+	domain = strings.TrimPrefix(domain, "*.")
+
+	info := dns01.GetChallengeInfo(domain, keyAuth)
+
+	err := provider.Present(domain, token, keyAuth)
+	if !reporter.step(domain, challenge.DNS01, fmt.Sprintf("create the TXT record %s", info.EffectiveFQDN), err) {
+		return
+	}
+
+	err = chlg.CheckPropagation(domain, keyAuth)
+	reporter.step(domain, challenge.DNS01, "check the propagation of the TXT record", err)
+
+	err = provider.CleanUp(domain, token, keyAuth)
+	reporter.step(domain, challenge.DNS01, fmt.Sprintf("delete the TXT record %s", info.EffectiveFQDN), err)
+}
+
+func checkHTTP(reporter *checkReporter, provider challenge.Provider, domain, token, keyAuth string) {
+	if strings.HasPrefix(domain, "*.") {
+		reporter.skip(domain, challenge.HTTP01, "wildcard domains cannot be validated with HTTP-01")
+		return
+	}
+
+	err := provider.Present(domain, token, keyAuth)
+	if !reporter.step(domain, challenge.HTTP01, "present the token", err) {
+		return
+	}
+
+	challengeURL := "http://" + formatHost(domain) + http01.ChallengePath(token)
+
+	err = fetchHTTPToken(challengeURL, keyAuth)
+	reporter.step(domain, challenge.HTTP01, fmt.Sprintf("fetch the token from %s", challengeURL), err)
+
+	err = provider.CleanUp(domain, token, keyAuth)
+	reporter.step(domain, challenge.HTTP01, "clean up the token", err)
+}
+
+func checkTLSALPN(reporter *checkReporter, provider challenge.Provider, domain, token, keyAuth string) {
+	if strings.HasPrefix(domain, "*.") {
+		reporter.skip(domain, challenge.TLSALPN01, "wildcard domains cannot be validated with TLS-ALPN-01")
+		return
+	}
+
+	err := provider.Present(domain, token, keyAuth)
+	if !reporter.step(domain, challenge.TLSALPN01, "present the challenge certificate", err) {
+		return
+	}
+
+	addr := net.JoinHostPort(domain, "443")
+
+	err = fetchTLSALPNCertificate(addr, domain, keyAuth)
+	reporter.step(domain, challenge.TLSALPN01, fmt.Sprintf("fetch the challenge certificate from %s", addr), err)
+
+	err = provider.CleanUp(domain, token, keyAuth)
+	reporter.step(domain, challenge.TLSALPN01, "clean up the challenge certificate", err)
+}
+
+// fetchHTTPToken fetches the token through the public name of the domain, like the ACME server does.
+func fetchHTTPToken(challengeURL, keyAuth string) error {
+	client := &http.Client{Timeout: checkTimeout}
+
+	resp, err := client.Get(challengeURL)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if strings.TrimSpace(string(body)) != keyAuth {
+		return fmt.Errorf("unexpected content: %q", string(body))
+	}
+
+	return nil
+}
+
+// fetchTLSALPNCertificate fetches the challenge certificate through the public name of the domain, like the ACME server does.
+func fetchTLSALPNCertificate(addr, domain, keyAuth string) error {
+	dialer := &net.Dialer{Timeout: checkTimeout}
+
+	conn, err := tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{
+		ServerName: tlsalpn01.ServerName(domain),
+		NextProtos: []string{tlsalpn01.ACMETLS1Protocol},
+		// The challenge certificate is self-signed.
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	state := conn.ConnectionState()
+
+	if state.NegotiatedProtocol != tlsalpn01.ACMETLS1Protocol {
+		return fmt.Errorf("unexpected protocol: %q", state.NegotiatedProtocol)
+	}
+
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("no certificate")
+	}
+
+	cert := state.PeerCertificates[0]
+
+	err = cert.VerifyHostname(domain)
+	if err != nil {
+		return err
+	}
+
+	zBytes := sha256.Sum256([]byte(keyAuth))
+
+	expected, err := asn1.Marshal(zBytes[:sha256.Size])
+	if err != nil {
+		return err
+	}
+
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(idPeAcmeIdentifierV1) {
+			if !bytes.Equal(ext.Value, expected) {
+				return errors.New("unexpected acmeIdentifier extension value")
+			}
+
+			return nil
+		}
+	}
+
+	return errors.New("no acmeIdentifier extension")
+}
+
+func generateCheckToken() (string, error) {
+	raw := make([]byte, 32)
+
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func formatHost(domain string) string {
+	if ip := net.ParseIP(domain); ip != nil && ip.To4() == nil {
+		return "[" + domain + "]"
+	}
+
+	return domain
+}
+
+type checkReporter struct {
+	failed bool
+}
+
+// step reports the result of a step, and returns true if the step succeeded.
+func (r *checkReporter) step(domain string, chlg challenge.Type, name string, err error) bool {
+	if err != nil {
+		r.failed = true
+
+		fmt.Printf("[%s] %s: %s: FAILED: %v\n", domain, chlg, name, err)
+
+		return false
+	}
+
+	fmt.Printf("[%s] %s: %s: OK\n", domain, chlg, name)
+
+	return true
+}
+
+func (r *checkReporter) skip(domain string, chlg challenge.Type, reason string) {
+	fmt.Printf("[%s] %s: SKIPPED: %s\n", domain, chlg, reason)
+}
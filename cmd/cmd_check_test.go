@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-acme/lego/v4/challenge/tlsalpn01"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_fetchHTTPToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/.well-known/acme-challenge/token" {
+			http.NotFound(rw, req)
+			return
+		}
+
+		_, _ = fmt.Fprint(rw, "token.thumbprint")
+	}))
+	t.Cleanup(server.Close)
+
+	err := fetchHTTPToken(server.URL+"/.well-known/acme-challenge/token", "token.thumbprint")
+	require.NoError(t, err)
+
+	err = fetchHTTPToken(server.URL+"/.well-known/acme-challenge/token", "token.other")
+	require.EqualError(t, err, `unexpected content: "token.thumbprint"`)
+
+	err = fetchHTTPToken(server.URL+"/.well-known/acme-challenge/missing", "token.thumbprint")
+	require.EqualError(t, err, "unexpected status code: 404")
+}
+
+func Test_fetchTLSALPNCertificate(t *testing.T) {
+	cert, err := tlsalpn01.ChallengeCert("example.com", "token.thumbprint")
+	require.NoError(t, err)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{*cert},
+		NextProtos:   []string{tlsalpn01.ACMETLS1Protocol},
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, errA := listener.Accept()
+			if errA != nil {
+				return
+			}
+
+			_ = conn.(*tls.Conn).Handshake()
+			_ = conn.Close()
+		}
+	}()
+
+	err = fetchTLSALPNCertificate(listener.Addr().String(), "example.com", "token.thumbprint")
+	require.NoError(t, err)
+
+	err = fetchTLSALPNCertificate(listener.Addr().String(), "example.com", "token.other")
+	require.EqualError(t, err, "unexpected acmeIdentifier extension value")
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/go-acme/lego/v4/log"
+	"github.com/urfave/cli/v2"
+)
+
+// Flag names.
+const (
+	flgForce = "force"
+)
+
+func createCleanup() *cli.Command {
+	return &cli.Command{
+		Name: "cleanup",
+		Usage: "Clean up the challenges left behind by an interrupted run (TXT records, webroot files, ...)." +
+			" The DNS providers are created from their environment variables," +
+			" the HTTP provider must be defined with the same options as the interrupted run.",
+		Action: cleanup,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name: flgForce,
+				Usage: "Clean up the challenges of the other hosts sharing the same path, even if they may be in progress," +
+					" and remove the entries of the journal even if the cleanup of the challenges fails.",
+			},
+		},
+	}
+}
+
+func cleanup(ctx *cli.Context) error {
+	journal := NewChallengeJournal(ctx)
+
+	entries, err := journal.Entries()
+	if err != nil {
+		return fmt.Errorf("journal: %w", err)
+	}
+
+	if len(entries) == 0 {
+		log.Println("No challenge to clean up.")
+		return nil
+	}
+
+	return journal.Replay(ctx, ctx.Bool(flgForce))
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"text/tabwriter"
 
+	"github.com/go-acme/lego/v4/providers/dns/plugin"
 	"github.com/urfave/cli/v2"
 )
 
@@ -41,6 +42,9 @@ func dnsHelp(ctx *cli.Context) error {
 		ew.writeln("Supported DNS providers:")
 		ew.writef("\t%s\n", allDNSCodes())
 		ew.writeln()
+		ew.writef("Other DNS providers can be used through plugins ('%s<code>'): the program '%s<code>' in the directories of %s or PATH.\n",
+			plugin.ProviderPrefix, plugin.BinaryPrefix, plugin.EnvPluginsPath)
+		ew.writeln()
 		ew.writeln("More information: https://go-acme.github.io/lego/dns")
 
 		if ew.err != nil {
@@ -50,7 +54,57 @@ func dnsHelp(ctx *cli.Context) error {
 		return w.Flush()
 	}
 
-	return displayDNSHelp(ctx.App.Writer, strings.ToLower(code))
+	code = strings.ToLower(code)
+
+	if name, ok := strings.CutPrefix(code, plugin.ProviderPrefix); ok {
+		return displayPluginHelp(ctx.App.Writer, name)
+	}
+
+	return displayDNSHelp(ctx.App.Writer, code)
+}
+
+// displayPluginHelp displays the configuration advertised by a DNS provider plugin.
+func displayPluginHelp(w io.Writer, code string) error {
+	info, err := plugin.Describe(code)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	ew := &errWriter{w: tw}
+
+	ew.writef("Configuration for the plugin '%s'.\n", info.Name)
+	ew.writef("Code:\t'%s%s'\n", plugin.ProviderPrefix, info.Name)
+	ew.writef("Protocol version:\t'%d'\n", info.ProtocolVersion)
+	ew.writeln()
+
+	writeFields := func(title string, required bool) {
+		var lines []string
+		for _, field := range info.Config {
+			if field.Required == required {
+				lines = append(lines, fmt.Sprintf("\t- %q:\t%s", field.Name, field.Description))
+			}
+		}
+
+		if len(lines) == 0 {
+			return
+		}
+
+		ew.writeln(title)
+		for _, line := range lines {
+			ew.writeln(line)
+		}
+		ew.writeln()
+	}
+
+	writeFields("Credentials:", true)
+	writeFields("Additional Configuration:", false)
+
+	if ew.err != nil {
+		return ew.err
+	}
+
+	return tw.Flush()
 }
 
 type errWriter struct {
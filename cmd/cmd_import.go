@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"crypto"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/certificate"
+	"github.com/go-acme/lego/v4/log"
+	"github.com/go-acme/lego/v4/registration"
+	"github.com/urfave/cli/v2"
+)
+
+// Flag names.
+const (
+	flgSource    = "source"
+	flgOverwrite = "overwrite"
+)
+
+// importedAccount an account read from another ACME client.
+type importedAccount struct {
+	Server       string
+	Email        string
+	PrivateKey   crypto.PrivateKey
+	Registration *registration.Resource
+}
+
+// importedCertificate a certificate read from another ACME client.
+type importedCertificate struct {
+	Resource *certificate.Resource
+	Domains  []string
+	Server   string
+	Email    string
+
+	// Options the lego options equivalent to the renewal settings of the other ACME client.
+	Options []string
+	// RenewOptions the options of the renew command equivalent to the renewal settings of the other ACME client.
+	RenewOptions []string
+}
+
+func createImport() *cli.Command {
+	overwriteFlag := &cli.BoolFlag{
+		Name:  flgOverwrite,
+		Usage: "Overwrite the accounts and the certificates already stored by lego.",
+	}
+
+	return &cli.Command{
+		Name:  "import",
+		Usage: "Import the accounts and the certificates of another ACME client into the --path directory.",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "certbot",
+				Usage:  "Import the accounts and the certificates of certbot.",
+				Action: importCertbot,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  flgSource,
+						Usage: "The configuration directory of certbot.",
+						Value: "/etc/letsencrypt",
+					},
+					overwriteFlag,
+				},
+			},
+			{
+				Name:   "acme.sh",
+				Usage:  "Import the accounts and the certificates of acme.sh.",
+				Action: importAcmeSh,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  flgSource,
+						Usage: "The home directory of acme.sh.",
+						Value: filepath.Join(userHomeDir(), ".acme.sh"),
+					},
+					overwriteFlag,
+				},
+			},
+		},
+	}
+}
+
+func importCertbot(ctx *cli.Context) error {
+	accounts, certificates, err := readCertbot(ctx.String(flgSource), ctx.String(flgEmail))
+	if err != nil {
+		return err
+	}
+
+	saveImported(ctx, accounts, certificates)
+
+	return nil
+}
+
+func importAcmeSh(ctx *cli.Context) error {
+	accounts, certificates, err := readAcmeSh(ctx.String(flgSource), ctx.String(flgEmail))
+	if err != nil {
+		return err
+	}
+
+	saveImported(ctx, accounts, certificates)
+
+	return nil
+}
+
+// saveImported saves the accounts and the certificates in the lego storages,
+// and displays the lego command to renew each certificate.
+func saveImported(ctx *cli.Context, accounts []importedAccount, certificates []importedCertificate) {
+	overwrite := ctx.Bool(flgOverwrite)
+
+	for _, account := range accounts {
+		if account.Email == "" {
+			log.Warnf("The account %s has no email address, skipping. Use --%s to define it.", account.Registration.URI, flgEmail)
+			continue
+		}
+
+		accountsStorage := newUserAccountsStorage(ctx, account.Server, account.Email)
+
+		if accountsStorage.ExistsAccountFilePath() && !overwrite {
+			log.Warnf("The account %s already exists in %s, skipping.", account.Email, accountsStorage.GetRootUserPath())
+			continue
+		}
+
+		err := accountsStorage.SavePrivateKey(account.PrivateKey)
+		if err != nil {
+			log.Fatalf("Could not save the key of the account %s: %v", account.Email, err)
+		}
+
+		err = accountsStorage.Save(&Account{Email: account.Email, Registration: account.Registration, key: account.PrivateKey})
+		if err != nil {
+			log.Fatalf("Could not save the account %s: %v", account.Email, err)
+		}
+
+		log.Infof("Imported the account %s (%s) into %s", account.Email, account.Registration.URI, accountsStorage.GetRootUserPath())
+	}
+
+	certsStorage := NewCertificatesStorage(ctx)
+	certsStorage.CreateRootFolder()
+
+	for _, cert := range certificates {
+		domain := cert.Resource.Domain
+
+		if certsStorage.ExistsFile(domain, certExt) && !overwrite {
+			log.Warnf("[%s] The certificate already exists in %s, skipping.", domain, certsStorage.GetRootPath())
+			continue
+		}
+
+		certsStorage.SaveResource(cert.Resource)
+
+		log.Infof("[%s] Imported the certificate into %s", domain, certsStorage.GetRootPath())
+
+		fmt.Printf("[%s] Renewal command:\n\t%s\n", domain, renewalCommand(ctx, cert))
+	}
+}
+
+// renewalCommand returns the lego command to renew an imported certificate.
+func renewalCommand(ctx *cli.Context, cert importedCertificate) string {
+	args := []string{"lego"}
+
+	if cert.Email != "" {
+		args = append(args, "--email="+quoteArg(cert.Email))
+	}
+
+	if cert.Server != "" {
+		args = append(args, "--server="+quoteArg(cert.Server))
+	}
+
+	if ctx.IsSet(flgPath) {
+		args = append(args, "--path="+quoteArg(ctx.String(flgPath)))
+	}
+
+	for _, domain := range cert.Domains {
+		args = append(args, "--domains="+quoteArg(domain))
+	}
+
+	args = append(args, cert.Options...)
+	args = append(args, "renew")
+	args = append(args, cert.RenewOptions...)
+
+	return strings.Join(args, " ")
+}
+
+func quoteArg(value string) string {
+	if strings.ContainsAny(value, " \t'\"*$\\") {
+		return fmt.Sprintf("%q", value)
+	}
+
+	return value
+}
+
+// newImportedResource creates a certificate resource from the PEM encoded certificate, issuer certificates and private key.
+func newImportedResource(certPEM, issuerPEM, keyPEM []byte, server string) (*certificate.Resource, []string, error) {
+	certs, err := certcrypto.ParsePEMBundle(certPEM)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	domain, err := certcrypto.GetCertificateMainDomain(certs[0])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, err = certcrypto.ParsePEMPrivateKey(keyPEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("private key: %w", err)
+	}
+
+	// The certificate is bundled with the issuer certificates, like the certificates obtained by lego.
+	bundle := certcrypto.PEMEncode(certcrypto.DERCertificateBytes(certs[0].Raw))
+	bundle = append(bundle, issuerPEM...)
+
+	resource := &certificate.Resource{
+		Domain:            domain,
+		CADirURL:          server,
+		PrivateKey:        keyPEM,
+		Certificate:       bundle,
+		IssuerCertificate: issuerPEM,
+	}
+
+	return resource, certcrypto.ExtractDomains(certs[0]), nil
+}
+
+// emailFromContacts returns the email of the first mailto contact URI.
+func emailFromContacts(contacts []string) string {
+	for _, contact := range contacts {
+		if email, ok := strings.CutPrefix(contact, "mailto:"); ok {
+			return email
+		}
+	}
+
+	return ""
+}
+
+func userHomeDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return home
+}
@@ -0,0 +1,315 @@
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_readCertbot(t *testing.T) {
+	source := t.TempDir()
+
+	accountKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwk, err := jose.JSONWebKey{Key: accountKey}.MarshalJSON()
+	require.NoError(t, err)
+
+	regr, err := json.Marshal(certbotRegistration{
+		Body: acme.Account{Status: acme.StatusValid, Contact: []string{"mailto:admin@example.com"}},
+		URI:  "https://acme-staging-v02.api.letsencrypt.org/acme/acct/123",
+	})
+	require.NoError(t, err)
+
+	accountDir := filepath.Join(source, "accounts", "acme-staging-v02.api.letsencrypt.org", "directory", "abc123")
+	writeTestFile(t, filepath.Join(accountDir, "private_key.json"), jwk)
+	writeTestFile(t, filepath.Join(accountDir, "regr.json"), regr)
+
+	certPEM, issuerPEM, keyPEM := createTestCertificate(t, "example.com", "www.example.com")
+
+	live := filepath.Join(source, "live", "example.com")
+	writeTestFile(t, filepath.Join(live, "cert.pem"), certPEM)
+	writeTestFile(t, filepath.Join(live, "chain.pem"), issuerPEM)
+	writeTestFile(t, filepath.Join(live, "privkey.pem"), keyPEM)
+
+	writeTestFile(t, filepath.Join(source, "renewal", "example.com.conf"), []byte(`# renew_before_expiry = 30 days
+version = 2.11.0
+archive_dir = /etc/letsencrypt/archive/example.com
+cert = /etc/letsencrypt/live/example.com/cert.pem
+renew_before_expiry = 20 days
+
+# Options used in the renewal process
+[renewalparams]
+account = abc123
+authenticator = webroot
+webroot_path = /var/www/html,
+server = https://acme-staging-v02.api.letsencrypt.org/directory
+key_type = ecdsa
+elliptic_curve = secp384r1
+preferred_chain = ISRG Root X1
+[[webroot_map]]
+example.com = /var/www/html
+`))
+
+	accounts, certificates, err := readCertbot(source, "")
+	require.NoError(t, err)
+
+	require.Len(t, accounts, 1)
+	assert.Equal(t, "https://acme-staging-v02.api.letsencrypt.org/directory", accounts[0].Server)
+	assert.Equal(t, "admin@example.com", accounts[0].Email)
+	assert.Equal(t, "https://acme-staging-v02.api.letsencrypt.org/acme/acct/123", accounts[0].Registration.URI)
+	assert.Equal(t, acme.StatusValid, accounts[0].Registration.Body.Status)
+	assert.True(t, accountKey.Equal(accounts[0].PrivateKey))
+
+	require.Len(t, certificates, 1)
+
+	cert := certificates[0]
+	assert.Equal(t, "example.com", cert.Resource.Domain)
+	assert.Equal(t, "https://acme-staging-v02.api.letsencrypt.org/directory", cert.Resource.CADirURL)
+	assert.Equal(t, append(certPEM, issuerPEM...), cert.Resource.Certificate)
+	assert.Equal(t, issuerPEM, cert.Resource.IssuerCertificate)
+	assert.Equal(t, keyPEM, cert.Resource.PrivateKey)
+	assert.Equal(t, []string{"example.com", "www.example.com"}, cert.Domains)
+	assert.Equal(t, "admin@example.com", cert.Email)
+	assert.Equal(t, []string{"--http", "--http.webroot=/var/www/html", "--key-type=ec384"}, cert.Options)
+	assert.Equal(t, []string{`--preferred-chain="ISRG Root X1"`, "--days=20"}, cert.RenewOptions)
+}
+
+func Test_certbotOptions(t *testing.T) {
+	testCases := []struct {
+		desc                 string
+		params               map[string]string
+		expectedOptions      []string
+		expectedRenewOptions []string
+	}{
+		{
+			desc:            "standalone",
+			params:          map[string]string{"authenticator": "standalone", "rsa_key_size": "4096"},
+			expectedOptions: []string{"--http", "--key-type=rsa4096"},
+		},
+		{
+			desc:            "standalone TLS-ALPN-01",
+			params:          map[string]string{"authenticator": "standalone", "pref_challs": "tls-alpn-01,"},
+			expectedOptions: []string{"--tls"},
+		},
+		{
+			desc:            "DNS plugin with a different name",
+			params:          map[string]string{"authenticator": "dns-google", "key_type": "ecdsa"},
+			expectedOptions: []string{"--dns=gcloud", "--key-type=ec256"},
+		},
+		{
+			desc:            "DNS plugin",
+			params:          map[string]string{"authenticator": "dns-hetzner", "key_type": "rsa"},
+			expectedOptions: []string{"--dns=hetzner", "--key-type=rsa2048"},
+		},
+		{
+			desc:            "nginx",
+			params:          map[string]string{"authenticator": "nginx"},
+			expectedOptions: []string{"--http"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			options, renewOptions := certbotOptions("example.com", map[string]map[string]string{"renewalparams": test.params})
+
+			assert.Equal(t, test.expectedOptions, options)
+			assert.Equal(t, test.expectedRenewOptions, renewOptions)
+		})
+	}
+}
+
+func Test_readAcmeSh(t *testing.T) {
+	source := t.TempDir()
+
+	accountKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	accountDir := filepath.Join(source, "ca", "acme-v02.api.letsencrypt.org", "directory")
+	writeTestFile(t, filepath.Join(accountDir, "account.key"), pem.EncodeToMemory(certcrypto.PEMBlock(accountKey)))
+	writeTestFile(t, filepath.Join(accountDir, "account.json"), []byte(`{"status":"valid","contact":["mailto:admin@example.com"]}`))
+	writeTestFile(t, filepath.Join(accountDir, "ca.conf"), []byte(`ACCOUNT_URL='https://acme-v02.api.letsencrypt.org/acme/acct/456'
+CA_EMAIL='admin@example.com'
+`))
+
+	// An account without registration.
+	writeTestFile(t, filepath.Join(source, "ca", "acme.zerossl.com", "v2", "DV90", "account.key"), pem.EncodeToMemory(certcrypto.PEMBlock(accountKey)))
+
+	writeTestFile(t, filepath.Join(source, "account.conf"), []byte(`LOG_FILE="/root/.acme.sh/acme.sh.log"
+`))
+
+	certPEM, issuerPEM, keyPEM := createTestCertificate(t, "example.com", "www.example.com")
+
+	certDir := filepath.Join(source, "example.com_ecc")
+	writeTestFile(t, filepath.Join(certDir, "example.com.cer"), certPEM)
+	writeTestFile(t, filepath.Join(certDir, "ca.cer"), issuerPEM)
+	writeTestFile(t, filepath.Join(certDir, "example.com.key"), keyPEM)
+	writeTestFile(t, filepath.Join(certDir, "example.com.conf"), []byte(`Le_Domain='example.com'
+Le_Alt='www.example.com'
+Le_Webroot='dns_cf,dns_cf'
+Le_Keylength='ec-256'
+Le_API='https://acme-v02.api.letsencrypt.org/directory'
+Le_LinkCert='https://acme-v02.api.letsencrypt.org/acme/cert/789'
+Le_RenewalDays='60'
+`))
+
+	// Not a certificate directory.
+	require.NoError(t, os.MkdirAll(filepath.Join(source, "dnsapi"), 0o700))
+
+	accounts, certificates, err := readAcmeSh(source, "")
+	require.NoError(t, err)
+
+	require.Len(t, accounts, 1)
+	assert.Equal(t, "https://acme-v02.api.letsencrypt.org/directory", accounts[0].Server)
+	assert.Equal(t, "admin@example.com", accounts[0].Email)
+	assert.Equal(t, "https://acme-v02.api.letsencrypt.org/acme/acct/456", accounts[0].Registration.URI)
+	assert.Equal(t, acme.StatusValid, accounts[0].Registration.Body.Status)
+	assert.True(t, accountKey.Equal(accounts[0].PrivateKey))
+
+	require.Len(t, certificates, 1)
+
+	cert := certificates[0]
+	assert.Equal(t, "example.com", cert.Resource.Domain)
+	assert.Equal(t, "https://acme-v02.api.letsencrypt.org/acme/cert/789", cert.Resource.CertURL)
+	assert.Equal(t, append(certPEM, issuerPEM...), cert.Resource.Certificate)
+	assert.Equal(t, []string{"example.com", "www.example.com"}, cert.Domains)
+	assert.Equal(t, "admin@example.com", cert.Email)
+	assert.Equal(t, []string{"--dns=cloudflare", "--key-type=ec256"}, cert.Options)
+	// 90 days certificate renewed 60 days after the issuance.
+	assert.Equal(t, []string{"--days=30"}, cert.RenewOptions)
+}
+
+func Test_acmeShKeyType(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		keyLength  string
+		expected   string
+		requireErr require.ErrorAssertionFunc
+	}{
+		{
+			desc:       "empty",
+			keyLength:  "",
+			expected:   "",
+			requireErr: require.NoError,
+		},
+		{
+			desc:       "RSA",
+			keyLength:  "4096",
+			expected:   "rsa4096",
+			requireErr: require.NoError,
+		},
+		{
+			desc:       "EC",
+			keyLength:  "ec-384",
+			expected:   "ec384",
+			requireErr: require.NoError,
+		},
+		{
+			desc:       "unsupported EC",
+			keyLength:  "ec-521",
+			requireErr: require.Error,
+		},
+		{
+			desc:       "unsupported RSA",
+			keyLength:  "1024",
+			requireErr: require.Error,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			keyType, err := acmeShKeyType(test.keyLength)
+			test.requireErr(t, err)
+
+			assert.Equal(t, test.expected, keyType)
+		})
+	}
+}
+
+func Test_readAcmeShConf(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "example.com.conf")
+	writeTestFile(t, filename, []byte(`Le_Domain='example.com'
+Le_Alt="no"
+# comment
+export Le_Keylength=2048
+`))
+
+	conf, err := readAcmeShConf(filename)
+	require.NoError(t, err)
+
+	expected := map[string]string{
+		"Le_Domain":    "example.com",
+		"Le_Alt":       "no",
+		"Le_Keylength": "2048",
+	}
+
+	assert.Equal(t, expected, conf)
+}
+
+func createTestCertificate(t *testing.T, domains ...string) (certPEM, issuerPEM, keyPEM []byte) {
+	t.Helper()
+
+	issuerKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	issuerTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(90 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	issuerDER, err := x509.CreateCertificate(rand.Reader, issuerTemplate, issuerTemplate, issuerKey.Public(), issuerKey)
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: domains[0]},
+		DNSNames:     domains,
+		NotBefore:    issuerTemplate.NotBefore,
+		NotAfter:     issuerTemplate.NotAfter,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, issuerTemplate, key.Public(), issuerKey)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	return certcrypto.PEMEncode(certcrypto.DERCertificateBytes(der)),
+		certcrypto.PEMEncode(certcrypto.DERCertificateBytes(issuerDER)),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeTestFile(t *testing.T, filename string, data []byte) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0o700))
+	require.NoError(t, os.WriteFile(filename, data, 0o600))
+}
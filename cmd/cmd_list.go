@@ -1,22 +1,67 @@
 package cmd
 
 import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/certificate"
+	"github.com/go-acme/lego/v4/lego"
+	"github.com/go-acme/lego/v4/log"
 	"github.com/urfave/cli/v2"
 )
 
 const (
 	flgAccounts = "accounts"
 	flgNames    = "names"
+	flgFormat   = "format"
+	flgListARI  = "ari"
+	flgWarn     = "warn"
+	flgCritical = "critical"
 )
 
+// Output formats (--format).
+const (
+	formatText = "text"
+	formatJSON = "json"
+	formatCSV  = "csv"
+)
+
+// Monitoring statuses and exit codes (Nagios plugin conventions).
+const (
+	statusOK       = "OK"
+	statusWarning  = "WARNING"
+	statusCritical = "CRITICAL"
+	statusUnknown  = "UNKNOWN"
+)
+
+var statusExitCodes = map[string]int{
+	statusOK:       0,
+	statusWarning:  1,
+	statusCritical: 2,
+	statusUnknown:  3,
+}
+
+// statusSeverities the order of the statuses, used to get the status of several certificates.
+var statusSeverities = map[string]int{
+	statusOK:       0,
+	statusWarning:  1,
+	statusUnknown:  2,
+	statusCritical: 3,
+}
+
 func createList() *cli.Command {
 	return &cli.Command{
 		Name:   "list",
@@ -33,6 +78,23 @@ func createList() *cli.Command {
 				Aliases: []string{"n"},
 				Usage:   "Display certificate common names only.",
 			},
+			&cli.StringFlag{
+				Name:  flgFormat,
+				Usage: "Output format of the certificates: text, json, or csv.",
+				Value: formatText,
+			},
+			&cli.BoolFlag{
+				Name:  flgListARI,
+				Usage: "Query the renewal information (ARI) of the certificates from the CA that issued them.",
+			},
+			&cli.IntFlag{
+				Name:  flgWarn,
+				Usage: "Monitoring mode: the status is WARNING (exit code 1) if a certificate expires in less than this number of days.",
+			},
+			&cli.IntFlag{
+				Name:  flgCritical,
+				Usage: "Monitoring mode: the status is CRITICAL (exit code 2) if a certificate expires in less than this number of days.",
+			},
 			// fake email, needed by NewAccountsStorage
 			&cli.StringFlag{
 				Name:   flgEmail,
@@ -43,7 +105,52 @@ func createList() *cli.Command {
 	}
 }
 
+// certificateInfo the information of a stored certificate.
+type certificateInfo struct {
+	Name            string     `json:"name"`
+	Domains         []string   `json:"domains"`
+	Serial          string     `json:"serial"`
+	Issuer          string     `json:"issuer"`
+	KeyType         string     `json:"keyType"`
+	NotBefore       time.Time  `json:"notBefore"`
+	NotAfter        time.Time  `json:"notAfter"`
+	DaysRemaining   int        `json:"daysRemaining"`
+	Profile         string     `json:"profile,omitempty"`
+	ARIWindowStart  *time.Time `json:"ariWindowStart,omitempty"`
+	ARIWindowEnd    *time.Time `json:"ariWindowEnd,omitempty"`
+	CertificatePath string     `json:"certificatePath"`
+	IssuerPath      string     `json:"issuerPath,omitempty"`
+	KeyPath         string     `json:"keyPath,omitempty"`
+	ResourcePath    string     `json:"resourcePath,omitempty"`
+	Status          string     `json:"status,omitempty"`
+	Error           string     `json:"error,omitempty"`
+
+	cert     *x509.Certificate
+	caDirURL string
+}
+
 func list(ctx *cli.Context) error {
+	format := ctx.String(flgFormat)
+
+	switch format {
+	case formatText, formatJSON, formatCSV:
+	default:
+		return fmt.Errorf("unsupported --%s: %q (supported values: %s, %s, %s)", flgFormat, format, formatText, formatJSON, formatCSV)
+	}
+
+	if ctx.IsSet(flgWarn) || ctx.IsSet(flgCritical) {
+		return monitorCertificates(ctx, format)
+	}
+
+	if format != formatText {
+		infos, err := getCertificateInfos(ctx)
+		if err != nil {
+			return err
+		}
+
+		return writeCertificateInfos(os.Stdout, format, infos)
+	}
+
 	if ctx.Bool(flgAccounts) && !ctx.Bool(flgNames) {
 		if err := listAccount(ctx); err != nil {
 			return err
@@ -54,16 +161,14 @@ func list(ctx *cli.Context) error {
 }
 
 func listCertificates(ctx *cli.Context) error {
-	certsStorage := NewCertificatesStorage(ctx)
-
-	matches, err := filepath.Glob(filepath.Join(certsStorage.GetRootPath(), "*.crt"))
+	infos, err := getCertificateInfos(ctx)
 	if err != nil {
 		return err
 	}
 
 	names := ctx.Bool(flgNames)
 
-	if len(matches) == 0 {
+	if len(infos) == 0 {
 		if !names {
 			fmt.Println("No certificates found.")
 		}
@@ -74,38 +179,306 @@ func listCertificates(ctx *cli.Context) error {
 		fmt.Println("Found the following certs:")
 	}
 
+	for _, info := range infos {
+		switch {
+		case names:
+			fmt.Println(info.Name)
+		case info.Error != "":
+			fmt.Println("  Certificate Name:", info.Name)
+			fmt.Println("    Error:", info.Error)
+			fmt.Println("    Certificate Path:", info.CertificatePath)
+			fmt.Println()
+		default:
+			fmt.Println("  Certificate Name:", info.Name)
+			fmt.Println("    Domains:", strings.Join(info.Domains, ", "))
+			fmt.Println("    Expiry Date:", info.NotAfter)
+			if info.ARIWindowStart != nil {
+				fmt.Println("    Suggested Renewal Window:", info.ARIWindowStart, "-", info.ARIWindowEnd)
+			}
+			fmt.Println("    Certificate Path:", info.CertificatePath)
+			fmt.Println()
+		}
+	}
+
+	return nil
+}
+
+// monitorCertificates checks the expiration of the certificates,
+// and exits with the status of the most critical certificate (Nagios plugin conventions).
+func monitorCertificates(ctx *cli.Context, format string) error {
+	infos, err := getCertificateInfos(ctx)
+	if err != nil {
+		fmt.Printf("CERTIFICATES %s - %v\n", statusUnknown, err)
+
+		return cli.Exit("", statusExitCodes[statusUnknown])
+	}
+
+	status := statusOK
+
+	var problems []string
+
+	for i, info := range infos {
+		infos[i].Status = getCertificateStatus(info, ctx.Int(flgWarn), ctx.Int(flgCritical), time.Now())
+
+		switch infos[i].Status {
+		case statusOK:
+			continue
+		case statusUnknown:
+			problems = append(problems, fmt.Sprintf("%s: %s", info.Name, info.Error))
+		default:
+			problems = append(problems, fmt.Sprintf("%s expires in %d days", info.Name, info.DaysRemaining))
+		}
+
+		if statusSeverities[infos[i].Status] > statusSeverities[status] {
+			status = infos[i].Status
+		}
+	}
+
+	if format != formatText {
+		err = writeCertificateInfos(os.Stdout, format, infos)
+		if err != nil {
+			return cli.Exit(err, statusExitCodes[statusUnknown])
+		}
+	} else if len(problems) == 0 {
+		fmt.Printf("CERTIFICATES %s - %d certificates\n", status, len(infos))
+	} else {
+		fmt.Printf("CERTIFICATES %s - %s\n", status, strings.Join(problems, ", "))
+	}
+
+	return cli.Exit("", statusExitCodes[status])
+}
+
+// getCertificateStatus returns the monitoring status of a certificate.
+// A threshold lower or equal to 0 is disabled.
+// A certificate with a suggested renewal window (ARI) already started is at least in WARNING.
+// A certificate that cannot be read is UNKNOWN.
+func getCertificateStatus(info certificateInfo, warn, critical int, now time.Time) string {
+	switch {
+	case info.Error != "":
+		return statusUnknown
+	case critical > 0 && info.DaysRemaining < critical:
+		return statusCritical
+	case warn > 0 && info.DaysRemaining < warn:
+		return statusWarning
+	case info.ARIWindowStart != nil && !now.Before(*info.ARIWindowStart):
+		return statusWarning
+	default:
+		return statusOK
+	}
+}
+
+// getCertificateInfos reads the information of the stored certificates.
+func getCertificateInfos(ctx *cli.Context) ([]certificateInfo, error) {
+	certsStorage := NewCertificatesStorage(ctx)
+
+	matches, err := filepath.Glob(filepath.Join(certsStorage.GetRootPath(), "*"+certExt))
+	if err != nil {
+		return nil, err
+	}
+
+	ari := newARIClients(ctx)
+
+	var infos []certificateInfo
+
 	for _, filename := range matches {
-		if strings.HasSuffix(filename, issuerExt) {
+		if strings.HasSuffix(filename, issuerExt) || isAlternateChainCertificate(filename) {
 			continue
 		}
 
-		data, err := os.ReadFile(filename)
+		info, err := getCertificateInfo(filename)
 		if err != nil {
-			return err
+			log.Warnf("Could not read the certificate %s: %v", filename, err)
+
+			info = certificateInfo{
+				Name:            strings.TrimSuffix(filepath.Base(filename), certExt),
+				CertificatePath: filename,
+				Error:           err.Error(),
+			}
+		} else if ctx.Bool(flgListARI) {
+			ari.setRenewalWindow(&info)
+		}
+
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
+// getCertificateInfo reads the information of a certificate,
+// and of the files stored next to it (same base name).
+func getCertificateInfo(filename string) (certificateInfo, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return certificateInfo{}, err
+	}
+
+	pCert, err := certcrypto.ParsePEMCertificate(data)
+	if err != nil {
+		return certificateInfo{}, err
+	}
+
+	name, err := certcrypto.GetCertificateMainDomain(pCert)
+	if err != nil {
+		return certificateInfo{}, err
+	}
+
+	info := certificateInfo{
+		Name:            name,
+		Domains:         certcrypto.ExtractDomains(pCert),
+		Serial:          fmt.Sprintf("%x", pCert.SerialNumber),
+		Issuer:          pCert.Issuer.CommonName,
+		KeyType:         getPublicKeyType(pCert.PublicKey),
+		NotBefore:       pCert.NotBefore,
+		NotAfter:        pCert.NotAfter,
+		DaysRemaining:   int(time.Until(pCert.NotAfter).Hours() / 24.0),
+		CertificatePath: filename,
+		cert:            pCert,
+	}
+
+	if info.Issuer == "" {
+		info.Issuer = pCert.Issuer.String()
+	}
+
+	base := strings.TrimSuffix(filename, certExt)
+
+	for ext, path := range map[string]*string{issuerExt: &info.IssuerPath, keyExt: &info.KeyPath, resourceExt: &info.ResourcePath} {
+		if _, err := os.Stat(base + ext); err == nil {
+			*path = base + ext
 		}
+	}
 
-		pCert, err := certcrypto.ParsePEMCertificate(data)
+	if info.ResourcePath != "" {
+		resource, err := readResourceFile(info.ResourcePath)
 		if err != nil {
-			return err
+			return certificateInfo{}, err
+		}
+
+		info.Profile = resource.Profile
+		info.caDirURL = resource.CADirURL
+	}
+
+	return info, nil
+}
+
+// getPublicKeyType returns the type of the public key, using the values of the --key-type flag.
+func getPublicKeyType(publicKey any) string {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return fmt.Sprintf("rsa%d", key.N.BitLen())
+	case *ecdsa.PublicKey:
+		return fmt.Sprintf("ec%d", key.Curve.Params().BitSize)
+	case ed25519.PublicKey:
+		return "ed25519"
+	default:
+		return "unknown"
+	}
+}
+
+// writeCertificateInfos writes the information of the certificates in JSON or CSV.
+func writeCertificateInfos(w io.Writer, format string, infos []certificateInfo) error {
+	if format == formatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		if infos == nil {
+			infos = []certificateInfo{}
 		}
 
-		name, err := certcrypto.GetCertificateMainDomain(pCert)
+		return encoder.Encode(infos)
+	}
+
+	writer := csv.NewWriter(w)
+
+	err := writer.Write([]string{
+		"name", "domains", "serial", "issuer", "key_type", "not_before", "not_after", "days_remaining", "profile",
+		"ari_window_start", "ari_window_end", "certificate_path", "issuer_path", "key_path", "resource_path", "status", "error",
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, info := range infos {
+		err = writer.Write([]string{
+			info.Name,
+			strings.Join(info.Domains, " "),
+			info.Serial,
+			info.Issuer,
+			info.KeyType,
+			info.NotBefore.Format(time.RFC3339),
+			info.NotAfter.Format(time.RFC3339),
+			strconv.Itoa(info.DaysRemaining),
+			info.Profile,
+			formatOptionalTime(info.ARIWindowStart),
+			formatOptionalTime(info.ARIWindowEnd),
+			info.CertificatePath,
+			info.IssuerPath,
+			info.KeyPath,
+			info.ResourcePath,
+			info.Status,
+			info.Error,
+		})
 		if err != nil {
 			return err
 		}
+	}
 
-		if names {
-			fmt.Println(name)
-		} else {
-			fmt.Println("  Certificate Name:", name)
-			fmt.Println("    Domains:", strings.Join(pCert.DNSNames, ", "))
-			fmt.Println("    Expiry Date:", pCert.NotAfter)
-			fmt.Println("    Certificate Path:", filename)
-			fmt.Println()
+	writer.Flush()
+
+	return writer.Error()
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+// ariClients the clients used to query the renewal information, by CA.
+type ariClients struct {
+	ctx     *cli.Context
+	clients map[string]*lego.Client
+}
+
+func newARIClients(ctx *cli.Context) *ariClients {
+	return &ariClients{ctx: ctx, clients: map[string]*lego.Client{}}
+}
+
+// setRenewalWindow sets the suggested renewal window of the certificate, from the CA that issued it.
+// The errors are only logged: the renewal information is optional.
+func (a *ariClients) setRenewalWindow(info *certificateInfo) {
+	server := a.ctx.String(flgServer)
+	if info.caDirURL != "" {
+		server = info.caDirURL
+	}
+
+	client, ok := a.clients[server]
+	if !ok {
+		// The renewal information doesn't require an account: a temporary key is used.
+		privateKey, err := certcrypto.GeneratePrivateKey(certcrypto.EC256)
+		if err != nil {
+			log.Warnf("[%s] Could not generate the private key: %v", info.Name, err)
+			return
 		}
+
+		client, err = lego.NewClient(newClientConfig(a.ctx, server, &Account{key: privateKey}, certcrypto.EC256))
+		if err != nil {
+			log.Warnf("[%s] Could not create client for %s: %v", info.Name, server, err)
+			return
+		}
+
+		a.clients[server] = client
 	}
 
-	return nil
+	renewalInfo, err := client.Certificate.GetRenewalInfo(certificate.RenewalInfoRequest{Cert: info.cert})
+	if err != nil {
+		log.Warnf("[%s] acme: calling renewal info endpoint: %v", info.Name, err)
+		return
+	}
+
+	info.ARIWindowStart = &renewalInfo.SuggestedWindow.Start
+	info.ARIWindowEnd = &renewalInfo.SuggestedWindow.End
 }
 
 func listAccount(ctx *cli.Context) error {
@@ -141,6 +514,10 @@ func listAccount(ctx *cli.Context) error {
 
 		fmt.Println("  Email:", account.Email)
 		fmt.Println("  Server:", uri.Host)
+		fmt.Println("  Status:", account.Registration.Body.Status)
+		if len(account.Registration.Body.Contact) > 0 {
+			fmt.Println("  Contacts:", strings.Join(account.Registration.Body.Contact, ", "))
+		}
 		fmt.Println("  Path:", filepath.Dir(filename))
 		fmt.Println()
 	}
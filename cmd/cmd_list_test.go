@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_getCertificateStatus(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	testCases := []struct {
+		desc     string
+		info     certificateInfo
+		warn     int
+		critical int
+		expected string
+	}{
+		{
+			desc:     "no thresholds",
+			info:     certificateInfo{DaysRemaining: 1},
+			expected: statusOK,
+		},
+		{
+			desc:     "ok",
+			info:     certificateInfo{DaysRemaining: 30},
+			warn:     20,
+			critical: 10,
+			expected: statusOK,
+		},
+		{
+			desc:     "warning",
+			info:     certificateInfo{DaysRemaining: 15},
+			warn:     20,
+			critical: 10,
+			expected: statusWarning,
+		},
+		{
+			desc:     "critical",
+			info:     certificateInfo{DaysRemaining: 5},
+			warn:     20,
+			critical: 10,
+			expected: statusCritical,
+		},
+		{
+			desc:     "expired",
+			info:     certificateInfo{DaysRemaining: -2},
+			critical: 10,
+			expected: statusCritical,
+		},
+		{
+			desc:     "ARI window started",
+			info:     certificateInfo{DaysRemaining: 30, ARIWindowStart: &past},
+			warn:     20,
+			expected: statusWarning,
+		},
+		{
+			desc:     "unreadable certificate",
+			info:     certificateInfo{Error: "unexpected end of JSON input"},
+			warn:     20,
+			critical: 10,
+			expected: statusUnknown,
+		},
+		{
+			desc:     "ARI window not started",
+			info:     certificateInfo{DaysRemaining: 30, ARIWindowStart: &future},
+			warn:     20,
+			expected: statusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			status := getCertificateStatus(test.info, test.warn, test.critical, now)
+
+			assert.Equal(t, test.expected, status)
+		})
+	}
+}
+
+func Test_writeCertificateInfos(t *testing.T) {
+	infos := []certificateInfo{{
+		Name:            "example.com",
+		Domains:         []string{"example.com", "www.example.com"},
+		Serial:          "3e1724a96e5f3c",
+		Issuer:          "Example CA",
+		KeyType:         "ec256",
+		NotBefore:       time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:        time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+		DaysRemaining:   42,
+		Profile:         "classic",
+		CertificatePath: "/lego/certificates/example.com.crt",
+		KeyPath:         "/lego/certificates/example.com.key",
+		Status:          statusOK,
+	}}
+
+	t.Run("csv", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		err := writeCertificateInfos(buf, formatCSV, infos)
+		require.NoError(t, err)
+
+		expected := "name,domains,serial,issuer,key_type,not_before,not_after,days_remaining,profile,ari_window_start,ari_window_end,certificate_path,issuer_path,key_path,resource_path,status,error\n" +
+			"example.com,example.com www.example.com,3e1724a96e5f3c,Example CA,ec256,2025-01-01T00:00:00Z,2025-04-01T00:00:00Z,42,classic,,,/lego/certificates/example.com.crt,,/lego/certificates/example.com.key,,OK,\n"
+
+		assert.Equal(t, expected, buf.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		err := writeCertificateInfos(buf, formatJSON, infos)
+		require.NoError(t, err)
+
+		expected := `[
+  {
+    "name": "example.com",
+    "domains": [
+      "example.com",
+      "www.example.com"
+    ],
+    "serial": "3e1724a96e5f3c",
+    "issuer": "Example CA",
+    "keyType": "ec256",
+    "notBefore": "2025-01-01T00:00:00Z",
+    "notAfter": "2025-04-01T00:00:00Z",
+    "daysRemaining": 42,
+    "profile": "classic",
+    "certificatePath": "/lego/certificates/example.com.crt",
+    "keyPath": "/lego/certificates/example.com.key",
+    "status": "OK"
+  }
+]
+`
+
+		assert.Equal(t, expected, buf.String())
+	})
+
+	t.Run("json: no certificates", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		err := writeCertificateInfos(buf, formatJSON, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, "[]\n", buf.String())
+	})
+}
+
+func Test_getCertificateInfo(t *testing.T) {
+	dir := t.TempDir()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	cert, err := certcrypto.GeneratePemCert(privateKey, "example.com", nil)
+	require.NoError(t, err)
+
+	// the files are stored with a name (--filename) that is not the main domain.
+	filename := filepath.Join(dir, "custom"+certExt)
+
+	err = os.WriteFile(filename, cert, 0o600)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, "custom"+resourceExt), []byte(`{"domain":"example.com","profile":"classic","caDirUrl":"https://ca.example.com/directory"}`), 0o600)
+	require.NoError(t, err)
+
+	info, err := getCertificateInfo(filename)
+	require.NoError(t, err)
+
+	assert.Contains(t, info.Domains, "example.com")
+	assert.Equal(t, "classic", info.Profile)
+	assert.Equal(t, "https://ca.example.com/directory", info.caDirURL)
+	assert.Equal(t, filepath.Join(dir, "custom"+resourceExt), info.ResourcePath)
+}
+
+func Test_getCertificateInfo_invalidResource(t *testing.T) {
+	dir := t.TempDir()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	cert, err := certcrypto.GeneratePemCert(privateKey, "example.com", nil)
+	require.NoError(t, err)
+
+	filename := filepath.Join(dir, "example.com"+certExt)
+
+	err = os.WriteFile(filename, cert, 0o600)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, "example.com"+resourceExt), []byte(`not JSON`), 0o600)
+	require.NoError(t, err)
+
+	_, err = getCertificateInfo(filename)
+	require.Error(t, err)
+}
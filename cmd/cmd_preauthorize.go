@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-acme/lego/v4/log"
+	"github.com/urfave/cli/v2"
+)
+
+func createPreAuthorize() *cli.Command {
+	return &cli.Command{
+		Name: "preauthorize",
+		Usage: "Validate the domains without requesting a certificate (pre-authorization, RFC 8555 section 7.4.1)." +
+			" The valid authorizations are reused by the ACME server for the next certificates, until they expire." +
+			" The ACME server must support pre-authorization.",
+		Before: func(ctx *cli.Context) error {
+			if len(ctx.StringSlice(flgDomains)) == 0 {
+				log.Fatalf("Please specify --%s/-d", flgDomains)
+			}
+
+			return nil
+		},
+		Action: preAuthorize,
+	}
+}
+
+func preAuthorize(ctx *cli.Context) error {
+	account, keyType := setupAccount(ctx, NewAccountsStorage(ctx))
+
+	if account.Registration == nil {
+		log.Fatalf("Account %s is not registered. Use 'run' to register a new account.\n", account.Email)
+	}
+
+	client := setupClient(ctx, getServer(ctx), account, keyType)
+
+	authzs, err := client.Certificate.PreAuthorize(getDomains(ctx))
+	if err != nil {
+		log.Fatalf("Could not pre-authorize the domains:\n\t%v", err)
+	}
+
+	for _, authz := range authzs {
+		fmt.Printf("[%s] %s, expires: %s\n", authz.Identifier.Value, authz.Status, authz.Expires.Format(time.RFC3339))
+		fmt.Printf("\tURL: %s\n", authz.Location)
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/lego"
+	"github.com/go-acme/lego/v4/log"
+	"github.com/urfave/cli/v2"
+)
+
+func createProfiles() *cli.Command {
+	return &cli.Command{
+		Name:   "profiles",
+		Usage:  "Display the certificate profiles offered by the CA (draft-aaron-acme-profiles).",
+		Action: listProfiles,
+	}
+}
+
+func listProfiles(ctx *cli.Context) error {
+	// The directory of the CA doesn't require an account: a temporary key is used.
+	privateKey, err := certcrypto.GeneratePrivateKey(certcrypto.EC256)
+	if err != nil {
+		log.Fatalf("Could not generate the private key: %v", err)
+	}
+
+	client, err := lego.NewClient(newClientConfig(ctx, ctx.String(flgServer), &Account{key: privateKey}, certcrypto.EC256))
+	if err != nil {
+		log.Fatalf("Could not create client: %v", err)
+	}
+
+	profiles := client.GetProfiles()
+
+	if len(profiles) == 0 {
+		fmt.Printf("The CA %s doesn't offer certificate profiles.\n", ctx.String(flgServer))
+		return nil
+	}
+
+	var names []string
+	for name := range profiles {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	fmt.Printf("Certificate profiles offered by the CA %s:\n", ctx.String(flgServer))
+
+	for _, name := range names {
+		fmt.Printf("  %s: %s\n", name, profiles[name])
+	}
+
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"slices"
 	"time"
 
+	"github.com/go-acme/lego/v4/acme"
 	"github.com/go-acme/lego/v4/acme/api"
 	"github.com/go-acme/lego/v4/certcrypto"
 	"github.com/go-acme/lego/v4/certificate"
@@ -48,9 +49,15 @@ func createRenew() *cli.Command {
 			if ctx.Bool(flgForceCertDomains) && hasCsr {
 				log.Fatal("--%s only works with --%s/-d, --%s/-c doesn't support this option.", flgForceCertDomains, flgDomains, flgCSR)
 			}
+			if ctx.Bool(flgNextKey) && hasCsr {
+				log.Fatalf("--%s only works with --%s/-d, --%s/-c doesn't support this option.", flgNextKey, flgDomains, flgCSR)
+			}
+			if ctx.Bool(flgNextKey) && ctx.Bool(flgReuseKey) {
+				log.Fatalf("Please specify either --%s or --%s, but not both", flgNextKey, flgReuseKey)
+			}
 			return nil
 		},
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			&cli.IntFlag{
 				Name:  flgDays,
 				Value: 30,
@@ -92,14 +99,38 @@ func createRenew() *cli.Command {
 				Usage: "If the CA offers multiple certificate chains, prefer the chain with an issuer matching this Subject Common Name." +
 					" If no match, the default offered chain will be used.",
 			},
+			&cli.StringSliceFlag{
+				Name: flgPreferredChainSPKI,
+				Usage: "If the CA offers multiple certificate chains, prefer the chain containing a certificate with this SHA-256 fingerprint of the Subject Public Key Info (hex)." +
+					" If no match, the default offered chain will be used.",
+			},
 			&cli.StringFlag{
-				Name:  flgProfile,
-				Usage: "If the CA offers multiple certificate profiles (draft-aaron-acme-profiles), choose this one.",
+				Name: flgPreferredChainKeyAlgorithm,
+				Usage: "If the CA offers multiple certificate chains, prefer the chain signed only with this key algorithm (rsa, ecdsa, ed25519)." +
+					" If no match, the default offered chain will be used.",
+			},
+			&cli.BoolFlag{
+				Name:  flgPreferredChainShortest,
+				Usage: "If the CA offers multiple certificate chains, prefer the shortest chain.",
+			},
+			&cli.BoolFlag{
+				Name:  flgAlternateChains,
+				Usage: "Store the alternate certificate chains offered by the CA alongside the certificate.",
+			},
+			&cli.StringFlag{
+				Name: flgProfile,
+				Usage: "If the CA offers multiple certificate profiles (draft-aaron-acme-profiles), choose this one." +
+					" By default, the profile of the certificate to renew is used.",
 			},
 			&cli.StringFlag{
 				Name:  flgAlwaysDeactivateAuthorizations,
 				Usage: "Force the authorizations to be relinquished even if the certificate request was successful.",
 			},
+			&cli.BoolFlag{
+				Name: flgCAAPreflight,
+				Usage: "Check the CAA records (RFC 8659, RFC 8657) of the domains before creating the order." +
+					" Fails if the CAA records don't allow the CA, the account or the challenge to be used.",
+			},
 			&cli.StringFlag{
 				Name:  flgRenewHook,
 				Usage: "Define a hook. The hook is executed only when the certificates are effectively renewed.",
@@ -118,12 +149,12 @@ func createRenew() *cli.Command {
 				Name:  flgForceCertDomains,
 				Usage: "Check and ensure that the cert's domain list matches those passed in the domains argument.",
 			},
-		},
+		}, createDANEFlags()...),
 	}
 }
 
 func renew(ctx *cli.Context) error {
-	account, keyType := setupAccount(ctx, NewAccountsStorage(ctx))
+	account, _ := setupAccount(ctx, NewAccountsStorage(ctx))
 
 	if account.Registration == nil {
 		log.Fatalf("Account %s is not registered. Use 'run' to register a new account.\n", account.Email)
@@ -137,15 +168,15 @@ func renew(ctx *cli.Context) error {
 
 	// CSR
 	if ctx.IsSet(flgCSR) {
-		return renewForCSR(ctx, account, keyType, certsStorage, bundle, meta)
+		return renewForCSR(ctx, certsStorage, bundle, meta)
 	}
 
 	// Domains
-	return renewForDomains(ctx, account, keyType, certsStorage, bundle, meta)
+	return renewForDomains(ctx, certsStorage, bundle, meta)
 }
 
-func renewForDomains(ctx *cli.Context, account *Account, keyType certcrypto.KeyType, certsStorage *CertificatesStorage, bundle bool, meta map[string]string) error {
-	domains := ctx.StringSlice(flgDomains)
+func renewForDomains(ctx *cli.Context, certsStorage *CertificatesStorage, bundle bool, meta map[string]string) error {
+	domains := getDomains(ctx)
 	domain := domains[0]
 
 	// load the cert resource from files.
@@ -161,10 +192,14 @@ func renewForDomains(ctx *cli.Context, account *Account, keyType certcrypto.KeyT
 	var ariRenewalTime *time.Time
 	var replacesCertID string
 
+	// The certificate is renewed by the CA that issued it, then by the other servers.
+	servers := getRenewalServers(ctx, getCertificateIssuer(certsStorage, domain))
+
+	var account *Account
 	var client *lego.Client
 
 	if !ctx.Bool(flgARIDisable) {
-		client = setupClient(ctx, account, keyType)
+		account, client = setupServerClient(ctx, servers[0])
 
 		ariRenewalTime = getARIRenewalTime(ctx, cert, domain, client)
 		if ariRenewalTime != nil {
@@ -187,21 +222,40 @@ func renewForDomains(ctx *cli.Context, account *Account, keyType certcrypto.KeyT
 
 	certDomains := certcrypto.ExtractDomains(cert)
 
+	// The TLSA records of the next private key are published before the renewal.
+	err = prepareNextKey(ctx, certsStorage, domain)
+	if err != nil {
+		log.Fatalf("[%s] Could not prepare the next private key: %v", domain, err)
+	}
+
 	if ariRenewalTime == nil && !needRenewal(cert, domain, ctx.Int(flgDays)) &&
 		(!forceDomains || slices.Equal(certDomains, domains)) {
 		return nil
 	}
 
-	if client == nil {
-		client = setupClient(ctx, account, keyType)
-	}
-
 	// This is just meant to be informal for the user.
 	timeLeft := cert.NotAfter.Sub(time.Now().UTC())
 	log.Infof("[%s] acme: Trying renewal with %d hours remaining", domain, int(timeLeft.Hours()))
 
+	useNextKey := ctx.Bool(flgNextKey)
+	if useNextKey {
+		useNextKey, err = isNextKeyReady(ctx, certsStorage, domain, time.Now().UTC())
+		if err != nil {
+			log.Fatalf("Error while checking the TLSA records of the next private key for domain %s\n\t%v", domain, err)
+		}
+
+		if !useNextKey {
+			log.Infof("[%s] The TLSA records of the next private key are published for less than twice their TTL: the current private key is used.", domain)
+		}
+	}
+
 	var privateKey crypto.PrivateKey
-	if ctx.Bool(flgReuseKey) {
+	if useNextKey {
+		privateKey, err = readNextKey(certsStorage, domain)
+		if err != nil {
+			log.Fatalf("Error while loading the next private key for domain %s\n\t%v", domain, err)
+		}
+	} else if ctx.Bool(flgReuseKey) || ctx.Bool(flgNextKey) {
 		keyBytes, errR := certsStorage.ReadFile(domain, keyExt)
 		if errR != nil {
 			log.Fatalf("Error while loading the private key for domain %s\n\t%v", domain, errR)
@@ -238,27 +292,54 @@ func renewForDomains(ctx *cli.Context, account *Account, keyType certcrypto.KeyT
 		NotAfter:                       getTime(ctx, flgNotAfter),
 		Bundle:                         bundle,
 		PreferredChain:                 ctx.String(flgPreferredChain),
-		Profile:                        ctx.String(flgProfile),
+		ChainPreference:                getChainPreference(ctx),
+		AlternateChains:                ctx.Bool(flgAlternateChains),
+		Profile:                        getRenewalProfile(ctx, certsStorage, domain),
 		AlwaysDeactivateAuthorizations: ctx.Bool(flgAlwaysDeactivateAuthorizations),
+		CAAPreflight:                   ctx.Bool(flgCAAPreflight),
 	}
 
-	if replacesCertID != "" {
-		request.ReplacesCertID = replacesCertID
+	obtain := func(_ *cli.Context, server caServer, client *lego.Client) (*certificate.Resource, error) {
+		// The ARI CertID is only known by the CA that issued the certificate.
+		if server.URL == servers[0].URL {
+			request.ReplacesCertID = replacesCertID
+		} else {
+			request.ReplacesCertID = ""
+		}
+
+		return client.Certificate.Obtain(request)
 	}
 
-	certRes, err := client.Certificate.Obtain(request)
+	_, certRes, err := obtainWithFailover(ctx, servers, account, client, obtain)
 	if err != nil {
-		log.Fatal(err)
+		if isRateLimited(domain, err) {
+			return nil
+		}
+
+		log.Fatal(userActionRequired(err))
 	}
 
 	certsStorage.SaveResource(certRes)
 
+	if useNextKey {
+		// The next private key is now the private key of the certificate.
+		err = writeNextKey(ctx, certsStorage, domain)
+		if err != nil {
+			log.Warnf("[%s] Could not create the next private key: %v", domain, err)
+		}
+	}
+
+	err = publishTLSA(ctx, certsStorage, domain)
+	if err != nil {
+		log.Warnf("[%s] Could not publish the TLSA records: %v", domain, err)
+	}
+
 	addPathToMetadata(meta, domain, certRes, certsStorage)
 
 	return launchHook(ctx.String(flgRenewHook), ctx.Duration(flgRenewHookTimeout), meta)
 }
 
-func renewForCSR(ctx *cli.Context, account *Account, keyType certcrypto.KeyType, certsStorage *CertificatesStorage, bundle bool, meta map[string]string) error {
+func renewForCSR(ctx *cli.Context, certsStorage *CertificatesStorage, bundle bool, meta map[string]string) error {
 	csr, err := readCSRFile(ctx.String(flgCSR))
 	if err != nil {
 		log.Fatal(err)
@@ -282,10 +363,14 @@ func renewForCSR(ctx *cli.Context, account *Account, keyType certcrypto.KeyType,
 	var ariRenewalTime *time.Time
 	var replacesCertID string
 
+	// The certificate is renewed by the CA that issued it, then by the other servers.
+	servers := getRenewalServers(ctx, getCertificateIssuer(certsStorage, domain))
+
+	var account *Account
 	var client *lego.Client
 
 	if !ctx.Bool(flgARIDisable) {
-		client = setupClient(ctx, account, keyType)
+		account, client = setupServerClient(ctx, servers[0])
 
 		ariRenewalTime = getARIRenewalTime(ctx, cert, domain, client)
 		if ariRenewalTime != nil {
@@ -308,10 +393,6 @@ func renewForCSR(ctx *cli.Context, account *Account, keyType certcrypto.KeyType,
 		return nil
 	}
 
-	if client == nil {
-		client = setupClient(ctx, account, keyType)
-	}
-
 	// This is just meant to be informal for the user.
 	timeLeft := cert.NotAfter.Sub(time.Now().UTC())
 	log.Infof("[%s] acme: Trying renewal with %d hours remaining", domain, int(timeLeft.Hours()))
@@ -322,17 +403,31 @@ func renewForCSR(ctx *cli.Context, account *Account, keyType certcrypto.KeyType,
 		NotAfter:                       getTime(ctx, flgNotAfter),
 		Bundle:                         bundle,
 		PreferredChain:                 ctx.String(flgPreferredChain),
-		Profile:                        ctx.String(flgProfile),
+		ChainPreference:                getChainPreference(ctx),
+		AlternateChains:                ctx.Bool(flgAlternateChains),
+		Profile:                        getRenewalProfile(ctx, certsStorage, domain),
 		AlwaysDeactivateAuthorizations: ctx.Bool(flgAlwaysDeactivateAuthorizations),
+		CAAPreflight:                   ctx.Bool(flgCAAPreflight),
 	}
 
-	if replacesCertID != "" {
-		request.ReplacesCertID = replacesCertID
+	obtain := func(_ *cli.Context, server caServer, client *lego.Client) (*certificate.Resource, error) {
+		// The ARI CertID is only known by the CA that issued the certificate.
+		if server.URL == servers[0].URL {
+			request.ReplacesCertID = replacesCertID
+		} else {
+			request.ReplacesCertID = ""
+		}
+
+		return client.Certificate.ObtainForCSR(request)
 	}
 
-	certRes, err := client.Certificate.ObtainForCSR(request)
+	_, certRes, err := obtainWithFailover(ctx, servers, account, client, obtain)
 	if err != nil {
-		log.Fatal(err)
+		if isRateLimited(domain, err) {
+			return nil
+		}
+
+		log.Fatal(userActionRequired(err))
 	}
 
 	certsStorage.SaveResource(certRes)
@@ -342,6 +437,48 @@ func renewForCSR(ctx *cli.Context, account *Account, keyType certcrypto.KeyType,
 	return launchHook(ctx.String(flgRenewHook), ctx.Duration(flgRenewHookTimeout), meta)
 }
 
+// getCertificateIssuer returns the URL of the CA that issued the certificate, if it is known.
+func getCertificateIssuer(certsStorage *CertificatesStorage, domain string) string {
+	if !certsStorage.ExistsFile(domain, resourceExt) {
+		return ""
+	}
+
+	return certsStorage.ReadResource(domain).CADirURL
+}
+
+// getRenewalProfile returns the profile to use for the renewal:
+// the profile defined by the flag, otherwise the profile of the certificate to renew.
+func getRenewalProfile(ctx *cli.Context, certsStorage *CertificatesStorage, domain string) string {
+	if ctx.IsSet(flgProfile) || !certsStorage.ExistsFile(domain, resourceExt) {
+		return ctx.String(flgProfile)
+	}
+
+	profile := certsStorage.ReadResource(domain).Profile
+	if profile != "" {
+		log.Infof("[%s] acme: Using the profile %q of the certificate", domain, profile)
+	}
+
+	return profile
+}
+
+// isRateLimited returns true if the renewal has been rejected by the rate limits of the CA.
+// In this case, the renewal is postponed to the next run instead of failing.
+func isRateLimited(domain string, err error) bool {
+	var rateLimitErr *acme.RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		return false
+	}
+
+	if rateLimitErr.RetryAfter.IsZero() {
+		log.Warnf("[%s] The renewal is rate limited, it will be retried on the next run: %v", domain, err)
+	} else {
+		log.Warnf("[%s] The renewal is rate limited until %s, it will be retried on the next run: %v",
+			domain, rateLimitErr.RetryAfter.Format(time.RFC3339), err)
+	}
+
+	return true
+}
+
 func needRenewal(x509Cert *x509.Certificate, domain string, days int) bool {
 	if x509Cert.IsCA {
 		log.Fatalf("[%s] Certificate bundle starts with a CA certificate", domain)
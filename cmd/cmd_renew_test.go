@@ -2,9 +2,12 @@ package cmd
 
 import (
 	"crypto/x509"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/go-acme/lego/v4/acme"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -114,3 +117,44 @@ func Test_needRenewal(t *testing.T) {
 		})
 	}
 }
+
+func Test_isRateLimited(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		err      error
+		expected bool
+	}{
+		{
+			desc:     "other error",
+			err:      errors.New("oops"),
+			expected: false,
+		},
+		{
+			desc:     "problem",
+			err:      &acme.ProblemDetails{Type: "urn:ietf:params:acme:error:malformed"},
+			expected: false,
+		},
+		{
+			desc: "rate limited",
+			err: &acme.RateLimitError{
+				ProblemDetails: &acme.ProblemDetails{Type: acme.RateLimitedErr},
+				RetryAfter:     time.Now().Add(time.Hour),
+			},
+			expected: true,
+		},
+		{
+			desc: "wrapped rate limit without Retry-After",
+			err: fmt.Errorf("error: one or more domains had a problem:\n%w",
+				&acme.RateLimitError{ProblemDetails: &acme.ProblemDetails{Type: acme.RateLimitedErr}}),
+			expected: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, isRateLimited("example.com", test.err))
+		})
+	}
+}
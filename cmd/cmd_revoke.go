@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/lego"
 	"github.com/go-acme/lego/v4/log"
 	"github.com/urfave/cli/v2"
 )
@@ -38,20 +39,26 @@ func createRevoke() *cli.Command {
 }
 
 func revoke(ctx *cli.Context) error {
-	account, keyType := setupAccount(ctx, NewAccountsStorage(ctx))
-
-	if account.Registration == nil {
-		log.Fatalf("Account %s is not registered. Use 'run' to register a new account.\n", account.Email)
-	}
-
-	client := newClient(ctx, account, keyType)
-
 	certsStorage := NewCertificatesStorage(ctx)
 	certsStorage.CreateRootFolder()
 
-	for _, domain := range ctx.StringSlice(flgDomains) {
+	clients := map[string]*lego.Client{}
+
+	for _, domain := range getDomains(ctx) {
 		log.Printf("Trying to revoke certificate for domain %s", domain)
 
+		// The certificate is revoked by the CA that issued it.
+		server := getServer(ctx)
+		if issuer := getCertificateIssuer(certsStorage, domain); issuer != "" {
+			server = caServer{URL: issuer}
+		}
+
+		client, ok := clients[server.URL]
+		if !ok {
+			client = newRevocationClient(ctx, server)
+			clients[server.URL] = client
+		}
+
 		certBytes, err := certsStorage.ReadFile(domain, certExt)
 		if err != nil {
 			log.Fatalf("Error while revoking the certificate for domain %s\n\t%v", domain, err)
@@ -82,3 +89,14 @@ func revoke(ctx *cli.Context) error {
 
 	return nil
 }
+
+// newRevocationClient creates a client for the ACME server, the account must be registered.
+func newRevocationClient(ctx *cli.Context, server caServer) *lego.Client {
+	account, keyType := setupAccount(ctx, newAccountsStorage(ctx, server.URL))
+
+	if account.Registration == nil {
+		log.Fatalf("Account %s is not registered on %s. Use 'run' to register a new account.\n", account.Email, server.URL)
+	}
+
+	return newClient(ctx, server, account, keyType)
+}
@@ -21,8 +21,13 @@ const (
 	flgNotBefore                      = "not-before"
 	flgNotAfter                       = "not-after"
 	flgPreferredChain                 = "preferred-chain"
+	flgPreferredChainSPKI             = "preferred-chain.spki"
+	flgPreferredChainKeyAlgorithm     = "preferred-chain.key-algorithm"
+	flgPreferredChainShortest         = "preferred-chain.shortest"
+	flgAlternateChains                = "alternate-chains"
 	flgProfile                        = "profile"
 	flgAlwaysDeactivateAuthorizations = "always-deactivate-authorizations"
+	flgCAAPreflight                   = "caa-preflight"
 	flgRunHook                        = "run-hook"
 	flgRunHookTimeout                 = "run-hook-timeout"
 )
@@ -41,10 +46,13 @@ func createRun() *cli.Command {
 			if !hasDomains && !hasCsr {
 				log.Fatal("Please specify --domains/-d (or --csr/-c if you already have a CSR)")
 			}
+			if ctx.Bool(flgNextKey) && hasCsr {
+				log.Fatalf("--%s only works with --domains/-d, --csr/-c doesn't support this option.", flgNextKey)
+			}
 			return nil
 		},
 		Action: run,
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			&cli.BoolFlag{
 				Name:  flgNoBundle,
 				Usage: "Do not create a certificate bundle by adding the issuers certificate to the new certificate.",
@@ -69,6 +77,24 @@ func createRun() *cli.Command {
 				Usage: "If the CA offers multiple certificate chains, prefer the chain with an issuer matching this Subject Common Name." +
 					" If no match, the default offered chain will be used.",
 			},
+			&cli.StringSliceFlag{
+				Name: flgPreferredChainSPKI,
+				Usage: "If the CA offers multiple certificate chains, prefer the chain containing a certificate with this SHA-256 fingerprint of the Subject Public Key Info (hex)." +
+					" If no match, the default offered chain will be used.",
+			},
+			&cli.StringFlag{
+				Name: flgPreferredChainKeyAlgorithm,
+				Usage: "If the CA offers multiple certificate chains, prefer the chain signed only with this key algorithm (rsa, ecdsa, ed25519)." +
+					" If no match, the default offered chain will be used.",
+			},
+			&cli.BoolFlag{
+				Name:  flgPreferredChainShortest,
+				Usage: "If the CA offers multiple certificate chains, prefer the shortest chain.",
+			},
+			&cli.BoolFlag{
+				Name:  flgAlternateChains,
+				Usage: "Store the alternate certificate chains offered by the CA alongside the certificate.",
+			},
 			&cli.StringFlag{
 				Name:  flgProfile,
 				Usage: "If the CA offers multiple certificate profiles (draft-aaron-acme-profiles), choose this one.",
@@ -77,6 +103,11 @@ func createRun() *cli.Command {
 				Name:  flgAlwaysDeactivateAuthorizations,
 				Usage: "Force the authorizations to be relinquished even if the certificate request was successful.",
 			},
+			&cli.BoolFlag{
+				Name: flgCAAPreflight,
+				Usage: "Check the CAA records (RFC 8659, RFC 8657) of the domains before creating the order." +
+					" Fails if the CAA records don't allow the CA, the account or the challenge to be used.",
+			},
 			&cli.StringFlag{
 				Name:  flgRunHook,
 				Usage: "Define a hook. The hook is executed when the certificates are effectively created.",
@@ -86,7 +117,7 @@ func createRun() *cli.Command {
 				Usage: "Define the timeout for the hook execution.",
 				Value: 2 * time.Minute,
 			},
-		},
+		}, createDANEFlags()...),
 	}
 }
 
@@ -102,38 +133,23 @@ backups of this folder is ideal.
 `
 
 func run(ctx *cli.Context) error {
-	accountsStorage := NewAccountsStorage(ctx)
-
-	account, keyType := setupAccount(ctx, accountsStorage)
-
-	client := setupClient(ctx, account, keyType)
-
-	if account.Registration == nil {
-		reg, err := register(ctx, client)
-		if err != nil {
-			log.Fatalf("Could not complete registration\n\t%v", err)
-		}
-
-		account.Registration = reg
-		if err = accountsStorage.Save(account); err != nil {
-			log.Fatal(err)
-		}
-
-		fmt.Printf(rootPathWarningMessage, accountsStorage.GetRootPath())
-	}
-
 	certsStorage := NewCertificatesStorage(ctx)
 	certsStorage.CreateRootFolder()
 
-	cert, err := obtainCertificate(ctx, client)
+	account, cert, err := obtainWithFailover(ctx, getServers(ctx), nil, nil, obtainCertificate)
 	if err != nil {
 		// Make sure to return a non-zero exit code if ObtainSANCertificate returned at least one error.
 		// Due to us not returning partial certificate we can just exit here instead of at the end.
-		log.Fatalf("Could not obtain certificates:\n\t%v", err)
+		log.Fatalf("Could not obtain certificates:\n\t%v", userActionRequired(err))
 	}
 
 	certsStorage.SaveResource(cert)
 
+	err = prepareNextKey(ctx, certsStorage, cert.Domain)
+	if err != nil {
+		log.Warnf("[%s] Could not prepare the next private key: %v", cert.Domain, err)
+	}
+
 	meta := map[string]string{
 		hookEnvAccountEmail: account.Email,
 	}
@@ -171,34 +187,31 @@ func handleTOS(ctx *cli.Context, client *lego.Client) bool {
 	}
 }
 
-func register(ctx *cli.Context, client *lego.Client) (*registration.Resource, error) {
+func register(ctx *cli.Context, client *lego.Client, server caServer) (*registration.Resource, error) {
 	accepted := handleTOS(ctx, client)
 	if !accepted {
 		log.Fatal("You did not accept the TOS. Unable to proceed.")
 	}
 
-	if ctx.Bool(flgEAB) {
-		kid := ctx.String(flgKID)
-		hmacEncoded := ctx.String(flgHMAC)
-
-		if kid == "" || hmacEncoded == "" {
+	if server.EAB {
+		if server.KID == "" || server.HMAC == "" {
 			log.Fatalf("Requires arguments --%s and --%s.", flgKID, flgHMAC)
 		}
 
 		return client.Registration.RegisterWithExternalAccountBinding(registration.RegisterEABOptions{
 			TermsOfServiceAgreed: accepted,
-			Kid:                  kid,
-			HmacEncoded:          hmacEncoded,
+			Kid:                  server.KID,
+			HmacEncoded:          server.HMAC,
 		})
 	}
 
 	return client.Registration.Register(registration.RegisterOptions{TermsOfServiceAgreed: true})
 }
 
-func obtainCertificate(ctx *cli.Context, client *lego.Client) (*certificate.Resource, error) {
+func obtainCertificate(ctx *cli.Context, _ caServer, client *lego.Client) (*certificate.Resource, error) {
 	bundle := !ctx.Bool(flgNoBundle)
 
-	domains := ctx.StringSlice(flgDomains)
+	domains := getDomains(ctx)
 	if len(domains) > 0 {
 		// obtain a certificate, generating a new private key
 		request := certificate.ObtainRequest{
@@ -206,8 +219,11 @@ func obtainCertificate(ctx *cli.Context, client *lego.Client) (*certificate.Reso
 			Bundle:                         bundle,
 			MustStaple:                     ctx.Bool(flgMustStaple),
 			PreferredChain:                 ctx.String(flgPreferredChain),
+			ChainPreference:                getChainPreference(ctx),
+			AlternateChains:                ctx.Bool(flgAlternateChains),
 			Profile:                        ctx.String(flgProfile),
 			AlwaysDeactivateAuthorizations: ctx.Bool(flgAlwaysDeactivateAuthorizations),
+			CAAPreflight:                   ctx.Bool(flgCAAPreflight),
 		}
 
 		notBefore := ctx.Timestamp(flgNotBefore)
@@ -236,8 +252,11 @@ func obtainCertificate(ctx *cli.Context, client *lego.Client) (*certificate.Reso
 		NotAfter:                       getTime(ctx, flgNotAfter),
 		Bundle:                         bundle,
 		PreferredChain:                 ctx.String(flgPreferredChain),
+		ChainPreference:                getChainPreference(ctx),
+		AlternateChains:                ctx.Bool(flgAlternateChains),
 		Profile:                        ctx.String(flgProfile),
 		AlwaysDeactivateAuthorizations: ctx.Bool(flgAlwaysDeactivateAuthorizations),
+		CAAPreflight:                   ctx.Bool(flgCAAPreflight),
 	}
 
 	return client.Certificate.ObtainForCSR(request)
@@ -0,0 +1,389 @@
+package cmd
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/go-acme/lego/v4/log"
+	"github.com/go-acme/lego/v4/providers/dns"
+	"github.com/go-acme/lego/v4/providers/dns/records"
+	"github.com/urfave/cli/v2"
+)
+
+// Flag names.
+const (
+	flgNextKey      = "next-key"
+	flgTLSADNS      = "tlsa.dns"
+	flgTLSAPort     = "tlsa.port"
+	flgTLSAProtocol = "tlsa.protocol"
+	flgTLSATTL      = "tlsa.ttl"
+	flgTLSAOverlap  = "tlsa.overlap"
+)
+
+// tlsaRecord a TLSA record published for a certificate.
+type tlsaRecord struct {
+	Zone  string `json:"zone"`
+	FQDN  string `json:"fqdn"`
+	Value string `json:"value"`
+	TTL   int    `json:"ttl"`
+
+	// PublishedAt the time when the record has been published.
+	PublishedAt *time.Time `json:"publishedAt,omitempty"`
+
+	// RetireAt the time after which the record is deleted (the key of the record is not used anymore).
+	RetireAt *time.Time `json:"retireAt,omitempty"`
+}
+
+func (r tlsaRecord) record() records.Record {
+	return records.Record{FQDN: r.FQDN, Type: records.TypeTLSA, Value: r.Value, TTL: r.TTL}
+}
+
+func createDANEFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name: flgNextKey,
+			Usage: "Pre-generate and store the private key of the next certificate, and use it for the renewal." +
+				" With --tlsa.dns, the next private key is only used once its TLSA records are published for twice their TTL." +
+				" Only works if the CSR is generated by lego.",
+		},
+		&cli.StringFlag{
+			Name: flgTLSADNS,
+			Usage: "Publish the TLSA records (DANE-EE, SPKI, SHA-256) of the current and next private keys with this DNS provider." +
+				" The DNS provider must support the management of DNS records.",
+		},
+		&cli.IntSliceFlag{
+			Name:  flgTLSAPort,
+			Usage: "The port of the services of the TLSA records. Supports multiple values.",
+			Value: cli.NewIntSlice(443),
+		},
+		&cli.StringFlag{
+			Name:  flgTLSAProtocol,
+			Usage: "The protocol of the services of the TLSA records.",
+			Value: "tcp",
+		},
+		&cli.IntFlag{
+			Name:  flgTLSATTL,
+			Usage: "The TTL of the TLSA records.",
+			Value: 3600,
+		},
+		&cli.DurationFlag{
+			Name:  flgTLSAOverlap,
+			Usage: "The duration during which the TLSA records of a replaced private key are kept. Should be longer than twice the TTL.",
+			Value: 48 * time.Hour,
+		},
+	}
+}
+
+// prepareNextKey creates the next private key of the certificate if needed,
+// and publishes the TLSA records of the current and next private keys.
+func prepareNextKey(ctx *cli.Context, certsStorage *CertificatesStorage, domain string) error {
+	if ctx.Bool(flgNextKey) && !certsStorage.ExistsFile(domain, nextKeyExt) {
+		err := writeNextKey(ctx, certsStorage, domain)
+		if err != nil {
+			return err
+		}
+	}
+
+	return publishTLSA(ctx, certsStorage, domain)
+}
+
+// isNextKeyReady returns true if the next private key can be used:
+// its TLSA records must be published for at least twice their TTL,
+// to let the resolvers that cached the previous TLSA records fetch the new ones.
+func isNextKeyReady(ctx *cli.Context, certsStorage *CertificatesStorage, domain string, now time.Time) (bool, error) {
+	if ctx.String(flgTLSADNS) == "" {
+		return true, nil
+	}
+
+	certs, err := certsStorage.ReadCertificate(domain, certExt)
+	if err != nil {
+		return false, err
+	}
+
+	nextKey, err := readNextKey(certsStorage, domain)
+	if err != nil {
+		return false, fmt.Errorf("next private key: %w", err)
+	}
+
+	next, err := getTLSARecords(ctx, certs[0], []crypto.PublicKey{nextKey.(crypto.Signer).Public()})
+	if err != nil {
+		return false, err
+	}
+
+	published, err := readTLSAState(certsStorage, domain)
+	if err != nil {
+		return false, err
+	}
+
+	return isTLSAPublished(published, next, now), nil
+}
+
+// isTLSAPublished returns true if all the records are published for at least twice their TTL.
+func isTLSAPublished(published, records []tlsaRecord, now time.Time) bool {
+	for _, record := range records {
+		found := false
+
+		for _, p := range published {
+			if p.FQDN != record.FQDN || p.Value != record.Value || p.PublishedAt == nil {
+				continue
+			}
+
+			found = !now.Before(p.PublishedAt.Add(2 * time.Duration(p.TTL) * time.Second))
+
+			break
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// readNextKey reads the next private key of the certificate.
+func readNextKey(certsStorage *CertificatesStorage, domain string) (crypto.PrivateKey, error) {
+	keyBytes, err := certsStorage.ReadFile(domain, nextKeyExt)
+	if err != nil {
+		return nil, err
+	}
+
+	return certcrypto.ParsePEMPrivateKey(keyBytes)
+}
+
+// writeNextKey generates and stores a new next private key of the certificate.
+func writeNextKey(ctx *cli.Context, certsStorage *CertificatesStorage, domain string) error {
+	privateKey, err := certcrypto.GeneratePrivateKey(getKeyType(ctx))
+	if err != nil {
+		return err
+	}
+
+	err = certsStorage.WriteFile(domain, nextKeyExt, pem.EncodeToMemory(certcrypto.PEMBlock(privateKey)))
+	if err != nil {
+		return err
+	}
+
+	log.Infof("[%s] Next private key stored in %s", domain, certsStorage.GetFileName(domain, nextKeyExt))
+
+	return nil
+}
+
+// publishTLSA publishes the TLSA records of the current and next private keys of the certificate,
+// and deletes the TLSA records of the replaced private keys after the overlap.
+func publishTLSA(ctx *cli.Context, certsStorage *CertificatesStorage, domain string) error {
+	if ctx.String(flgTLSADNS) == "" {
+		return nil
+	}
+
+	provider, err := newRecordsProvider(ctx.String(flgTLSADNS))
+	if err != nil {
+		return err
+	}
+
+	desired, err := getDesiredTLSA(ctx, certsStorage, domain)
+	if err != nil {
+		return err
+	}
+
+	published, err := readTLSAState(certsStorage, domain)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+
+	upsert, remove, state := planTLSA(published, desired, now, ctx.Duration(flgTLSAOverlap))
+
+	for _, record := range upsert {
+		record.Zone, err = dns01.FindZoneByFqdn(record.FQDN)
+		if err != nil {
+			return fmt.Errorf("could not find the zone of %s: %w", record.FQDN, err)
+		}
+
+		err = provider.UpsertRecord(record.Zone, record.record())
+		if err != nil {
+			return err
+		}
+
+		log.Infof("[%s] TLSA record published: %s %s", domain, record.FQDN, record.Value)
+
+		record.PublishedAt = &now
+
+		state = append(state, record)
+	}
+
+	for _, record := range remove {
+		err = provider.DeleteRecord(record.Zone, record.record())
+		if err != nil {
+			// The record is kept in the state, the deletion will be retried.
+			state = append(state, record)
+
+			log.Warnf("[%s] Could not delete the TLSA record %s %s: %v", domain, record.FQDN, record.Value, err)
+
+			continue
+		}
+
+		log.Infof("[%s] TLSA record deleted: %s %s", domain, record.FQDN, record.Value)
+	}
+
+	return writeTLSAState(certsStorage, domain, state)
+}
+
+// planTLSA compares the published TLSA records with the desired TLSA records.
+// Returns the records to publish, the records to delete, and the published records to keep.
+func planTLSA(published, desired []tlsaRecord, now time.Time, overlap time.Duration) (upsert, remove, state []tlsaRecord) {
+	isDesired := func(record tlsaRecord) bool {
+		for _, d := range desired {
+			if d.FQDN == record.FQDN && d.Value == record.Value {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for _, record := range published {
+		switch {
+		case isDesired(record):
+			record.RetireAt = nil
+
+			if record.PublishedAt == nil {
+				// The publication time is unknown (state created by a previous version).
+				record.PublishedAt = &now
+			}
+
+			state = append(state, record)
+
+		case record.RetireAt == nil:
+			retireAt := now.Add(overlap)
+			record.RetireAt = &retireAt
+			state = append(state, record)
+
+		case !now.Before(*record.RetireAt):
+			remove = append(remove, record)
+
+		default:
+			state = append(state, record)
+		}
+	}
+
+	for _, record := range desired {
+		found := false
+
+		for _, p := range state {
+			if p.FQDN == record.FQDN && p.Value == record.Value {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			upsert = append(upsert, record)
+		}
+	}
+
+	return upsert, remove, state
+}
+
+// getDesiredTLSA returns the TLSA records of the current and next private keys, for each domain and port.
+func getDesiredTLSA(ctx *cli.Context, certsStorage *CertificatesStorage, domain string) ([]tlsaRecord, error) {
+	certs, err := certsStorage.ReadCertificate(domain, certExt)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []crypto.PublicKey{certs[0].PublicKey}
+
+	if certsStorage.ExistsFile(domain, nextKeyExt) {
+		nextKey, errN := readNextKey(certsStorage, domain)
+		if errN != nil {
+			return nil, fmt.Errorf("next private key: %w", errN)
+		}
+
+		keys = append(keys, nextKey.(crypto.Signer).Public())
+	}
+
+	return getTLSARecords(ctx, certs[0], keys)
+}
+
+// getTLSARecords returns the TLSA records of the keys, for each domain of the certificate and each port.
+func getTLSARecords(ctx *cli.Context, cert *x509.Certificate, keys []crypto.PublicKey) ([]tlsaRecord, error) {
+	var desired []tlsaRecord
+
+	for _, name := range certcrypto.ExtractDomains(cert) {
+		// The TLSA records cannot be published for wildcard domains and IP addresses.
+		if strings.HasPrefix(name, "*.") || net.ParseIP(name) != nil {
+			continue
+		}
+
+		for _, port := range ctx.IntSlice(flgTLSAPort) {
+			fqdn := records.TLSAName(port, ctx.String(flgTLSAProtocol), name)
+
+			for _, key := range keys {
+				record, errT := records.NewTLSA(fqdn, key, ctx.Int(flgTLSATTL))
+				if errT != nil {
+					return nil, errT
+				}
+
+				desired = append(desired, tlsaRecord{FQDN: record.FQDN, Value: record.Value, TTL: record.TTL})
+			}
+		}
+	}
+
+	return desired, nil
+}
+
+func newRecordsProvider(name string) (records.Provider, error) {
+	provider, err := dns.NewDNSChallengeProviderByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	recordsProvider, ok := provider.(records.Provider)
+	if !ok {
+		return nil, fmt.Errorf("the DNS provider %s doesn't support the management of DNS records", name)
+	}
+
+	return recordsProvider, nil
+}
+
+func readTLSAState(certsStorage *CertificatesStorage, domain string) ([]tlsaRecord, error) {
+	if !certsStorage.ExistsFile(domain, tlsaExt) {
+		return nil, nil
+	}
+
+	data, err := certsStorage.ReadFile(domain, tlsaExt)
+	if err != nil {
+		return nil, err
+	}
+
+	var state []tlsaRecord
+
+	err = json.Unmarshal(data, &state)
+	if err != nil {
+		return nil, fmt.Errorf("TLSA records file: %w", err)
+	}
+
+	return state, nil
+}
+
+func writeTLSAState(certsStorage *CertificatesStorage, domain string, state []tlsaRecord) error {
+	if state == nil {
+		state = []tlsaRecord{}
+	}
+
+	data, err := json.MarshalIndent(state, "", "\t")
+	if err != nil {
+		return fmt.Errorf("TLSA records file: %w", err)
+	}
+
+	return certsStorage.WriteFile(domain, tlsaExt, data)
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_planTLSA(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+	retireAt := now.Add(48 * time.Hour)
+
+	current := tlsaRecord{Zone: "example.com.", FQDN: "_443._tcp.example.com.", Value: "3 1 1 aa", TTL: 3600}
+	next := tlsaRecord{FQDN: "_443._tcp.example.com.", Value: "3 1 1 bb", TTL: 3600}
+	previous := tlsaRecord{Zone: "example.com.", FQDN: "_443._tcp.example.com.", Value: "3 1 1 cc", TTL: 3600}
+
+	withRetireAt := func(record tlsaRecord, retireAt time.Time) tlsaRecord {
+		record.RetireAt = &retireAt
+		return record
+	}
+
+	withPublishedAt := func(record tlsaRecord, publishedAt time.Time) tlsaRecord {
+		record.PublishedAt = &publishedAt
+		return record
+	}
+
+	published := withPublishedAt(current, past)
+
+	testCases := []struct {
+		desc           string
+		published      []tlsaRecord
+		desired        []tlsaRecord
+		expectedUpsert []tlsaRecord
+		expectedRemove []tlsaRecord
+		expectedState  []tlsaRecord
+	}{
+		{
+			desc:           "nothing published",
+			desired:        []tlsaRecord{current, next},
+			expectedUpsert: []tlsaRecord{current, next},
+		},
+		{
+			desc:          "already published",
+			published:     []tlsaRecord{published},
+			desired:       []tlsaRecord{current},
+			expectedState: []tlsaRecord{published},
+		},
+		{
+			desc:          "publication time unknown",
+			published:     []tlsaRecord{current},
+			desired:       []tlsaRecord{current},
+			expectedState: []tlsaRecord{withPublishedAt(current, now)},
+		},
+		{
+			desc:           "key replaced",
+			published:      []tlsaRecord{previous, published},
+			desired:        []tlsaRecord{current, next},
+			expectedUpsert: []tlsaRecord{next},
+			expectedState:  []tlsaRecord{withRetireAt(previous, retireAt), published},
+		},
+		{
+			desc:          "overlap not elapsed",
+			published:     []tlsaRecord{withRetireAt(previous, future), published},
+			desired:       []tlsaRecord{current},
+			expectedState: []tlsaRecord{withRetireAt(previous, future), published},
+		},
+		{
+			desc:           "overlap elapsed",
+			published:      []tlsaRecord{withRetireAt(previous, past), published},
+			desired:        []tlsaRecord{current},
+			expectedRemove: []tlsaRecord{withRetireAt(previous, past)},
+			expectedState:  []tlsaRecord{published},
+		},
+		{
+			desc:          "retired key used again",
+			published:     []tlsaRecord{withRetireAt(published, future)},
+			desired:       []tlsaRecord{current},
+			expectedState: []tlsaRecord{published},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			upsert, remove, state := planTLSA(test.published, test.desired, now, 48*time.Hour)
+
+			assert.Equal(t, test.expectedUpsert, upsert)
+			assert.Equal(t, test.expectedRemove, remove)
+			assert.Equal(t, test.expectedState, state)
+		})
+	}
+}
+
+func Test_isTLSAPublished(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	withPublishedAt := func(record tlsaRecord, publishedAt time.Time) tlsaRecord {
+		record.PublishedAt = &publishedAt
+		return record
+	}
+
+	next := tlsaRecord{FQDN: "_443._tcp.example.com.", Value: "3 1 1 bb", TTL: 3600}
+	nextWWW := tlsaRecord{FQDN: "_443._tcp.www.example.com.", Value: "3 1 1 bb", TTL: 3600}
+
+	testCases := []struct {
+		desc      string
+		published []tlsaRecord
+		records   []tlsaRecord
+		expected  assert.BoolAssertionFunc
+	}{
+		{
+			desc:     "no records",
+			expected: assert.True,
+		},
+		{
+			desc:     "not published",
+			records:  []tlsaRecord{next},
+			expected: assert.False,
+		},
+		{
+			desc:      "published for less than twice the TTL",
+			published: []tlsaRecord{withPublishedAt(next, now.Add(-time.Hour))},
+			records:   []tlsaRecord{next},
+			expected:  assert.False,
+		},
+		{
+			desc:      "published for twice the TTL",
+			published: []tlsaRecord{withPublishedAt(next, now.Add(-2*time.Hour))},
+			records:   []tlsaRecord{next},
+			expected:  assert.True,
+		},
+		{
+			desc:      "publication time unknown",
+			published: []tlsaRecord{next},
+			records:   []tlsaRecord{next},
+			expected:  assert.False,
+		},
+		{
+			desc:      "one record recently published",
+			published: []tlsaRecord{withPublishedAt(next, now.Add(-3*time.Hour)), withPublishedAt(nextWWW, now.Add(-time.Minute))},
+			records:   []tlsaRecord{next, nextWWW},
+			expected:  assert.False,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			test.expected(t, isTLSAPublished(test.published, test.records, now))
+		})
+	}
+}
@@ -0,0 +1,353 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/log"
+	"github.com/go-acme/lego/v4/registration"
+)
+
+// acmeShDefaultServer the default ACME server of acme.sh.
+const acmeShDefaultServer = "https://acme.zerossl.com/v2/DV90"
+
+// acmeShDNSHooks the acme.sh DNS API hooks with a name different from the name of the lego DNS provider.
+var acmeShDNSHooks = map[string]string{
+	"dns_acmedns":       "acmedns",
+	"dns_ali":           "alidns",
+	"dns_aws":           "route53",
+	"dns_azure":         "azuredns",
+	"dns_cf":            "cloudflare",
+	"dns_dgon":          "digitalocean",
+	"dns_dp":            "dnspod",
+	"dns_gandi_livedns": "gandiv5",
+	"dns_gd":            "godaddy",
+	"dns_he":            "hurricane",
+	"dns_linode_v4":     "linode",
+	"dns_me":            "dnsmadeeasy",
+	"dns_nsone":         "ns1",
+	"dns_nsupdate":      "rfc2136",
+}
+
+// readAcmeSh reads the accounts and the certificates from the home directory of acme.sh.
+//
+//	~/.acme.sh/
+//	├── account.conf
+//	├── ca/<server host>/<server path>/{account.key,account.json,ca.conf}
+//	├── <domain>/{<domain>.conf,<domain>.cer,<domain>.key,ca.cer}
+//	└── <domain>_ecc/{<domain>.conf,<domain>.cer,<domain>.key,ca.cer}
+func readAcmeSh(source, defaultEmail string) ([]importedAccount, []importedCertificate, error) {
+	globalConf, err := readAcmeShConf(filepath.Join(source, "account.conf"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, fmt.Errorf("acme.sh account.conf: %w", err)
+	}
+
+	if email := globalConf["ACCOUNT_EMAIL"]; email != "" && defaultEmail == "" {
+		defaultEmail = email
+	}
+
+	accounts, err := readAcmeShAccounts(filepath.Join(source, "ca"), defaultEmail)
+	if err != nil {
+		return nil, nil, fmt.Errorf("acme.sh accounts: %w", err)
+	}
+
+	defaultServer := globalConf["DEFAULT_ACME_SERVER"]
+	if defaultServer == "" {
+		defaultServer = acmeShDefaultServer
+	}
+
+	entries, err := os.ReadDir(source)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var certificates []importedCertificate
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		dir := filepath.Join(source, entry.Name())
+		domain := strings.TrimSuffix(entry.Name(), "_ecc")
+
+		if _, err = os.Stat(filepath.Join(dir, domain+".conf")); err != nil {
+			// not a certificate directory (e.g. `ca`, `deploy`, `dnsapi`).
+			continue
+		}
+
+		cert, err := readAcmeShCertificate(dir, domain, defaultServer)
+		if err != nil {
+			return nil, nil, fmt.Errorf("acme.sh certificate %s: %w", entry.Name(), err)
+		}
+
+		for _, account := range accounts {
+			if account.Server == cert.Server {
+				cert.Email = account.Email
+				break
+			}
+		}
+
+		certificates = append(certificates, cert)
+	}
+
+	return accounts, certificates, nil
+}
+
+func readAcmeShAccounts(root, defaultEmail string) ([]importedAccount, error) {
+	var accounts []importedAccount
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || d.Name() != "account.key" {
+			return nil
+		}
+
+		account, err := readAcmeShAccount(root, filepath.Dir(path), defaultEmail)
+		if err != nil {
+			return fmt.Errorf("%s: %w", filepath.Dir(path), err)
+		}
+
+		if account.Registration.URI == "" {
+			log.Warnf("The acme.sh account %s is not registered, skipping.", filepath.Dir(path))
+			return nil
+		}
+
+		accounts = append(accounts, account)
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+func readAcmeShAccount(root, dir, defaultEmail string) (importedAccount, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "account.key"))
+	if err != nil {
+		return importedAccount{}, err
+	}
+
+	privateKey, err := certcrypto.ParsePEMPrivateKey(data)
+	if err != nil {
+		return importedAccount{}, fmt.Errorf("private key: %w", err)
+	}
+
+	conf, err := readAcmeShConf(filepath.Join(dir, "ca.conf"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return importedAccount{}, err
+	}
+
+	var body acme.Account
+
+	data, err = os.ReadFile(filepath.Join(dir, "account.json"))
+	if err == nil {
+		err = json.Unmarshal(data, &body)
+		if err != nil {
+			return importedAccount{}, fmt.Errorf("account.json: %w", err)
+		}
+	}
+
+	email := conf["CA_EMAIL"]
+	if email == "" {
+		email = emailFromContacts(body.Contact)
+	}
+
+	if email == "" {
+		email = defaultEmail
+	}
+
+	// The path of the account directory is built from the URL of the ACME server directory:
+	// ca/acme-v02.api.letsencrypt.org/directory
+	server, err := filepath.Rel(root, dir)
+	if err != nil {
+		return importedAccount{}, err
+	}
+
+	return importedAccount{
+		Server:       "https://" + filepath.ToSlash(server),
+		Email:        email,
+		PrivateKey:   privateKey,
+		Registration: &registration.Resource{URI: conf["ACCOUNT_URL"], Body: body},
+	}, nil
+}
+
+func readAcmeShCertificate(dir, domain, defaultServer string) (importedCertificate, error) {
+	conf, err := readAcmeShConf(filepath.Join(dir, domain+".conf"))
+	if err != nil {
+		return importedCertificate{}, err
+	}
+
+	server := conf["Le_API"]
+	if server == "" {
+		server = defaultServer
+	}
+
+	var files [][]byte
+
+	for _, filename := range []string{domain + ".cer", "ca.cer", domain + ".key"} {
+		data, errR := os.ReadFile(filepath.Join(dir, filename))
+		if errR != nil {
+			return importedCertificate{}, errR
+		}
+
+		files = append(files, data)
+	}
+
+	resource, domains, err := newImportedResource(files[0], files[1], files[2], server)
+	if err != nil {
+		return importedCertificate{}, err
+	}
+
+	resource.CertURL = conf["Le_LinkCert"]
+
+	cert := importedCertificate{
+		Resource: resource,
+		Domains:  domains,
+		Server:   server,
+	}
+
+	cert.Options, cert.RenewOptions, err = acmeShOptions(domain, conf, files[0])
+	if err != nil {
+		return importedCertificate{}, err
+	}
+
+	return cert, nil
+}
+
+// acmeShOptions maps the renewal settings of acme.sh to the lego options.
+func acmeShOptions(domain string, conf map[string]string, certPEM []byte) ([]string, []string, error) {
+	var options []string
+
+	// Le_Webroot contains the validation mode of each domain, separated by commas.
+	webroot, _, _ := strings.Cut(conf["Le_Webroot"], ",")
+
+	switch {
+	case webroot == "" || webroot == "no":
+		options = append(options, "--"+flgHTTP)
+
+	case webroot == "alpn":
+		options = append(options, "--"+flgTLS)
+
+	case webroot == "dns":
+		log.Warnf("[%s] The acme.sh manual DNS mode is not supported.", domain)
+
+	case strings.HasPrefix(webroot, "dns_"):
+		provider, ok := acmeShDNSHooks[webroot]
+		if !ok {
+			provider = strings.TrimPrefix(webroot, "dns_")
+		}
+
+		options = append(options, "--"+flgDNS+"="+provider)
+
+	case strings.HasPrefix(webroot, "nginx") || strings.HasPrefix(webroot, "apache"):
+		log.Warnf("[%s] The acme.sh mode %q is not supported: the HTTP-01 challenge is used, the web server configuration must be adapted.", domain, webroot)
+
+		options = append(options, "--"+flgHTTP)
+
+	default:
+		options = append(options, "--"+flgHTTP, "--"+flgHTTPWebroot+"="+quoteArg(webroot))
+	}
+
+	keyType, err := acmeShKeyType(conf["Le_Keylength"])
+	if err != nil {
+		return nil, nil, fmt.Errorf("Le_Keylength: %w", err)
+	}
+
+	if keyType != "" {
+		options = append(options, "--"+flgKeyType+"="+keyType)
+	}
+
+	var renewOptions []string
+
+	if chain := conf["Le_PreferredChain"]; chain != "" {
+		renewOptions = append(renewOptions, "--"+flgPreferredChain+"="+quoteArg(chain))
+	}
+
+	// Le_RenewalDays is the number of days after the issuance, lego uses the number of days before the expiration.
+	if value := conf["Le_RenewalDays"]; value != "" {
+		renewalDays, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Le_RenewalDays: %w", err)
+		}
+
+		certs, err := certcrypto.ParsePEMBundle(certPEM)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		lifetime := int(certs[0].NotAfter.Sub(certs[0].NotBefore).Hours() / 24)
+		if days := lifetime - renewalDays; days > 0 {
+			renewOptions = append(renewOptions, fmt.Sprintf("--%s=%d", flgDays, days))
+		}
+	}
+
+	return options, renewOptions, nil
+}
+
+// acmeShKeyType maps the key length of acme.sh (`2048`, `ec-256`, etc.) to the lego key type.
+func acmeShKeyType(keyLength string) (string, error) {
+	switch keyLength {
+	case "":
+		return "", nil
+	case "ec-256":
+		return "ec256", nil
+	case "ec-384":
+		return "ec384", nil
+	case "2048", "3072", "4096", "8192":
+		return "rsa" + keyLength, nil
+	default:
+		return "", fmt.Errorf("unsupported key length %q", keyLength)
+	}
+}
+
+// readAcmeShConf reads a configuration file of acme.sh (shell variables, e.g. `Le_Domain='example.com'`).
+func readAcmeShConf(filename string) (map[string]string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	conf := map[string]string{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			continue
+		}
+
+		conf[key] = unquoteShell(value)
+	}
+
+	return conf, scanner.Err()
+}
+
+func unquoteShell(value string) string {
+	if len(value) >= 2 && (value[0] == '\'' || value[0] == '"') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+
+	return value
+}
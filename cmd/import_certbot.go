@@ -0,0 +1,387 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/lego"
+	"github.com/go-acme/lego/v4/log"
+	"github.com/go-acme/lego/v4/registration"
+	"github.com/go-jose/go-jose/v4"
+)
+
+// certbotDNSPlugins the certbot DNS plugins with a name different from the name of the lego DNS provider.
+var certbotDNSPlugins = map[string]string{
+	"dns-google":       "gcloud",
+	"dns-gandi":        "gandiv5",
+	"dns-nsone":        "ns1",
+	"dns-dnsimple":     "dnsimple",
+	"dns-ovh":          "ovh",
+	"dns-rfc2136":      "rfc2136",
+	"dns-luadns":       "luadns",
+	"dns-linode":       "linode",
+	"dns-sakuracloud":  "sakuracloud",
+	"dns-digitalocean": "digitalocean",
+	"dns-cloudxns":     "cloudxns",
+	"dns-dnsmadeeasy":  "dnsmadeeasy",
+	"dns-route53":      "route53",
+	"dns-cloudflare":   "cloudflare",
+}
+
+// certbotRegistration the content of the `regr.json` file of a certbot account.
+type certbotRegistration struct {
+	Body acme.Account `json:"body"`
+	URI  string       `json:"uri"`
+}
+
+// readCertbot reads the accounts and the certificates from the configuration directory of certbot.
+//
+//	/etc/letsencrypt/
+//	├── accounts/<server host>/<server path>/<account ID>/{private_key.json,regr.json}
+//	├── archive/<name>/
+//	├── live/<name>/{cert.pem,chain.pem,privkey.pem}
+//	└── renewal/<name>.conf
+func readCertbot(source, defaultEmail string) ([]importedAccount, []importedCertificate, error) {
+	accounts, accountIDs, err := readCertbotAccounts(filepath.Join(source, "accounts"), defaultEmail)
+	if err != nil {
+		return nil, nil, fmt.Errorf("certbot accounts: %w", err)
+	}
+
+	confs, err := filepath.Glob(filepath.Join(source, "renewal", "*.conf"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var certificates []importedCertificate
+
+	for _, conf := range confs {
+		name := strings.TrimSuffix(filepath.Base(conf), ".conf")
+
+		cert, err := readCertbotCertificate(source, name, conf)
+		if err != nil {
+			return nil, nil, fmt.Errorf("certbot certificate %s: %w", name, err)
+		}
+
+		if account, ok := accountIDs[cert.accountID]; ok {
+			cert.Email = account.Email
+		} else if cert.accountID != "" {
+			log.Warnf("[%s] The certbot account %s is not found.", name, cert.accountID)
+		}
+
+		certificates = append(certificates, cert.importedCertificate)
+	}
+
+	return accounts, certificates, nil
+}
+
+// readCertbotAccounts reads the accounts, and returns them also indexed by certbot account ID.
+func readCertbotAccounts(root, defaultEmail string) ([]importedAccount, map[string]importedAccount, error) {
+	var regrFiles []string
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() && d.Name() == "regr.json" {
+			regrFiles = append(regrFiles, path)
+		}
+
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, err
+	}
+
+	var accounts []importedAccount
+
+	accountIDs := map[string]importedAccount{}
+
+	for _, regrFile := range regrFiles {
+		dir := filepath.Dir(regrFile)
+
+		account, err := readCertbotAccount(root, dir, defaultEmail)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", dir, err)
+		}
+
+		accounts = append(accounts, account)
+		accountIDs[filepath.Base(dir)] = account
+	}
+
+	return accounts, accountIDs, nil
+}
+
+func readCertbotAccount(root, dir, defaultEmail string) (importedAccount, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "regr.json"))
+	if err != nil {
+		return importedAccount{}, err
+	}
+
+	var regr certbotRegistration
+
+	err = json.Unmarshal(data, &regr)
+	if err != nil {
+		return importedAccount{}, err
+	}
+
+	data, err = os.ReadFile(filepath.Join(dir, "private_key.json"))
+	if err != nil {
+		return importedAccount{}, err
+	}
+
+	var jwk jose.JSONWebKey
+
+	err = jwk.UnmarshalJSON(data)
+	if err != nil {
+		return importedAccount{}, fmt.Errorf("private key: %w", err)
+	}
+
+	if jwk.IsPublic() {
+		return importedAccount{}, fmt.Errorf("private key: public key found")
+	}
+
+	// The path of the account directory is built from the URL of the ACME server directory:
+	// accounts/acme-v02.api.letsencrypt.org/directory/<account ID>
+	server, err := filepath.Rel(root, filepath.Dir(dir))
+	if err != nil {
+		return importedAccount{}, err
+	}
+
+	email := emailFromContacts(regr.Body.Contact)
+	if email == "" {
+		email = defaultEmail
+	}
+
+	return importedAccount{
+		Server:       "https://" + filepath.ToSlash(server),
+		Email:        email,
+		PrivateKey:   jwk.Key,
+		Registration: &registration.Resource{URI: regr.URI, Body: regr.Body},
+	}, nil
+}
+
+// certbotCertificate a certificate of certbot, and the ID of its account.
+type certbotCertificate struct {
+	importedCertificate
+
+	accountID string
+}
+
+func readCertbotCertificate(source, name, conf string) (certbotCertificate, error) {
+	renewal, err := readCertbotRenewalConf(conf)
+	if err != nil {
+		return certbotCertificate{}, err
+	}
+
+	params := renewal["renewalparams"]
+
+	server := params["server"]
+	if server == "" {
+		server = lego.LEDirectoryProduction
+	}
+
+	live := filepath.Join(source, "live", name)
+
+	var files [][]byte
+
+	for _, filename := range []string{"cert.pem", "chain.pem", "privkey.pem"} {
+		data, errR := os.ReadFile(filepath.Join(live, filename))
+		if errR != nil {
+			return certbotCertificate{}, errR
+		}
+
+		files = append(files, data)
+	}
+
+	resource, domains, err := newImportedResource(files[0], files[1], files[2], server)
+	if err != nil {
+		return certbotCertificate{}, err
+	}
+
+	cert := certbotCertificate{
+		importedCertificate: importedCertificate{
+			Resource: resource,
+			Domains:  domains,
+			Server:   server,
+		},
+		accountID: params["account"],
+	}
+
+	cert.Options, cert.RenewOptions = certbotOptions(name, renewal)
+
+	return cert, nil
+}
+
+// certbotOptions maps the renewal parameters of certbot to the lego options.
+func certbotOptions(name string, renewal map[string]map[string]string) ([]string, []string) {
+	params := renewal["renewalparams"]
+
+	var options []string
+
+	switch authenticator := params["authenticator"]; {
+	case authenticator == "webroot":
+		options = append(options, "--"+flgHTTP)
+
+		webroot := firstListValue(params["webroot_path"])
+		if webroot == "" {
+			webroot = firstMapValue(renewal["webroot_map"])
+		}
+
+		if webroot != "" {
+			options = append(options, "--"+flgHTTPWebroot+"="+quoteArg(webroot))
+		}
+
+	case authenticator == "standalone":
+		if strings.Contains(params["pref_challs"], "tls-alpn-01") {
+			options = append(options, "--"+flgTLS)
+		} else {
+			options = append(options, "--"+flgHTTP)
+		}
+
+	case strings.HasPrefix(authenticator, "dns-"):
+		provider, ok := certbotDNSPlugins[authenticator]
+		if !ok {
+			provider = strings.TrimPrefix(authenticator, "dns-")
+		}
+
+		options = append(options, "--"+flgDNS+"="+provider)
+
+	case authenticator == "nginx" || authenticator == "apache":
+		log.Warnf("[%s] The certbot authenticator %q is not supported: the HTTP-01 challenge is used, the web server configuration must be adapted.", name, authenticator)
+
+		options = append(options, "--"+flgHTTP)
+
+	case authenticator != "":
+		log.Warnf("[%s] The certbot authenticator %q is not supported.", name, authenticator)
+	}
+
+	if keyType := certbotKeyType(params); keyType != "" {
+		options = append(options, "--"+flgKeyType+"="+keyType)
+	}
+
+	var renewOptions []string
+
+	if chain := params["preferred_chain"]; chain != "" {
+		renewOptions = append(renewOptions, "--"+flgPreferredChain+"="+quoteArg(chain))
+	}
+
+	if days, ok := certbotRenewBeforeExpiry(renewal[""]["renew_before_expiry"]); ok {
+		renewOptions = append(renewOptions, fmt.Sprintf("--%s=%d", flgDays, days))
+	}
+
+	return options, renewOptions
+}
+
+func certbotKeyType(params map[string]string) string {
+	switch params["key_type"] {
+	case "ecdsa":
+		switch params["elliptic_curve"] {
+		case "secp384r1":
+			return "ec384"
+		default:
+			return "ec256"
+		}
+
+	case "rsa":
+		size := params["rsa_key_size"]
+		if size == "" {
+			size = "2048"
+		}
+
+		return "rsa" + size
+
+	default:
+		if size := params["rsa_key_size"]; size != "" {
+			return "rsa" + size
+		}
+
+		return ""
+	}
+}
+
+// certbotRenewBeforeExpiry parses the renew_before_expiry option (e.g. `30 days`).
+func certbotRenewBeforeExpiry(value string) (int, bool) {
+	fields := strings.Fields(value)
+	if len(fields) != 2 || !strings.HasPrefix(fields[1], "day") {
+		return 0, false
+	}
+
+	days, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, false
+	}
+
+	return days, true
+}
+
+// readCertbotRenewalConf reads a renewal configuration file of certbot (ConfigObj format).
+// The options outside a section are stored in the section with an empty name.
+func readCertbotRenewalConf(filename string) (map[string]map[string]string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	conf := map[string]map[string]string{"": {}}
+	section := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			section = strings.Trim(line, "[]")
+			if _, ok := conf[section]; !ok {
+				conf[section] = map[string]string{}
+			}
+
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+
+		conf[section][strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	return conf, scanner.Err()
+}
+
+// firstListValue returns the first value of a ConfigObj list (e.g. `/var/www/html, /var/www/other,`).
+func firstListValue(value string) string {
+	first, _, _ := strings.Cut(value, ",")
+
+	return strings.TrimSpace(first)
+}
+
+// firstMapValue returns the value of the first key, in alphabetical order.
+func firstMapValue(values map[string]string) string {
+	var keys []string
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 {
+		return ""
+	}
+
+	sort.Strings(keys)
+
+	return values[keys[0]]
+}
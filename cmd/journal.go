@@ -0,0 +1,409 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/go-acme/lego/v4/log"
+	"github.com/go-acme/lego/v4/providers/dns"
+	"github.com/urfave/cli/v2"
+)
+
+const baseJournalFolderName = "journal"
+
+// journalValidationTimeout the maximum duration of the validation of a challenge by the ACME server,
+// after its propagation.
+// An entry of another host is considered stale after the propagation timeout of its provider and this duration.
+const journalValidationTimeout = 10 * time.Minute
+
+// JournalEntry a challenge presented by a provider and not yet cleaned up.
+type JournalEntry struct {
+	Type     challenge.Type `json:"type"`
+	Provider string         `json:"provider"`
+	Domain   string         `json:"domain"`
+	Token    string         `json:"token"`
+	KeyAuth  string         `json:"keyAuth"`
+
+	Hostname string    `json:"hostname"`
+	PID      int       `json:"pid"`
+	Date     time.Time `json:"date"`
+}
+
+func (e JournalEntry) key() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{e.Type.String(), e.Provider, e.Domain, e.Token}, "\n")))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// ChallengeJournal records the challenges presented by the providers,
+// to be able to clean them up if lego is stopped between Present and CleanUp.
+//
+// rootPath:
+//
+//	./.lego/journal/
+//	     │      └── one file per challenge presented and not yet cleaned up
+//	     └── "path" option
+type ChallengeJournal struct {
+	rootPath string
+}
+
+// NewChallengeJournal create a new challenge journal.
+func NewChallengeJournal(ctx *cli.Context) *ChallengeJournal {
+	return &ChallengeJournal{
+		rootPath: filepath.Join(ctx.String(flgPath), baseJournalFolderName),
+	}
+}
+
+// Add records an entry.
+// The entry is written to the disk before returning.
+func (j *ChallengeJournal) Add(entry JournalEntry) error {
+	err := createNonExistingFolder(j.rootPath)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(entry, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(j.rootPath, ".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = os.Remove(file.Name()) }()
+
+	_, err = file.Write(data)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	// The entry must be on the disk before presenting the challenge.
+	err = file.Sync()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), j.entryPath(entry))
+}
+
+// Remove removes an entry.
+func (j *ChallengeJournal) Remove(entry JournalEntry) error {
+	err := os.Remove(j.entryPath(entry))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// Entries returns all the entries, sorted by date.
+func (j *ChallengeJournal) Entries() ([]JournalEntry, error) {
+	matches, err := filepath.Glob(filepath.Join(j.rootPath, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []JournalEntry
+
+	for _, filename := range matches {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		var entry JournalEntry
+		err = json.Unmarshal(data, &entry)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, k int) bool {
+		return entries[i].Date.Before(entries[k].Date)
+	})
+
+	return entries, nil
+}
+
+func (j *ChallengeJournal) entryPath(entry JournalEntry) string {
+	return filepath.Join(j.rootPath, entry.key()+".json")
+}
+
+// Wrap wraps a provider to record the challenges inside the journal.
+// The optional interfaces of the provider used by the challenges are preserved.
+func (j *ChallengeJournal) Wrap(chlgType challenge.Type, name string, provider challenge.Provider) challenge.Provider {
+	p := &journalProvider{provider: provider, journal: j, chlgType: chlgType, name: name}
+
+	_, isSequential := provider.(sequentialProvider)
+	_, isBatch := provider.(dns01.BatchProvider)
+
+	switch {
+	case isSequential && isBatch:
+		return &journalSequentialBatchProvider{journalProvider: p}
+	case isSequential:
+		return &journalSequentialProvider{journalProvider: p}
+	case isBatch:
+		return &journalBatchProvider{journalProvider: p}
+	default:
+		return p
+	}
+}
+
+// Replay cleans up the challenges of the journal which are not handled by a running lego process.
+// The entries of other hosts (shared --path) are only cleaned up once they are older than the challenge timeout.
+// If force is true, all the entries are cleaned up,
+// and the entries are removed even if the cleanup fails.
+func (j *ChallengeJournal) Replay(ctx *cli.Context, force bool) error {
+	entries, err := j.Entries()
+	if err != nil {
+		return fmt.Errorf("journal: %w", err)
+	}
+
+	hostname, _ := os.Hostname()
+
+	providers := make(map[string]challenge.Provider)
+
+	var errAll error
+
+	for _, entry := range entries {
+		if entry.Hostname == hostname && entry.PID != os.Getpid() && processExists(entry.PID) {
+			log.Infof("[%s] journal: %s challenge is handled by the running process %d, skipping", entry.Domain, entry.Type, entry.PID)
+			continue
+		}
+
+		provider, err := j.getProvider(ctx, providers, entry)
+		if err != nil {
+			errAll = errors.Join(errAll, fmt.Errorf("[%s] journal: %s challenge: %w", entry.Domain, entry.Type, err))
+			continue
+		}
+
+		if entry.Hostname != hostname && !force && !isStaleEntry(entry, provider, time.Now().UTC()) {
+			log.Infof("[%s] journal: %s challenge may be handled by the host %s, skipping", entry.Domain, entry.Type, entry.Hostname)
+			continue
+		}
+
+		log.Infof("[%s] journal: cleaning up the stale %s challenge (%s, %s)", entry.Domain, entry.Type, entry.Provider, entry.Date.Format(time.RFC3339))
+
+		err = provider.CleanUp(entry.Domain, entry.Token, entry.KeyAuth)
+		if err != nil {
+			if !force {
+				errAll = errors.Join(errAll, fmt.Errorf("[%s] journal: %s challenge: %w", entry.Domain, entry.Type, err))
+				continue
+			}
+
+			log.Warnf("[%s] journal: %s challenge: cleaning up failed: %v", entry.Domain, entry.Type, err)
+		}
+
+		err = j.Remove(entry)
+		if err != nil {
+			errAll = errors.Join(errAll, fmt.Errorf("[%s] journal: %w", entry.Domain, err))
+		}
+	}
+
+	return errAll
+}
+
+func (j *ChallengeJournal) getProvider(ctx *cli.Context, providers map[string]challenge.Provider, entry JournalEntry) (challenge.Provider, error) {
+	key := entry.Type.String() + "/" + entry.Provider
+
+	provider, ok := providers[key]
+	if !ok {
+		var err error
+
+		provider, err = createJournalProvider(ctx, entry)
+		if err != nil {
+			return nil, err
+		}
+
+		providers[key] = provider
+	}
+
+	return provider, nil
+}
+
+// isStaleEntry returns true if the challenge of the entry is older than the challenge timeout
+// (the propagation timeout of the provider and the validation timeout).
+func isStaleEntry(entry JournalEntry, provider challenge.Provider, now time.Time) bool {
+	timeout := dns01.DefaultPropagationTimeout
+	if p, ok := provider.(challenge.ProviderTimeout); ok {
+		timeout, _ = p.Timeout()
+	}
+
+	return now.After(entry.Date.Add(timeout + journalValidationTimeout))
+}
+
+// createJournalProvider creates the provider used to clean up an entry of the journal.
+// The DNS providers are created by their names,
+// the HTTP providers are created from the options, and must match the provider of the entry.
+func createJournalProvider(ctx *cli.Context, entry JournalEntry) (challenge.Provider, error) {
+	switch entry.Type {
+	case challenge.DNS01:
+		return dns.NewDNSChallengeProviderByName(entry.Provider)
+
+	case challenge.HTTP01:
+		name := httpProviderName(ctx)
+		if name != entry.Provider {
+			return nil, fmt.Errorf("the HTTP provider %q is not configured", entry.Provider)
+		}
+
+		return setupHTTPProvider(ctx), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported challenge type: %s", entry.Type)
+	}
+}
+
+// httpProviderName returns the name of the HTTP provider defined by the options.
+// The built-in server is not named: it doesn't leave anything behind it.
+func httpProviderName(ctx *cli.Context) string {
+	switch {
+	case ctx.IsSet(flgHTTPWebroot):
+		return "webroot"
+	case ctx.IsSet(flgHTTPMemcachedHost):
+		return "memcached"
+	case ctx.IsSet(flgHTTPS3Bucket):
+		return "s3"
+	default:
+		return ""
+	}
+}
+
+type sequentialProvider interface {
+	Sequential() time.Duration
+}
+
+// journalProvider records the challenges inside the journal before presenting them,
+// and removes them from the journal after cleaning them up.
+type journalProvider struct {
+	provider challenge.Provider
+	journal  *ChallengeJournal
+	chlgType challenge.Type
+	name     string
+}
+
+func (p *journalProvider) Present(domain, token, keyAuth string) error {
+	err := p.journal.Add(p.entry(domain, token, keyAuth))
+	if err != nil {
+		return fmt.Errorf("journal: %w", err)
+	}
+
+	return p.provider.Present(domain, token, keyAuth)
+}
+
+func (p *journalProvider) CleanUp(domain, token, keyAuth string) error {
+	err := p.provider.CleanUp(domain, token, keyAuth)
+	if err != nil {
+		// the entry is kept to be able to retry later.
+		return err
+	}
+
+	return p.journal.Remove(p.entry(domain, token, keyAuth))
+}
+
+func (p *journalProvider) Timeout() (timeout, interval time.Duration) {
+	if provider, ok := p.provider.(challenge.ProviderTimeout); ok {
+		return provider.Timeout()
+	}
+
+	return dns01.DefaultPropagationTimeout, dns01.DefaultPollingInterval
+}
+
+func (p *journalProvider) entry(domain, token, keyAuth string) JournalEntry {
+	hostname, _ := os.Hostname()
+
+	return JournalEntry{
+		Type:     p.chlgType,
+		Provider: p.name,
+		Domain:   domain,
+		Token:    token,
+		KeyAuth:  keyAuth,
+		Hostname: hostname,
+		PID:      os.Getpid(),
+		Date:     time.Now().UTC(),
+	}
+}
+
+func (p *journalProvider) presentBatch(zone string, records []dns01.BatchRecord) error {
+	for _, record := range records {
+		err := p.journal.Add(p.entry(record.Domain, record.Token, record.KeyAuth))
+		if err != nil {
+			return fmt.Errorf("journal: %w", err)
+		}
+	}
+
+	return p.provider.(dns01.BatchProvider).PresentBatch(zone, records)
+}
+
+func (p *journalProvider) cleanUpBatch(zone string, records []dns01.BatchRecord) error {
+	err := p.provider.(dns01.BatchProvider).CleanUpBatch(zone, records)
+	if err != nil {
+		// the entries are kept to be able to retry later.
+		return err
+	}
+
+	for _, record := range records {
+		errR := p.journal.Remove(p.entry(record.Domain, record.Token, record.KeyAuth))
+		if errR != nil {
+			err = errors.Join(err, errR)
+		}
+	}
+
+	return err
+}
+
+type journalSequentialProvider struct {
+	*journalProvider
+}
+
+func (p *journalSequentialProvider) Sequential() time.Duration {
+	return p.provider.(sequentialProvider).Sequential()
+}
+
+type journalBatchProvider struct {
+	*journalProvider
+}
+
+func (p *journalBatchProvider) PresentBatch(zone string, records []dns01.BatchRecord) error {
+	return p.presentBatch(zone, records)
+}
+
+func (p *journalBatchProvider) CleanUpBatch(zone string, records []dns01.BatchRecord) error {
+	return p.cleanUpBatch(zone, records)
+}
+
+type journalSequentialBatchProvider struct {
+	*journalProvider
+}
+
+func (p *journalSequentialBatchProvider) Sequential() time.Duration {
+	return p.provider.(sequentialProvider).Sequential()
+}
+
+func (p *journalSequentialBatchProvider) PresentBatch(zone string, records []dns01.BatchRecord) error {
+	return p.presentBatch(zone, records)
+}
+
+func (p *journalSequentialBatchProvider) CleanUpBatch(zone string, records []dns01.BatchRecord) error {
+	return p.cleanUpBatch(zone, records)
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type journalProviderMock struct {
+	journal *ChallengeJournal
+
+	cleanUp error
+
+	// number of entries in the journal when Present is called.
+	presentEntries int
+}
+
+func (p *journalProviderMock) Present(_, _, _ string) error {
+	entries, err := p.journal.Entries()
+	if err != nil {
+		return err
+	}
+
+	p.presentEntries = len(entries)
+
+	return nil
+}
+
+func (p *journalProviderMock) CleanUp(_, _, _ string) error {
+	return p.cleanUp
+}
+
+type journalBatchProviderMock struct {
+	journalProviderMock
+}
+
+func (p *journalBatchProviderMock) PresentBatch(_ string, _ []dns01.BatchRecord) error {
+	return nil
+}
+
+func (p *journalBatchProviderMock) CleanUpBatch(_ string, _ []dns01.BatchRecord) error {
+	return nil
+}
+
+func TestChallengeJournal(t *testing.T) {
+	journal := &ChallengeJournal{rootPath: filepath.Join(t.TempDir(), baseJournalFolderName)}
+
+	entries, err := journal.Entries()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	entryA := JournalEntry{Type: challenge.DNS01, Provider: "exec", Domain: "example.com", Token: "a", Date: time.Unix(20, 0).UTC()}
+	entryB := JournalEntry{Type: challenge.HTTP01, Provider: "webroot", Domain: "example.org", Token: "b", Date: time.Unix(10, 0).UTC()}
+
+	require.NoError(t, journal.Add(entryA))
+	require.NoError(t, journal.Add(entryB))
+
+	// the same challenge is recorded once.
+	require.NoError(t, journal.Add(entryA))
+
+	entries, err = journal.Entries()
+	require.NoError(t, err)
+	assert.Equal(t, []JournalEntry{entryB, entryA}, entries)
+
+	require.NoError(t, journal.Remove(entryB))
+
+	// removing an unknown entry is not an error.
+	require.NoError(t, journal.Remove(entryB))
+
+	entries, err = journal.Entries()
+	require.NoError(t, err)
+	assert.Equal(t, []JournalEntry{entryA}, entries)
+
+	files, err := os.ReadDir(journal.rootPath)
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestChallengeJournal_Wrap(t *testing.T) {
+	journal := &ChallengeJournal{rootPath: t.TempDir()}
+
+	mock := &journalProviderMock{journal: journal}
+
+	provider := journal.Wrap(challenge.DNS01, "mock", mock)
+
+	require.NoError(t, provider.Present("example.com", "token", "keyAuth"))
+	assert.Equal(t, 1, mock.presentEntries)
+
+	// the entry is kept when the cleanup fails.
+	mock.cleanUp = errors.New("OOPS")
+	require.EqualError(t, provider.CleanUp("example.com", "token", "keyAuth"), "OOPS")
+
+	entries, err := journal.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	assert.Equal(t, challenge.DNS01, entries[0].Type)
+	assert.Equal(t, "mock", entries[0].Provider)
+	assert.Equal(t, "example.com", entries[0].Domain)
+	assert.Equal(t, "token", entries[0].Token)
+	assert.Equal(t, "keyAuth", entries[0].KeyAuth)
+	assert.Equal(t, os.Getpid(), entries[0].PID)
+
+	mock.cleanUp = nil
+	require.NoError(t, provider.CleanUp("example.com", "token", "keyAuth"))
+
+	entries, err = journal.Entries()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestChallengeJournal_Wrap_interfaces(t *testing.T) {
+	journal := &ChallengeJournal{rootPath: t.TempDir()}
+
+	provider := journal.Wrap(challenge.DNS01, "mock", &journalProviderMock{journal: journal})
+	assert.Implements(t, (*challenge.ProviderTimeout)(nil), provider)
+	assert.NotImplements(t, (*sequentialProvider)(nil), provider)
+	assert.NotImplements(t, (*dns01.BatchProvider)(nil), provider)
+
+	provider = journal.Wrap(challenge.DNS01, "mock", &journalBatchProviderMock{journalProviderMock{journal: journal}})
+	assert.NotImplements(t, (*sequentialProvider)(nil), provider)
+	assert.Implements(t, (*dns01.BatchProvider)(nil), provider)
+
+	batch := provider.(dns01.BatchProvider)
+
+	records := []dns01.BatchRecord{
+		{Domain: "example.com", Token: "a", KeyAuth: "a.key"},
+		{Domain: "www.example.com", Token: "b", KeyAuth: "b.key"},
+	}
+
+	require.NoError(t, batch.PresentBatch("example.com.", records))
+
+	entries, err := journal.Entries()
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	require.NoError(t, batch.CleanUpBatch("example.com.", records))
+
+	entries, err = journal.Entries()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestChallengeJournal_Replay(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test uses a shell script")
+	}
+
+	dir := t.TempDir()
+
+	calls := filepath.Join(dir, "calls")
+	script := filepath.Join(dir, "exec.sh")
+
+	err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" >> "+calls+"\n[ \"$3\" != \"failure.example.com\" ]\n"), 0o700)
+	require.NoError(t, err)
+
+	t.Setenv("EXEC_PATH", script)
+	t.Setenv("EXEC_MODE", "RAW")
+
+	journal := &ChallengeJournal{rootPath: filepath.Join(dir, baseJournalFolderName)}
+
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+
+	stale := JournalEntry{Type: challenge.DNS01, Provider: "exec", Domain: "example.com", Token: "a", KeyAuth: "a.key", Hostname: "other", PID: os.Getpid(), Date: time.Unix(10, 0).UTC()}
+	failure := JournalEntry{Type: challenge.DNS01, Provider: "exec", Domain: "failure.example.com", Token: "b", KeyAuth: "b.key", Hostname: "other", Date: time.Unix(20, 0).UTC()}
+	// the parent process (go test) is running.
+	running := JournalEntry{Type: challenge.DNS01, Provider: "exec", Domain: "running.example.com", Token: "c", KeyAuth: "c.key", Hostname: hostname, PID: os.Getppid(), Date: time.Unix(30, 0).UTC()}
+	// the challenge of another host is not yet timed out.
+	inProgress := JournalEntry{Type: challenge.DNS01, Provider: "exec", Domain: "progress.example.com", Token: "d", KeyAuth: "d.key", Hostname: "other", Date: time.Now().UTC()}
+
+	for _, entry := range []JournalEntry{stale, failure, running, inProgress} {
+		require.NoError(t, journal.Add(entry))
+	}
+
+	err = journal.Replay(nil, false)
+	require.EqualError(t, err, "[failure.example.com] journal: dns-01 challenge: exec: wait command: exit status 1")
+
+	entries, err := journal.Entries()
+	require.NoError(t, err)
+	assert.Equal(t, []JournalEntry{failure, running, inProgress}, entries)
+
+	err = journal.Replay(nil, true)
+	require.NoError(t, err)
+
+	entries, err = journal.Entries()
+	require.NoError(t, err)
+	assert.Equal(t, []JournalEntry{running}, entries)
+
+	data, err := os.ReadFile(calls)
+	require.NoError(t, err)
+
+	expected := "cleanup -- example.com a a.key\n" +
+		"cleanup -- failure.example.com b b.key\n" +
+		"cleanup -- failure.example.com b b.key\n" +
+		"cleanup -- progress.example.com d d.key\n"
+
+	assert.Equal(t, expected, string(data))
+}
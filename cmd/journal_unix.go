@@ -0,0 +1,20 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processExists reports whether a process with the given PID is running.
+func processExists(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+
+	err := syscall.Kill(pid, 0)
+
+	// EPERM: the process exists but belongs to another user.
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import "os"
+
+// processExists reports whether a process with the given PID is running.
+func processExists(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+
+	_ = process.Release()
+
+	return true
+}
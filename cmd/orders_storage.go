@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-acme/lego/v4/certificate"
+	"github.com/go-acme/lego/v4/log"
+	"github.com/urfave/cli/v2"
+)
+
+const baseOrdersFolderName = "orders"
+
+var _ certificate.OrderStore = (*OrdersStorage)(nil)
+
+// OrdersStorage a storage for the orders in progress,
+// used to resume an order after an interruption instead of creating a new order.
+//
+// rootPath:
+//
+//	./.lego/orders/localhost_14000/hubert@hubert.com/
+//	     │      │             │             └── one file per order in progress (named with the main domain)
+//	     │      │             └── userID ("email" option)
+//	     │      └── CA server ("server" option)
+//	     └── "path" option
+type OrdersStorage struct {
+	rootPath string
+}
+
+// NewOrdersStorage create a new storage for the orders of an account on an ACME server.
+func NewOrdersStorage(ctx *cli.Context, server, email string) *OrdersStorage {
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	serverPath := strings.NewReplacer(":", "_", "/", string(os.PathSeparator)).Replace(serverURL.Host)
+
+	return &OrdersStorage{
+		rootPath: filepath.Join(ctx.String(flgPath), baseOrdersFolderName, serverPath, email),
+	}
+}
+
+// Load returns the state of the order in progress for the domains, or nil if there is none.
+func (s *OrdersStorage) Load(domains []string) (*certificate.OrderState, error) {
+	data, err := os.ReadFile(s.filePath(domains))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	state := &certificate.OrderState{}
+
+	err = json.Unmarshal(data, state)
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// Save saves the state of an order.
+// The file contains the private key of the certificate.
+func (s *OrdersStorage) Save(state *certificate.OrderState) error {
+	err := createNonExistingFolder(s.rootPath)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(state, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.filePath(state.Domains), data, filePerm)
+}
+
+// Delete deletes the state of the order in progress for the domains.
+func (s *OrdersStorage) Delete(domains []string) error {
+	err := os.Remove(s.filePath(domains))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *OrdersStorage) filePath(domains []string) string {
+	return filepath.Join(s.rootPath, sanitizedDomain(domains[0])+".json")
+}
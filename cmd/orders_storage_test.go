@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/go-acme/lego/v4/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrdersStorage(t *testing.T) {
+	storage := &OrdersStorage{rootPath: t.TempDir()}
+
+	domains := []string{"*.example.com", "example.com"}
+
+	state, err := storage.Load(domains)
+	require.NoError(t, err)
+	assert.Nil(t, state)
+
+	expected := &certificate.OrderState{
+		Domains:    domains,
+		OrderURL:   "https://example.org/order/1",
+		PrivateKey: []byte("key"),
+		CSR:        []byte("csr"),
+	}
+
+	err = storage.Save(expected)
+	require.NoError(t, err)
+
+	assert.FileExists(t, storage.filePath(domains))
+
+	state, err = storage.Load(domains)
+	require.NoError(t, err)
+	assert.Equal(t, expected, state)
+
+	err = storage.Delete(domains)
+	require.NoError(t, err)
+
+	state, err = storage.Load(domains)
+	require.NoError(t, err)
+	assert.Nil(t, state)
+
+	// deleting a missing order is not an error.
+	err = storage.Delete(domains)
+	require.NoError(t, err)
+}
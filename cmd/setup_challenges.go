@@ -24,8 +24,21 @@ func setupChallenges(ctx *cli.Context, client *lego.Client) {
 		log.Fatalf("No challenge selected. You must specify at least one challenge: `--%s`, `--%s`, `--%s`.", flgHTTP, flgTLS, flgDNS)
 	}
 
+	journal := NewChallengeJournal(ctx)
+
+	// Cleans up the challenges left behind by an interrupted run.
+	err := journal.Replay(ctx, false)
+	if err != nil {
+		log.Warnf("Unable to clean up all the stale challenges, use the 'cleanup' command: %v", err)
+	}
+
 	if ctx.Bool(flgHTTP) {
-		err := client.Challenge.SetHTTP01Provider(setupHTTPProvider(ctx))
+		provider := setupHTTPProvider(ctx)
+		if name := httpProviderName(ctx); name != "" {
+			provider = journal.Wrap(challenge.HTTP01, name, provider)
+		}
+
+		err := client.Challenge.SetHTTP01Provider(provider)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -39,7 +52,7 @@ func setupChallenges(ctx *cli.Context, client *lego.Client) {
 	}
 
 	if ctx.IsSet(flgDNS) {
-		err := setupDNS(ctx, client)
+		err := setupDNS(ctx, client, journal)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -117,28 +130,95 @@ func setupTLSProvider(ctx *cli.Context) challenge.Provider {
 	}
 }
 
-func setupDNS(ctx *cli.Context, client *lego.Client) error {
-	err := checkPropagationExclusiveOptions(ctx)
+func setupDNS(ctx *cli.Context, client *lego.Client, journal *ChallengeJournal) error {
+	provider, opts, err := setupDNSProvider(ctx)
 	if err != nil {
 		return err
 	}
 
+	if !ctx.Bool(flgDNSDisableValidation) {
+		err = validateDNSProvider(provider)
+		if err != nil {
+			return err
+		}
+	}
+
+	return client.Challenge.SetDNS01Provider(journal.Wrap(challenge.DNS01, ctx.String(flgDNS), provider), opts...)
+}
+
+// validateDNSProvider checks the credentials and the access to the zones of the DNS provider,
+// if the provider implements challenge.ProviderValidator.
+func validateDNSProvider(provider challenge.Provider) error {
+	validator, ok := provider.(challenge.ProviderValidator)
+	if !ok {
+		return nil
+	}
+
+	err := validator.Validate()
+	if err != nil {
+		return fmt.Errorf("DNS provider validation: %w", err)
+	}
+
+	return nil
+}
+
+// setupDNSProvider creates the DNS provider and the options of the DNS-01 challenge.
+func setupDNSProvider(ctx *cli.Context) (challenge.Provider, []dns01.ChallengeOption, error) {
+	err := checkPropagationExclusiveOptions(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	wait := ctx.Duration(flgDNSPropagationWait)
 	if wait < 0 {
-		return fmt.Errorf("'%s' cannot be negative", flgDNSPropagationWait)
+		return nil, nil, fmt.Errorf("'%s' cannot be negative", flgDNSPropagationWait)
+	}
+
+	policy, err := dns01.ParsePropagationPolicy(ctx.String(flgDNSPropagationPolicy))
+	if err != nil {
+		return nil, nil, fmt.Errorf("'%s': %w", flgDNSPropagationPolicy, err)
 	}
 
 	provider, err := dns.NewDNSChallengeProviderByName(ctx.String(flgDNS))
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+
+	// The optional interfaces of the provider are hidden by the journal.
+	propagationProvider, isPropagationProvider := provider.(dns01.PropagationProvider)
+	if !isPropagationProvider && policy != dns01.PropagationPolicyDNS {
+		log.Warnf("The DNS provider %s cannot report the propagation of the TXT records, the '%s' flag is ignored.", ctx.String(flgDNS), flgDNSPropagationPolicy)
 	}
 
 	servers := ctx.StringSlice(flgDNSResolvers)
 
-	err = client.Challenge.SetDNS01Provider(provider,
+	rootCAs, err := lego.CreateCertPool(ctx.StringSlice(flgDNSResolversCA), true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("DNS resolvers CA: %w", err)
+	}
+
+	zoneOption, err := getZoneNameserversOption(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dnssecOption := dns01.ValidateDNSSEC(nil)
+	if ctx.IsSet(flgDNSDNSSECTrustAnchors) {
+		trustAnchors, errA := dns01.ReadTrustAnchors(ctx.String(flgDNSDNSSECTrustAnchors))
+		if errA != nil {
+			return nil, nil, fmt.Errorf("DNSSEC trust anchors: %w", errA)
+		}
+
+		dnssecOption = dns01.ValidateDNSSEC(trustAnchors)
+	}
+
+	opts := []dns01.ChallengeOption{
 		dns01.CondOption(len(servers) > 0,
 			dns01.AddRecursiveNameservers(dns01.ParseNameservers(ctx.StringSlice(flgDNSResolvers)))),
 
+		dns01.CondOption(rootCAs != nil,
+			dns01.AddDNSRootCAs(rootCAs)),
+
 		dns01.CondOption(ctx.Bool(flgDNSDisableCP) || ctx.Bool(flgDNSPropagationDisableANS),
 			dns01.DisableAuthoritativeNssPropagationRequirement()),
 
@@ -150,11 +230,74 @@ func setupDNS(ctx *cli.Context, client *lego.Client) error {
 		dns01.CondOption(ctx.Bool(flgDNSPropagationRNS),
 			dns01.RecursiveNSsPropagationRequirement()),
 
+		dns01.CondOption(isPropagationProvider,
+			dns01.SetPropagationProvider(propagationProvider)),
+
+		dns01.SetPropagationPolicy(policy),
+
 		dns01.CondOption(ctx.IsSet(flgDNSTimeout),
 			dns01.AddDNSTimeout(time.Duration(ctx.Int(flgDNSTimeout))*time.Second)),
-	)
 
-	return err
+		dns01.CondOption(ctx.Bool(flgDNSDNSSEC),
+			dnssecOption),
+
+		zoneOption,
+	}
+
+	return provider, opts, nil
+}
+
+// getZoneNameserversOption creates the option of the per-zone resolvers (split-horizon DNS).
+func getZoneNameserversOption(ctx *cli.Context) (dns01.ChallengeOption, error) {
+	discovery, err := parseZoneResolvers(ctx.StringSlice(flgDNSZoneResolvers))
+	if err != nil {
+		return nil, fmt.Errorf("'%s': %w", flgDNSZoneResolvers, err)
+	}
+
+	propagation, err := parseZoneResolvers(ctx.StringSlice(flgDNSZonePropagationRes))
+	if err != nil {
+		return nil, fmt.Errorf("'%s': %w", flgDNSZonePropagationRes, err)
+	}
+
+	var zones []string
+	for zone := range discovery {
+		zones = append(zones, zone)
+	}
+
+	for zone := range propagation {
+		if _, ok := discovery[zone]; !ok {
+			zones = append(zones, zone)
+		}
+	}
+
+	return func(chlg *dns01.Challenge) error {
+		for _, zone := range zones {
+			err := dns01.AddZoneNameservers(zone, discovery[zone], propagation[zone])(chlg)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, nil
+}
+
+// parseZoneResolvers parses a list of zone=resolver.
+func parseZoneResolvers(values []string) (map[string][]string, error) {
+	resolvers := make(map[string][]string)
+
+	for _, value := range values {
+		zone, resolver, ok := strings.Cut(value, "=")
+		if !ok || zone == "" || resolver == "" {
+			return nil, fmt.Errorf("invalid value %q, expected zone=resolver", value)
+		}
+
+		zone = dns01.ToFqdn(strings.ToLower(zone))
+
+		resolvers[zone] = append(resolvers[zone], resolver)
+	}
+
+	return resolvers, nil
 }
 
 func checkPropagationExclusiveOptions(ctx *cli.Context) error {
@@ -170,6 +313,10 @@ func checkPropagationExclusiveOptions(ctx *cli.Context) error {
 		return fmt.Errorf("'%s' and '%s' are mutually exclusive", flgDNSPropagationRNS, flgDNSPropagationWait)
 	}
 
+	if ctx.IsSet(flgDNSPropagationPolicy) && ctx.IsSet(flgDNSPropagationWait) {
+		return fmt.Errorf("'%s' and '%s' are mutually exclusive", flgDNSPropagationPolicy, flgDNSPropagationWait)
+	}
+
 	return nil
 }
 
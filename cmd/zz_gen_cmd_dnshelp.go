@@ -157,6 +157,7 @@ func allDNSCodes() string {
 		"yandex360",
 		"yandexcloud",
 		"zoneee",
+		"zonefile",
 		"zonomi",
 	}
 	sort.Strings(providers)
@@ -2490,7 +2491,6 @@ func displayDNSHelp(w io.Writer, name string) error {
 		ew.writeln()
 
 		ew.writeln(`Credentials:`)
-		ew.writeln(`	- "RFC2136_NAMESERVER":	Network address in the form "host" or "host:port"`)
 		ew.writeln(`	- "RFC2136_TSIG_ALGORITHM":	TSIG algorithm. See [miekg/dns#tsig.go](https://github.com/miekg/dns/blob/master/tsig.go) for supported values. To disable TSIG authentication, leave the 'RFC2136_TSIG_KEY' or 'RFC2136_TSIG_SECRET' variables unset.`)
 		ew.writeln(`	- "RFC2136_TSIG_KEY":	Name of the secret key as defined in DNS server configuration. To disable TSIG authentication, leave the 'RFC2136_TSIG_KEY' variable unset.`)
 		ew.writeln(`	- "RFC2136_TSIG_SECRET":	Secret key payload. To disable TSIG authentication, leave the 'RFC2136_TSIG_SECRET' variable unset.`)
@@ -2498,9 +2498,11 @@ func displayDNSHelp(w io.Writer, name string) error {
 
 		ew.writeln(`Additional Configuration:`)
 		ew.writeln(`	- "RFC2136_DNS_TIMEOUT":	API request timeout in seconds (Default: 10)`)
+		ew.writeln(`	- "RFC2136_NAMESERVER":	Network address in the form "host" or "host:port". If not set, the updates are sent to the primary nameserver of the zone (SOA MNAME).`)
 		ew.writeln(`	- "RFC2136_POLLING_INTERVAL":	Time between DNS propagation check in seconds (Default: 2)`)
 		ew.writeln(`	- "RFC2136_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation in seconds (Default: 60)`)
 		ew.writeln(`	- "RFC2136_SEQUENCE_INTERVAL":	Time between sequential requests in seconds (Default: 60)`)
+		ew.writeln(`	- "RFC2136_SIG0_FILE":	Path to a SIG(0) key file generated by 'dnssec-keygen -T KEY' (the '.key' file, the '.private' file must be in the same directory). Cannot be used with TSIG.`)
 		ew.writeln(`	- "RFC2136_TSIG_FILE":	Path to a key file generated by tsig-keygen`)
 		ew.writeln(`	- "RFC2136_TTL":	The TTL of the TXT record used for the DNS challenge in seconds (Default: 120)`)
 
@@ -3256,6 +3258,27 @@ func displayDNSHelp(w io.Writer, name string) error {
 		ew.writeln()
 		ew.writeln(`More information: https://go-acme.github.io/lego/dns/zoneee`)
 
+	case "zonefile":
+		// generated from: providers/dns/zonefile/zonefile.toml
+		ew.writeln(`Configuration for Zone file.`)
+		ew.writeln(`Code:	'zonefile'`)
+		ew.writeln(`Since:	'v4.22.0'`)
+		ew.writeln()
+
+		ew.writeln(`Credentials:`)
+		ew.writeln(`	- "ZONEFILE_PATH":	Path of the zone file`)
+		ew.writeln()
+
+		ew.writeln(`Additional Configuration:`)
+		ew.writeln(`	- "ZONEFILE_POLLING_INTERVAL":	Time between DNS propagation check in seconds (Default: 2)`)
+		ew.writeln(`	- "ZONEFILE_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation in seconds (Default: 60)`)
+		ew.writeln(`	- "ZONEFILE_RELOAD_COMMAND":	The command run after each change of the zone file (e.g. 'rndc reload example.com', 'nsd-control reload example.com', 'knotc zone-reload example.com')`)
+		ew.writeln(`	- "ZONEFILE_TTL":	The TTL of the TXT record used for the DNS challenge in seconds (Default: 120)`)
+		ew.writeln(`	- "ZONEFILE_ZONE":	The origin of the zone file (Default: determined by using the recursive nameservers)`)
+
+		ew.writeln()
+		ew.writeln(`More information: https://go-acme.github.io/lego/dns/zonefile`)
+
 	case "zonomi":
 		// generated from: providers/dns/zonomi/zonomi.toml
 		ew.writeln(`Configuration for Zonomi.`)
@@ -56,6 +56,12 @@ func generate() error {
 		{"lego", "help", "renew"},
 		{"lego", "help", "revoke"},
 		{"lego", "help", "list"},
+		{"lego", "help", "check"},
+		{"lego", "help", "cleanup"},
+		{"lego", "help", "preauthorize"},
+		{"lego", "help", "profiles"},
+		{"lego", "help", "accounts"},
+		{"lego", "help", "import"},
 		{"lego", "dnshelp"},
 	} {
 		content, err := run(app, args)
@@ -50,10 +50,17 @@ func NewClient(config *Config) (*Client, error) {
 		return nil, err
 	}
 
+	core.SetRetryPolicy(config.Retry)
+
 	solversManager := resolver.NewSolversManager(core)
 
 	prober := resolver.NewProber(solversManager)
-	certifier := certificate.NewCertifier(core, prober, certificate.CertifierOptions{KeyType: config.Certificate.KeyType, Timeout: config.Certificate.Timeout, OverallRequestLimit: config.Certificate.OverallRequestLimit})
+	certifier := certificate.NewCertifier(core, prober, certificate.CertifierOptions{
+		KeyType:             config.Certificate.KeyType,
+		Timeout:             config.Certificate.Timeout,
+		OverallRequestLimit: config.Certificate.OverallRequestLimit,
+		OrderStore:          config.Certificate.OrderStore,
+	})
 
 	return &Client{
 		Certificate:  certifier,
@@ -72,3 +79,9 @@ func (c *Client) GetToSURL() string {
 func (c *Client) GetExternalAccountRequired() bool {
 	return c.core.GetDirectory().Meta.ExternalAccountRequired
 }
+
+// GetProfiles returns the certificate profiles offered by the ACME server (names and descriptions).
+// - https://www.ietf.org/id/draft-aaron-acme-profiles-00.html#section-3
+func (c *Client) GetProfiles() map[string]string {
+	return c.core.GetDirectory().Meta.Profiles
+}
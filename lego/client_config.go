@@ -11,7 +11,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/go-acme/lego/v4/acme/api"
 	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/certificate"
 	"github.com/go-acme/lego/v4/registration"
 )
 
@@ -46,6 +48,12 @@ type Config struct {
 	UserAgent   string
 	HTTPClient  *http.Client
 	Certificate CertificateConfig
+
+	// Retry the policy used to retry the requests to the ACME server
+	// on transient failures (5xx responses, network errors) and when the requests are rate limited.
+	// The transient failures are only retried for the idempotent requests.
+	// The zero value disables the retries.
+	Retry api.RetryPolicy
 }
 
 func NewConfig(user registration.User) *Config {
@@ -64,6 +72,9 @@ type CertificateConfig struct {
 	KeyType             certcrypto.KeyType
 	Timeout             time.Duration
 	OverallRequestLimit int
+
+	// OrderStore persists the orders in progress to resume them after an interruption (optional).
+	OrderStore certificate.OrderStore
 }
 
 // createDefaultHTTPClient Creates an HTTP client with a reasonable timeout value
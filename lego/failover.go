@@ -0,0 +1,51 @@
+package lego
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/go-acme/lego/v4/acme"
+)
+
+// FailoverCondition reports whether an error returned by a CA allows to use the next CA.
+type FailoverCondition func(err error) bool
+
+// FailoverOnRateLimit matches the rate limit errors (`urn:ietf:params:acme:error:rateLimited`).
+func FailoverOnRateLimit(err error) bool {
+	var rateLimitErr *acme.RateLimitError
+
+	return errors.As(err, &rateLimitErr)
+}
+
+// FailoverOnServerError matches the server errors (5xx, `urn:ietf:params:acme:error:serverInternal`) and the network errors.
+func FailoverOnServerError(err error) bool {
+	var problem *acme.ProblemDetails
+	if errors.As(err, &problem) {
+		return problem.HTTPStatus >= http.StatusInternalServerError || problem.Type == acme.ServerInternalErr
+	}
+
+	var urlErr *url.Error
+
+	return errors.As(err, &urlErr)
+}
+
+// FailoverOnAnyError matches all the errors.
+func FailoverOnAnyError(err error) bool {
+	return err != nil
+}
+
+// ShouldFailover returns true if the error matches one of the conditions.
+func ShouldFailover(err error, conditions ...FailoverCondition) bool {
+	if err == nil {
+		return false
+	}
+
+	for _, condition := range conditions {
+		if condition(err) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,95 @@
+package lego
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldFailover(t *testing.T) {
+	rateLimitErr := &acme.RateLimitError{
+		ProblemDetails: &acme.ProblemDetails{Type: acme.RateLimitedErr, HTTPStatus: 429},
+	}
+
+	serverErr := &acme.ProblemDetails{Type: acme.ServerInternalErr, HTTPStatus: 500}
+
+	unavailableErr := &acme.ProblemDetails{HTTPStatus: 503}
+
+	networkErr := &url.Error{Op: "Post", URL: "https://example.com", Err: errors.New("connection refused")}
+
+	validationErr := &acme.ProblemDetails{Type: "urn:ietf:params:acme:error:unauthorized", HTTPStatus: 403}
+
+	testCases := []struct {
+		desc       string
+		err        error
+		conditions []FailoverCondition
+		expected   bool
+	}{
+		{
+			desc:       "no error",
+			conditions: []FailoverCondition{FailoverOnAnyError},
+		},
+		{
+			desc: "no conditions",
+			err:  rateLimitErr,
+		},
+		{
+			desc:       "rate limit",
+			err:        rateLimitErr,
+			conditions: []FailoverCondition{FailoverOnRateLimit},
+			expected:   true,
+		},
+		{
+			desc:       "wrapped rate limit",
+			err:        fmt.Errorf("error: one or more domains had a problem:\n%w", errors.Join(fmt.Errorf("example.com: %w", rateLimitErr))),
+			conditions: []FailoverCondition{FailoverOnRateLimit},
+			expected:   true,
+		},
+		{
+			desc:       "rate limit: server error condition",
+			err:        rateLimitErr,
+			conditions: []FailoverCondition{FailoverOnServerError},
+		},
+		{
+			desc:       "server error",
+			err:        serverErr,
+			conditions: []FailoverCondition{FailoverOnRateLimit, FailoverOnServerError},
+			expected:   true,
+		},
+		{
+			desc:       "service unavailable",
+			err:        unavailableErr,
+			conditions: []FailoverCondition{FailoverOnServerError},
+			expected:   true,
+		},
+		{
+			desc:       "network error",
+			err:        networkErr,
+			conditions: []FailoverCondition{FailoverOnServerError},
+			expected:   true,
+		},
+		{
+			desc:       "validation error",
+			err:        validationErr,
+			conditions: []FailoverCondition{FailoverOnRateLimit, FailoverOnServerError},
+		},
+		{
+			desc:       "validation error: any error",
+			err:        validationErr,
+			conditions: []FailoverCondition{FailoverOnAnyError},
+			expected:   true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, ShouldFailover(test.err, test.conditions...))
+		})
+	}
+}
@@ -45,6 +45,10 @@ const (
 
 var _ challenge.ProviderTimeout = (*DNSProvider)(nil)
 
+var _ challenge.ProviderValidator = (*DNSProvider)(nil)
+
+var _ dns01.BatchProvider = (*DNSProvider)(nil)
+
 // Config is used to configure the creation of the DNSProvider.
 type Config struct {
 	AuthEmail string
@@ -146,6 +150,16 @@ func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
 	return d.config.PropagationTimeout, d.config.PollingInterval
 }
 
+// Validate checks the credentials and the access to the zones.
+func (d *DNSProvider) Validate() error {
+	err := d.client.Validate(context.Background())
+	if err != nil {
+		return fmt.Errorf("cloudflare: %w", err)
+	}
+
+	return nil
+}
+
 // Present creates a TXT record to fulfill the dns-01 challenge.
 func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 	info := dns01.GetChallengeInfo(domain, keyAuth)
@@ -216,6 +230,86 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 	return nil
 }
 
+// PresentBatch creates the TXT records of several challenges of a zone with a single batch request.
+func (d *DNSProvider) PresentBatch(zone string, records []dns01.BatchRecord) error {
+	zoneID, err := d.client.ZoneIDByName(zone)
+	if err != nil {
+		return fmt.Errorf("cloudflare: failed to find zone %s: %w", zone, err)
+	}
+
+	var batch batchRequest
+	for _, record := range records {
+		batch.Posts = append(batch.Posts, batchRecord{
+			Type:    "TXT",
+			Name:    dns01.UnFqdn(record.Info.EffectiveFQDN),
+			Content: record.Info.Value,
+			TTL:     d.config.TTL,
+		})
+	}
+
+	result, err := d.client.BatchDNSRecords(context.Background(), zoneID, batch)
+	if err != nil {
+		return fmt.Errorf("cloudflare: failed to create TXT records: %w", err)
+	}
+
+	if len(result.Posts) != len(records) {
+		return fmt.Errorf("cloudflare: unexpected number of created records: %d, expected %d", len(result.Posts), len(records))
+	}
+
+	d.recordIDsMu.Lock()
+	for i, record := range records {
+		d.recordIDs[record.Token] = result.Posts[i].ID
+	}
+	d.recordIDsMu.Unlock()
+
+	log.Infof("cloudflare: %d new records for the zone %s", len(records), zone)
+
+	return nil
+}
+
+// CleanUpBatch removes the TXT records of several challenges of a zone with a single batch request.
+func (d *DNSProvider) CleanUpBatch(zone string, records []dns01.BatchRecord) error {
+	zoneID, err := d.client.ZoneIDByName(zone)
+	if err != nil {
+		return fmt.Errorf("cloudflare: failed to find zone %s: %w", zone, err)
+	}
+
+	var batch batchRequest
+	var unknown []string
+
+	d.recordIDsMu.Lock()
+	for _, record := range records {
+		recordID, ok := d.recordIDs[record.Token]
+		if !ok {
+			unknown = append(unknown, record.Info.EffectiveFQDN)
+			continue
+		}
+
+		batch.Deletes = append(batch.Deletes, batchRecord{ID: recordID})
+	}
+	d.recordIDsMu.Unlock()
+
+	if len(batch.Deletes) > 0 {
+		_, err = d.client.BatchDNSRecords(context.Background(), zoneID, batch)
+		if err != nil {
+			log.Printf("cloudflare: failed to delete TXT records: %v", err)
+		}
+	}
+
+	// Delete record IDs from map
+	d.recordIDsMu.Lock()
+	for _, record := range records {
+		delete(d.recordIDs, record.Token)
+	}
+	d.recordIDsMu.Unlock()
+
+	if len(unknown) > 0 {
+		return fmt.Errorf("cloudflare: unknown record ID for '%s'", strings.Join(unknown, "', '"))
+	}
+
+	return nil
+}
+
 func altEnvName(v string) string {
 	return strings.ReplaceAll(v, envNamespace, altEnvNamespace)
 }
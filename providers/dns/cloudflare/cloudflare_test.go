@@ -1,9 +1,16 @@
 package cloudflare
 
 import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/go-acme/lego/v4/challenge/dns01"
 	"github.com/go-acme/lego/v4/platform/tester"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -271,6 +278,89 @@ func TestNewDNSProviderConfig(t *testing.T) {
 	}
 }
 
+func setupBatchTest(t *testing.T, result string) (*DNSProvider, *[]string) {
+	t.Helper()
+
+	var requests []string
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/zones/023e105f4ecef8ad9ca31a8372d0c353/dns_records/batch", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		requests = append(requests, string(body))
+
+		_, _ = fmt.Fprintf(rw, `{"success":true,"errors":[],"messages":[],"result":%s}`, result)
+	})
+
+	client, err := cloudflare.NewWithAPIToken("secret", cloudflare.BaseURL(server.URL))
+	require.NoError(t, err)
+
+	config := NewDefaultConfig()
+	config.TTL = 120
+
+	provider := &DNSProvider{
+		config: config,
+		client: &metaClient{
+			clientEdit: client,
+			clientRead: client,
+			zones:      map[string]string{"example.com.": "023e105f4ecef8ad9ca31a8372d0c353"},
+			zonesMu:    &sync.RWMutex{},
+		},
+		recordIDs: make(map[string]string),
+	}
+
+	return provider, &requests
+}
+
+func TestDNSProvider_PresentBatch(t *testing.T) {
+	provider, requests := setupBatchTest(t, `{"posts":[{"id":"id-a","type":"TXT"},{"id":"id-b","type":"TXT"}]}`)
+
+	records := []dns01.BatchRecord{
+		{Domain: "example.com", Token: "a", Info: dns01.ChallengeInfo{EffectiveFQDN: "_acme-challenge.example.com.", Value: "value-a"}},
+		{Domain: "www.example.com", Token: "b", Info: dns01.ChallengeInfo{EffectiveFQDN: "_acme-challenge.www.example.com.", Value: "value-b"}},
+	}
+
+	err := provider.PresentBatch("example.com.", records)
+	require.NoError(t, err)
+
+	expected := `{"posts":[{"type":"TXT","name":"_acme-challenge.example.com","content":"value-a","ttl":120},` +
+		`{"type":"TXT","name":"_acme-challenge.www.example.com","content":"value-b","ttl":120}]}`
+
+	assert.Equal(t, []string{expected}, *requests)
+	assert.Equal(t, map[string]string{"a": "id-a", "b": "id-b"}, provider.recordIDs)
+}
+
+func TestDNSProvider_CleanUpBatch(t *testing.T) {
+	provider, requests := setupBatchTest(t, `{"deletes":[{"id":"id-a","type":"TXT"},{"id":"id-b","type":"TXT"}]}`)
+
+	provider.recordIDs["a"] = "id-a"
+	provider.recordIDs["b"] = "id-b"
+
+	records := []dns01.BatchRecord{
+		{Domain: "example.com", Token: "a", Info: dns01.ChallengeInfo{EffectiveFQDN: "_acme-challenge.example.com.", Value: "value-a"}},
+		{Domain: "www.example.com", Token: "b", Info: dns01.ChallengeInfo{EffectiveFQDN: "_acme-challenge.www.example.com.", Value: "value-b"}},
+		{Domain: "api.example.com", Token: "c", Info: dns01.ChallengeInfo{EffectiveFQDN: "_acme-challenge.api.example.com.", Value: "value-c"}},
+	}
+
+	err := provider.CleanUpBatch("example.com.", records)
+	require.EqualError(t, err, "cloudflare: unknown record ID for '_acme-challenge.api.example.com.'")
+
+	assert.Equal(t, []string{`{"deletes":[{"id":"id-a"},{"id":"id-b"}]}`}, *requests)
+	assert.Empty(t, provider.recordIDs)
+}
+
 func TestLivePresent(t *testing.T) {
 	if !envTest.IsLiveTest() {
 		t.Skip("skipping live test")
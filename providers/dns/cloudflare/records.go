@@ -0,0 +1,199 @@
+package cloudflare
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/go-acme/lego/v4/providers/dns/records"
+	"github.com/miekg/dns"
+)
+
+var _ records.Provider = (*DNSProvider)(nil)
+
+// ListRecords returns the TXT, CAA, TLSA and CNAME records of the zone.
+func (d *DNSProvider) ListRecords(zone string) ([]records.Record, error) {
+	zoneID, err := d.client.ZoneIDByName(dns01.ToFqdn(zone))
+	if err != nil {
+		return nil, fmt.Errorf("cloudflare: failed to find zone %s: %w", zone, err)
+	}
+
+	dnsRecords, _, err := d.client.DNSRecords(context.Background(), zoneID, cloudflare.ListDNSRecordsParams{})
+	if err != nil {
+		return nil, fmt.Errorf("cloudflare: failed to list records: %w", err)
+	}
+
+	var result []records.Record
+
+	for _, dnsRecord := range dnsRecords {
+		if !records.IsSupportedType(dnsRecord.Type) {
+			continue
+		}
+
+		record, err := fromCloudflareRecord(dnsRecord)
+		if err != nil {
+			return nil, fmt.Errorf("cloudflare: %w", err)
+		}
+
+		result = append(result, record)
+	}
+
+	return result, nil
+}
+
+// UpsertRecord creates the record, or updates its TTL if the record already exists.
+func (d *DNSProvider) UpsertRecord(zone string, record records.Record) error {
+	params, err := toCloudflareRecord(record)
+	if err != nil {
+		return fmt.Errorf("cloudflare: %w", err)
+	}
+
+	zoneID, existing, err := d.findRecords(zone, record)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	if len(existing) == 0 {
+		_, err = d.client.CreateDNSRecord(ctx, zoneID, params)
+		if err != nil {
+			return fmt.Errorf("cloudflare: failed to create %s record: %w", record.Type, err)
+		}
+
+		return nil
+	}
+
+	if existing[0].TTL == record.TTL {
+		return nil
+	}
+
+	_, err = d.client.UpdateDNSRecord(ctx, zoneID, cloudflare.UpdateDNSRecordParams{
+		ID:      existing[0].ID,
+		Type:    params.Type,
+		Name:    params.Name,
+		Content: params.Content,
+		Data:    params.Data,
+		TTL:     params.TTL,
+	})
+	if err != nil {
+		return fmt.Errorf("cloudflare: failed to update %s record: %w", record.Type, err)
+	}
+
+	return nil
+}
+
+// DeleteRecord deletes the record.
+func (d *DNSProvider) DeleteRecord(zone string, record records.Record) error {
+	zoneID, existing, err := d.findRecords(zone, record)
+	if err != nil {
+		return err
+	}
+
+	for _, dnsRecord := range existing {
+		err = d.client.DeleteDNSRecord(context.Background(), zoneID, dnsRecord.ID)
+		if err != nil {
+			return fmt.Errorf("cloudflare: failed to delete %s record: %w", record.Type, err)
+		}
+	}
+
+	return nil
+}
+
+// findRecords returns the zone ID, and the Cloudflare records matching the record.
+func (d *DNSProvider) findRecords(zone string, record records.Record) (string, []cloudflare.DNSRecord, error) {
+	zoneID, err := d.client.ZoneIDByName(dns01.ToFqdn(zone))
+	if err != nil {
+		return "", nil, fmt.Errorf("cloudflare: failed to find zone %s: %w", zone, err)
+	}
+
+	params := cloudflare.ListDNSRecordsParams{
+		Type: strings.ToUpper(record.Type),
+		Name: dns01.UnFqdn(record.FQDN),
+	}
+
+	dnsRecords, _, err := d.client.DNSRecords(context.Background(), zoneID, params)
+	if err != nil {
+		return "", nil, fmt.Errorf("cloudflare: failed to list records: %w", err)
+	}
+
+	var matches []cloudflare.DNSRecord
+
+	for _, dnsRecord := range dnsRecords {
+		existing, err := fromCloudflareRecord(dnsRecord)
+		if err != nil {
+			return "", nil, fmt.Errorf("cloudflare: %w", err)
+		}
+
+		if records.Same(record, existing) {
+			matches = append(matches, dnsRecord)
+		}
+	}
+
+	return zoneID, matches, nil
+}
+
+// toCloudflareRecord converts a record to the parameters of the Cloudflare API.
+// The CAA and TLSA records are defined with structured data.
+func toCloudflareRecord(record records.Record) (cloudflare.CreateDNSRecordParams, error) {
+	rr, err := record.RR()
+	if err != nil {
+		return cloudflare.CreateDNSRecordParams{}, err
+	}
+
+	params := cloudflare.CreateDNSRecordParams{
+		Type: dns.TypeToString[rr.Header().Rrtype],
+		Name: dns01.UnFqdn(rr.Header().Name),
+		TTL:  record.TTL,
+	}
+
+	switch v := rr.(type) {
+	case *dns.TXT:
+		params.Content = strings.Join(v.Txt, "")
+	case *dns.CNAME:
+		params.Content = dns01.UnFqdn(v.Target)
+	case *dns.CAA:
+		params.Data = map[string]any{"flags": v.Flag, "tag": v.Tag, "value": v.Value}
+	case *dns.TLSA:
+		params.Data = map[string]any{
+			"usage":         v.Usage,
+			"selector":      v.Selector,
+			"matching_type": v.MatchingType,
+			"certificate":   v.Certificate,
+		}
+	}
+
+	return params, nil
+}
+
+// fromCloudflareRecord converts a Cloudflare record to a record.
+func fromCloudflareRecord(dnsRecord cloudflare.DNSRecord) (records.Record, error) {
+	fqdn := dns01.ToFqdn(dnsRecord.Name)
+
+	switch dnsRecord.Type {
+	case records.TypeTXT:
+		if strings.HasPrefix(dnsRecord.Content, `"`) {
+			return records.Record{FQDN: fqdn, Type: records.TypeTXT, Value: dnsRecord.Content, TTL: dnsRecord.TTL}.Normalize()
+		}
+
+		return records.NewTXT(fqdn, dnsRecord.Content, dnsRecord.TTL), nil
+
+	case records.TypeCNAME:
+		return records.NewCNAME(fqdn, dnsRecord.Content, dnsRecord.TTL), nil
+	}
+
+	value := dnsRecord.Content
+
+	if data, ok := dnsRecord.Data.(map[string]any); ok {
+		switch dnsRecord.Type {
+		case records.TypeCAA:
+			value = fmt.Sprintf("%v %v %q", data["flags"], data["tag"], data["value"])
+		case records.TypeTLSA:
+			value = fmt.Sprintf("%v %v %v %v", data["usage"], data["selector"], data["matching_type"], data["certificate"])
+		}
+	}
+
+	return records.Record{FQDN: fqdn, Type: dnsRecord.Type, Value: value, TTL: dnsRecord.TTL}.Normalize()
+}
@@ -0,0 +1,170 @@
+package cloudflare
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/go-acme/lego/v4/providers/dns/records"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRecords = `[
+	{"id":"id-txt","type":"TXT","name":"example.com","content":"\"v=spf1 -all\"","ttl":300},
+	{"id":"id-caa","type":"CAA","name":"example.com","content":"0 issue letsencrypt.org","data":{"flags":0,"tag":"issue","value":"letsencrypt.org"},"ttl":3600},
+	{"id":"id-tlsa","type":"TLSA","name":"_443._tcp.example.com","content":"3 1 1 ABCDEF","data":{"usage":3,"selector":1,"matching_type":1,"certificate":"ABCDEF"},"ttl":3600},
+	{"id":"id-cname","type":"CNAME","name":"www.example.com","content":"example.com","ttl":1},
+	{"id":"id-a","type":"A","name":"example.com","content":"192.0.2.1","ttl":1}
+]`
+
+func setupRecordsTest(t *testing.T) (*DNSProvider, *[]string) {
+	t.Helper()
+
+	var requests []string
+
+	var all []cloudflare.DNSRecord
+	require.NoError(t, json.Unmarshal([]byte(testRecords), &all))
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/zones/023e105f4ecef8ad9ca31a8372d0c353/dns_records", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost {
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				http.Error(rw, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			requests = append(requests, "POST "+string(body))
+
+			_, _ = fmt.Fprint(rw, `{"success":true,"errors":[],"messages":[],"result":{"id":"id-new"}}`)
+
+			return
+		}
+
+		var result []cloudflare.DNSRecord
+
+		for _, record := range all {
+			if req.URL.Query().Get("type") != "" && req.URL.Query().Get("type") != record.Type {
+				continue
+			}
+
+			if req.URL.Query().Get("name") != "" && req.URL.Query().Get("name") != record.Name {
+				continue
+			}
+
+			result = append(result, record)
+		}
+
+		data, err := json.Marshal(result)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		_, _ = fmt.Fprintf(rw, `{"success":true,"errors":[],"messages":[],"result":%s,"result_info":{"page":1,"per_page":100,"count":%d,"total_count":%d,"total_pages":1}}`,
+			data, len(result), len(result))
+	})
+
+	mux.HandleFunc("/zones/023e105f4ecef8ad9ca31a8372d0c353/dns_records/{id}", func(rw http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		requests = append(requests, req.Method+" "+req.PathValue("id")+" "+string(body))
+
+		_, _ = fmt.Fprintf(rw, `{"success":true,"errors":[],"messages":[],"result":{"id":%q}}`, req.PathValue("id"))
+	})
+
+	client, err := cloudflare.NewWithAPIToken("secret", cloudflare.BaseURL(server.URL))
+	require.NoError(t, err)
+
+	provider := &DNSProvider{
+		config: NewDefaultConfig(),
+		client: &metaClient{
+			clientEdit: client,
+			clientRead: client,
+			zones:      map[string]string{"example.com.": "023e105f4ecef8ad9ca31a8372d0c353"},
+			zonesMu:    &sync.RWMutex{},
+		},
+		recordIDs: make(map[string]string),
+	}
+
+	return provider, &requests
+}
+
+func TestDNSProvider_ListRecords(t *testing.T) {
+	provider, _ := setupRecordsTest(t)
+
+	result, err := provider.ListRecords("example.com.")
+	require.NoError(t, err)
+
+	expected := []records.Record{
+		{FQDN: "example.com.", Type: records.TypeTXT, Value: `"v=spf1 -all"`, TTL: 300},
+		{FQDN: "example.com.", Type: records.TypeCAA, Value: `0 issue "letsencrypt.org"`, TTL: 3600},
+		{FQDN: "_443._tcp.example.com.", Type: records.TypeTLSA, Value: "3 1 1 abcdef", TTL: 3600},
+		{FQDN: "www.example.com.", Type: records.TypeCNAME, Value: "example.com.", TTL: 1},
+	}
+
+	assert.Equal(t, expected, result)
+}
+
+func TestDNSProvider_UpsertRecord(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		record   records.Record
+		expected []string
+	}{
+		{
+			desc:     "new record",
+			record:   records.NewCAA("example.com.", 0, "issuewild", ";", 3600),
+			expected: []string{`POST {"created_on":"0001-01-01T00:00:00Z","modified_on":"0001-01-01T00:00:00Z","type":"CAA","name":"example.com","data":{"flags":0,"tag":"issuewild","value":";"},"ttl":3600}`},
+		},
+		{
+			desc:     "existing record with the same TTL",
+			record:   records.Record{FQDN: "_443._tcp.example.com.", Type: records.TypeTLSA, Value: "3 1 1 abcdef", TTL: 3600},
+			expected: nil,
+		},
+		{
+			desc:   "existing record with another TTL",
+			record: records.NewTXT("example.com.", "v=spf1 -all", 600),
+			expected: []string{
+				`PATCH id-txt {"type":"TXT","name":"example.com","content":"v=spf1 -all","ttl":600,"tags":null}`,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			provider, requests := setupRecordsTest(t)
+
+			err := provider.UpsertRecord("example.com.", test.record)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, *requests)
+		})
+	}
+}
+
+func TestDNSProvider_DeleteRecord(t *testing.T) {
+	provider, requests := setupRecordsTest(t)
+
+	err := provider.DeleteRecord("example.com.", records.NewCAA("example.com.", 0, "issue", "letsencrypt.org", 3600))
+	require.NoError(t, err)
+
+	// Deleting a record that doesn't exist is not an error.
+	err = provider.DeleteRecord("example.com.", records.NewCAA("example.com.", 0, "issue", "example.org", 3600))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"DELETE id-caa "}, *requests)
+}
@@ -2,12 +2,37 @@ package cloudflare
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
 	"sync"
 
 	"github.com/cloudflare/cloudflare-go"
 	"github.com/go-acme/lego/v4/challenge/dns01"
 )
 
+// batchRequest the changes of a batch request.
+// https://developers.cloudflare.com/api/resources/dns/subresources/records/methods/batch/
+type batchRequest struct {
+	Deletes []batchRecord `json:"deletes,omitempty"`
+	Posts   []batchRecord `json:"posts,omitempty"`
+}
+
+// batchResult the result of a batch request.
+type batchResult struct {
+	Deletes []cloudflare.DNSRecord `json:"deletes,omitempty"`
+	Posts   []cloudflare.DNSRecord `json:"posts,omitempty"`
+}
+
+type batchRecord struct {
+	ID      string `json:"id,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Content string `json:"content,omitempty"`
+	TTL     int    `json:"ttl,omitempty"`
+}
+
 type metaClient struct {
 	clientEdit *cloudflare.API // needs Zone/DNS/Edit permissions
 	clientRead *cloudflare.API // needs Zone/Zone/Read permissions
@@ -67,10 +92,66 @@ func (m *metaClient) DNSRecords(ctx context.Context, zoneID string, rr cloudflar
 	return m.clientEdit.ListDNSRecords(ctx, cloudflare.ZoneIdentifier(zoneID), rr)
 }
 
+func (m *metaClient) UpdateDNSRecord(ctx context.Context, zoneID string, rr cloudflare.UpdateDNSRecordParams) (cloudflare.DNSRecord, error) {
+	return m.clientEdit.UpdateDNSRecord(ctx, cloudflare.ZoneIdentifier(zoneID), rr)
+}
+
 func (m *metaClient) DeleteDNSRecord(ctx context.Context, zoneID, recordID string) error {
 	return m.clientEdit.DeleteDNSRecord(ctx, cloudflare.ZoneIdentifier(zoneID), recordID)
 }
 
+// BatchDNSRecords deletes and creates DNS records with a single request.
+// The changes are applied atomically: if one of them fails, none of them is applied.
+func (m *metaClient) BatchDNSRecords(ctx context.Context, zoneID string, batch batchRequest) (*batchResult, error) {
+	resp, err := m.clientEdit.Raw(ctx, http.MethodPost, "/zones/"+zoneID+"/dns_records/batch", batch, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result batchResult
+	err = json.Unmarshal(resp.Result, &result)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal batch result: %w", err)
+	}
+
+	return &result, nil
+}
+
+// Validate checks that the zones are readable and, when a dedicated DNS token is used, that this token is active.
+// Only the first zone is requested, to avoid listing all the zones of the account.
+func (m *metaClient) Validate(ctx context.Context) error {
+	resp, err := m.clientRead.Raw(ctx, http.MethodGet, "/zones?per_page=1", nil, nil)
+	if err != nil {
+		return fmt.Errorf("list zones: %w", err)
+	}
+
+	var zones []cloudflare.Zone
+
+	err = json.Unmarshal(resp.Result, &zones)
+	if err != nil {
+		return fmt.Errorf("list zones: %w", err)
+	}
+
+	if len(zones) == 0 {
+		return errors.New("no zone is accessible with these credentials")
+	}
+
+	if m.clientEdit == m.clientRead {
+		return nil
+	}
+
+	token, err := m.clientEdit.VerifyAPIToken(ctx)
+	if err != nil {
+		return fmt.Errorf("verify DNS API token: %w", err)
+	}
+
+	if token.Status != "active" {
+		return fmt.Errorf("the DNS API token is %s", token.Status)
+	}
+
+	return nil
+}
+
 func (m *metaClient) ZoneIDByName(fdqn string) (string, error) {
 	m.zonesMu.RLock()
 	id := m.zones[fdqn]
@@ -0,0 +1,84 @@
+package cloudflare
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/stretchr/testify/require"
+)
+
+func setupValidateTest(t *testing.T, zones, tokenStatus string) *metaClient {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/zones", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer zone" {
+			rw.WriteHeader(http.StatusForbidden)
+			_, _ = fmt.Fprint(rw, `{"success":false,"errors":[{"code":9109,"message":"Invalid access token"}],"messages":[],"result":null}`)
+			return
+		}
+
+		if req.URL.Query().Get("per_page") != "1" {
+			rw.WriteHeader(http.StatusBadRequest)
+			_, _ = fmt.Fprintf(rw, `{"success":false,"errors":[{"code":1000,"message":"unexpected per_page: %s"}],"messages":[],"result":null}`, req.URL.Query().Get("per_page"))
+			return
+		}
+
+		_, _ = fmt.Fprintf(rw, `{"success":true,"errors":[],"messages":[],"result":[%s],"result_info":{"page":1,"per_page":1,"total_pages":1,"count":1,"total_count":1}}`, zones)
+	})
+
+	mux.HandleFunc("/user/tokens/verify", func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = fmt.Fprintf(rw, `{"success":true,"errors":[],"messages":[],"result":{"id":"123","status":%q}}`, tokenStatus)
+	})
+
+	clientEdit, err := cloudflare.NewWithAPIToken("dns", cloudflare.BaseURL(server.URL))
+	require.NoError(t, err)
+
+	clientRead, err := cloudflare.NewWithAPIToken("zone", cloudflare.BaseURL(server.URL))
+	require.NoError(t, err)
+
+	return &metaClient{
+		clientEdit: clientEdit,
+		clientRead: clientRead,
+		zones:      make(map[string]string),
+		zonesMu:    &sync.RWMutex{},
+	}
+}
+
+func Test_metaClient_Validate(t *testing.T) {
+	client := setupValidateTest(t, `{"id":"023e105f4ecef8ad9ca31a8372d0c353","name":"example.com"}`, "active")
+
+	err := client.Validate(context.Background())
+	require.NoError(t, err)
+}
+
+func Test_metaClient_Validate_noZone(t *testing.T) {
+	client := setupValidateTest(t, "", "active")
+
+	err := client.Validate(context.Background())
+	require.EqualError(t, err, "no zone is accessible with these credentials")
+}
+
+func Test_metaClient_Validate_inactiveToken(t *testing.T) {
+	client := setupValidateTest(t, `{"id":"023e105f4ecef8ad9ca31a8372d0c353","name":"example.com"}`, "disabled")
+
+	err := client.Validate(context.Background())
+	require.EqualError(t, err, "the DNS API token is disabled")
+}
+
+func Test_metaClient_Validate_invalidCredentials(t *testing.T) {
+	client := setupValidateTest(t, "", "active")
+
+	client.clientRead = client.clientEdit
+
+	err := client.Validate(context.Background())
+	require.ErrorContains(t, err, "list zones: ")
+}
@@ -31,6 +31,8 @@ const (
 
 var _ challenge.ProviderTimeout = (*DNSProvider)(nil)
 
+var _ challenge.ProviderValidator = (*DNSProvider)(nil)
+
 // Config is used to configure the creation of the DNSProvider.
 type Config struct {
 	BaseURL            string
@@ -111,6 +113,20 @@ func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
 	return d.config.PropagationTimeout, d.config.PollingInterval
 }
 
+// Validate checks the credentials and the access to the domains.
+func (d *DNSProvider) Validate() error {
+	domains, err := d.client.ListDomains(context.Background())
+	if err != nil {
+		return fmt.Errorf("digitalocean: %w", err)
+	}
+
+	if len(domains) == 0 {
+		return errors.New("digitalocean: no domain is accessible with these credentials")
+	}
+
+	return nil
+}
+
 // Present creates a TXT record using the specified parameters.
 func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 	info := dns01.GetChallengeInfo(domain, keyAuth)
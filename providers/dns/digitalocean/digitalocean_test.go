@@ -173,3 +173,33 @@ func TestDNSProvider_CleanUp(t *testing.T) {
 	err := provider.CleanUp("example.com", "token", "")
 	require.NoError(t, err, "fail to remove TXT record")
 }
+
+func TestDNSProvider_Validate(t *testing.T) {
+	provider, mux := setupTest(t)
+
+	mux.HandleFunc("/v2/domains", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "method")
+		assert.Equal(t, "Bearer asdf1234", r.Header.Get("Authorization"), "Authorization")
+
+		_, err := fmt.Fprint(w, `{"domains":[{"name":"example.com","ttl":1800,"zone_file":""}],"links":{},"meta":{"total":1}}`)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	err := provider.Validate()
+	require.NoError(t, err)
+}
+
+func TestDNSProvider_Validate_unauthorized(t *testing.T) {
+	provider, mux := setupTest(t)
+
+	mux.HandleFunc("/v2/domains", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = fmt.Fprint(w, `{"id":"Unauthorized","message":"Unable to authenticate you."}`)
+	})
+
+	err := provider.Validate()
+	require.EqualError(t, err, "digitalocean: [status code 401] Unauthorized: Unable to authenticate you.")
+}
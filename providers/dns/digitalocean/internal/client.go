@@ -36,6 +36,23 @@ func NewClient(hc *http.Client) *Client {
 	return &Client{BaseURL: baseURL, httpClient: hc}
 }
 
+func (c *Client) ListDomains(ctx context.Context) ([]Domain, error) {
+	endpoint := c.BaseURL.JoinPath("v2", "domains")
+
+	req, err := newJSONRequest(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	respData := &DomainsResponse{}
+	err = c.do(req, respData)
+	if err != nil {
+		return nil, err
+	}
+
+	return respData.Domains, nil
+}
+
 func (c *Client) AddTxtRecord(ctx context.Context, zone string, record Record) (*TxtRecordResponse, error) {
 	endpoint := c.BaseURL.JoinPath("v2", "domains", dns01.UnFqdn(zone), "records")
 
@@ -50,6 +50,30 @@ func writeFixture(rw http.ResponseWriter, filename string) {
 	_, _ = io.Copy(rw, file)
 }
 
+func TestClient_ListDomains(t *testing.T) {
+	client := setupTest(t, "/v2/domains", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		err := checkHeader(req, "Authorization", "Bearer secret")
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		writeFixture(rw, "domains_GET.json")
+	})
+
+	domains, err := client.ListDomains(context.Background())
+	require.NoError(t, err)
+
+	expected := []Domain{{Name: "example.com", TTL: 1800}}
+
+	assert.Equal(t, expected, domains)
+}
+
 func TestClient_AddTxtRecord(t *testing.T) {
 	client := setupTest(t, "/v2/domains/example.com/records", func(rw http.ResponseWriter, req *http.Request) {
 		if req.Method != http.MethodPost {
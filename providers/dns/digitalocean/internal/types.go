@@ -15,6 +15,16 @@ type Record struct {
 	TTL  int    `json:"ttl,omitempty"`
 }
 
+// DomainsResponse represents a response from DO's API listing the domains.
+type DomainsResponse struct {
+	Domains []Domain `json:"domains"`
+}
+
+type Domain struct {
+	Name string `json:"name,omitempty"`
+	TTL  int    `json:"ttl,omitempty"`
+}
+
 type APIError struct {
 	ID      string `json:"id"`
 	Message string `json:"message"`
@@ -81,6 +81,28 @@ func (c *Client) getAPIVersion(ctx context.Context) (int, error) {
 	return latestVersion, err
 }
 
+func (c *Client) ListZones(ctx context.Context) ([]HostedZone, error) {
+	endpoint := c.joinPath("/", "servers", c.serverName, "zones")
+
+	req, err := newJSONRequest(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var zones []HostedZone
+	err = json.Unmarshal(result, &zones)
+	if err != nil {
+		return nil, err
+	}
+
+	return zones, nil
+}
+
 func (c *Client) GetHostedZone(ctx context.Context, authZone string) (*HostedZone, error) {
 	endpoint := c.joinPath("/", "servers", c.serverName, "zones", dns.Fqdn(authZone))
 
@@ -159,6 +159,31 @@ func TestClient_joinPath(t *testing.T) {
 	}
 }
 
+func TestClient_ListZones(t *testing.T) {
+	client := setupTest(t, http.MethodGet, "/api/v1/servers/server/zones", http.StatusOK, "zones.json")
+	client.apiVersion = 1
+
+	zones, err := client.ListZones(context.Background())
+	require.NoError(t, err)
+
+	expected := []HostedZone{
+		{
+			ID:   "example.org.",
+			Name: "example.org.",
+			URL:  "/api/v1/servers/localhost/zones/example.org.",
+			Kind: "Native",
+		},
+		{
+			ID:   "example.com.",
+			Name: "example.com.",
+			URL:  "/api/v1/servers/localhost/zones/example.com.",
+			Kind: "Master",
+		},
+	}
+
+	assert.Equal(t, expected, zones)
+}
+
 func TestClient_GetHostedZone(t *testing.T) {
 	client := setupTest(t, http.MethodGet, "/api/v1/servers/server/zones/example.org.", http.StatusOK, "zone.json")
 	client.apiVersion = 1
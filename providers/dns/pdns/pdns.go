@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/go-acme/lego/v4/challenge"
@@ -14,6 +15,7 @@ import (
 	"github.com/go-acme/lego/v4/log"
 	"github.com/go-acme/lego/v4/platform/config/env"
 	"github.com/go-acme/lego/v4/providers/dns/pdns/internal"
+	"github.com/go-acme/lego/v4/providers/dns/records"
 )
 
 // Environment variables names.
@@ -33,6 +35,12 @@ const (
 
 var _ challenge.ProviderTimeout = (*DNSProvider)(nil)
 
+var _ challenge.ProviderValidator = (*DNSProvider)(nil)
+
+var _ dns01.BatchProvider = (*DNSProvider)(nil)
+
+var _ records.Provider = (*DNSProvider)(nil)
+
 // Config is used to configure the creation of the DNSProvider.
 type Config struct {
 	APIKey             string
@@ -118,6 +126,30 @@ func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
 	return d.config.PropagationTimeout, d.config.PollingInterval
 }
 
+// Validate checks the credentials and the access to the zones.
+func (d *DNSProvider) Validate() error {
+	ctx := context.Background()
+
+	// the API version is fetched again if it failed during the creation of the provider.
+	if d.client.APIVersion() <= 0 && d.config.APIVersion <= 0 {
+		err := d.client.SetAPIVersion(ctx)
+		if err != nil {
+			return fmt.Errorf("pdns: get API version: %w", err)
+		}
+	}
+
+	zones, err := d.client.ListZones(ctx)
+	if err != nil {
+		return fmt.Errorf("pdns: list zones: %w", err)
+	}
+
+	if len(zones) == 0 {
+		return errors.New("pdns: no zone is accessible with these credentials")
+	}
+
+	return nil
+}
+
 // Present creates a TXT record to fulfill the dns-01 challenge.
 func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 	info := dns01.GetChallengeInfo(domain, keyAuth)
@@ -127,6 +159,169 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 		return fmt.Errorf("pdns: could not find zone for domain %q: %w", domain, err)
 	}
 
+	return d.PresentBatch(authZone, []dns01.BatchRecord{{Domain: domain, Token: token, KeyAuth: keyAuth, Info: info}})
+}
+
+// CleanUp removes the TXT record matching the specified parameters.
+func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
+	info := dns01.GetChallengeInfo(domain, keyAuth)
+
+	authZone, err := dns01.FindZoneByFqdn(info.EffectiveFQDN)
+	if err != nil {
+		return fmt.Errorf("pdns: could not find zone for domain %q: %w", domain, err)
+	}
+
+	return d.CleanUpBatch(authZone, []dns01.BatchRecord{{Domain: domain, Token: token, KeyAuth: keyAuth, Info: info}})
+}
+
+// PresentBatch creates the TXT records of several challenges of a zone with a single update.
+func (d *DNSProvider) PresentBatch(authZone string, records []dns01.BatchRecord) error {
+	ctx := context.Background()
+
+	zone, err := d.client.GetHostedZone(ctx, authZone)
+	if err != nil {
+		return fmt.Errorf("pdns: %w", err)
+	}
+
+	var rrSets internal.RRSets
+	index := make(map[string]int)
+
+	for _, record := range records {
+		fqdn := record.Info.EffectiveFQDN
+
+		name := fqdn
+		if d.client.APIVersion() == 0 {
+			// pre-v1 API wants non-fqdn
+			name = dns01.UnFqdn(fqdn)
+		}
+
+		i, ok := index[fqdn]
+		if !ok {
+			set := internal.RRSet{
+				Name:       name,
+				ChangeType: "REPLACE",
+				Type:       "TXT",
+				Kind:       "Master",
+				TTL:        d.config.TTL,
+			}
+
+			// merge the existing and new records
+			if existingRRSet := findTxtRecord(zone, fqdn); existingRRSet != nil {
+				set.Records = existingRRSet.Records
+			}
+
+			i = len(rrSets.RRSets)
+			index[fqdn] = i
+			rrSets.RRSets = append(rrSets.RRSets, set)
+		}
+
+		rrSets.RRSets[i].Records = append(rrSets.RRSets[i].Records, internal.Record{
+			Content:  "\"" + record.Info.Value + "\"",
+			Disabled: false,
+
+			// pre-v1 API
+			Type: "TXT",
+			Name: name,
+			TTL:  d.config.TTL,
+		})
+	}
+
+	err = d.client.UpdateRecords(ctx, zone, rrSets)
+	if err != nil {
+		return fmt.Errorf("pdns: %w", err)
+	}
+
+	return d.client.Notify(ctx, zone)
+}
+
+// CleanUpBatch removes the TXT records of several challenges of a zone with a single update.
+func (d *DNSProvider) CleanUpBatch(authZone string, records []dns01.BatchRecord) error {
+	ctx := context.Background()
+
+	zone, err := d.client.GetHostedZone(ctx, authZone)
+	if err != nil {
+		return fmt.Errorf("pdns: %w", err)
+	}
+
+	var rrSets internal.RRSets
+	var missing []string
+
+	seen := make(map[string]struct{})
+
+	for _, record := range records {
+		fqdn := record.Info.EffectiveFQDN
+
+		if _, ok := seen[fqdn]; ok {
+			continue
+		}
+
+		seen[fqdn] = struct{}{}
+
+		set := findTxtRecord(zone, fqdn)
+		if set == nil {
+			missing = append(missing, fqdn)
+			continue
+		}
+
+		rrSets.RRSets = append(rrSets.RRSets, internal.RRSet{
+			Name:       set.Name,
+			Type:       set.Type,
+			ChangeType: "DELETE",
+		})
+	}
+
+	if len(rrSets.RRSets) > 0 {
+		err = d.client.UpdateRecords(ctx, zone, rrSets)
+		if err != nil {
+			return fmt.Errorf("pdns: %w", err)
+		}
+
+		err = d.client.Notify(ctx, zone)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("pdns: no existing record found for %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// ListRecords returns the TXT, CAA, TLSA and CNAME records of the zone.
+func (d *DNSProvider) ListRecords(authZone string) ([]records.Record, error) {
+	zone, err := d.client.GetHostedZone(context.Background(), authZone)
+	if err != nil {
+		return nil, fmt.Errorf("pdns: %w", err)
+	}
+
+	var result []records.Record
+
+	for _, set := range zone.RRSets {
+		if !records.IsSupportedType(set.Type) {
+			continue
+		}
+
+		for _, record := range set.Records {
+			if record.Disabled {
+				continue
+			}
+
+			result = append(result, toRecord(set, record))
+		}
+	}
+
+	return result, nil
+}
+
+// UpsertRecord creates the record, or updates its TTL if the record already exists.
+func (d *DNSProvider) UpsertRecord(authZone string, record records.Record) error {
+	record, err := record.Normalize()
+	if err != nil {
+		return fmt.Errorf("pdns: %w", err)
+	}
+
 	ctx := context.Background()
 
 	zone, err := d.client.GetHostedZone(ctx, authZone)
@@ -134,29 +329,30 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 		return fmt.Errorf("pdns: %w", err)
 	}
 
-	name := info.EffectiveFQDN
+	name := record.FQDN
 	if d.client.APIVersion() == 0 {
 		// pre-v1 API wants non-fqdn
-		name = dns01.UnFqdn(info.EffectiveFQDN)
+		name = dns01.UnFqdn(record.FQDN)
 	}
 
-	// Look for existing records.
-	existingRRSet := findTxtRecord(zone, info.EffectiveFQDN)
-
 	// merge the existing and new records
-	var records []internal.Record
-	if existingRRSet != nil {
-		records = existingRRSet.Records
+	var existing []internal.Record
+	if set := findRRSet(zone, record.FQDN, record.Type); set != nil {
+		for _, r := range set.Records {
+			if !records.Same(record, toRecord(*set, r)) {
+				existing = append(existing, r)
+			}
+		}
 	}
 
 	rec := internal.Record{
-		Content:  "\"" + info.Value + "\"",
+		Content:  record.Value,
 		Disabled: false,
 
 		// pre-v1 API
-		Type: "TXT",
+		Type: record.Type,
 		Name: name,
-		TTL:  d.config.TTL,
+		TTL:  record.TTL,
 	}
 
 	rrSets := internal.RRSets{
@@ -164,10 +360,10 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 			{
 				Name:       name,
 				ChangeType: "REPLACE",
-				Type:       "TXT",
+				Type:       record.Type,
 				Kind:       "Master",
-				TTL:        d.config.TTL,
-				Records:    append(records, rec),
+				TTL:        record.TTL,
+				Records:    append(existing, rec),
 			},
 		},
 	}
@@ -180,15 +376,8 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 	return d.client.Notify(ctx, zone)
 }
 
-// CleanUp removes the TXT record matching the specified parameters.
-func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
-	info := dns01.GetChallengeInfo(domain, keyAuth)
-
-	authZone, err := dns01.FindZoneByFqdn(info.EffectiveFQDN)
-	if err != nil {
-		return fmt.Errorf("pdns: could not find zone for domain %q: %w", domain, err)
-	}
-
+// DeleteRecord deletes the record.
+func (d *DNSProvider) DeleteRecord(authZone string, record records.Record) error {
 	ctx := context.Background()
 
 	zone, err := d.client.GetHostedZone(ctx, authZone)
@@ -196,23 +385,36 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 		return fmt.Errorf("pdns: %w", err)
 	}
 
-	set := findTxtRecord(zone, info.EffectiveFQDN)
-
+	set := findRRSet(zone, record.FQDN, record.Type)
 	if set == nil {
-		return fmt.Errorf("pdns: no existing record found for %s", info.EffectiveFQDN)
+		return nil
 	}
 
-	rrSets := internal.RRSets{
-		RRSets: []internal.RRSet{
-			{
-				Name:       set.Name,
-				Type:       set.Type,
-				ChangeType: "DELETE",
-			},
-		},
+	var remaining []internal.Record
+	for _, r := range set.Records {
+		if !records.Same(record, toRecord(*set, r)) {
+			remaining = append(remaining, r)
+		}
 	}
 
-	err = d.client.UpdateRecords(ctx, zone, rrSets)
+	if len(remaining) == len(set.Records) {
+		return nil
+	}
+
+	rrSet := internal.RRSet{
+		Name:       set.Name,
+		Type:       set.Type,
+		ChangeType: "DELETE",
+	}
+
+	if len(remaining) > 0 {
+		rrSet.ChangeType = "REPLACE"
+		rrSet.Kind = "Master"
+		rrSet.TTL = set.TTL
+		rrSet.Records = remaining
+	}
+
+	err = d.client.UpdateRecords(ctx, zone, internal.RRSets{RRSets: []internal.RRSet{rrSet}})
 	if err != nil {
 		return fmt.Errorf("pdns: %w", err)
 	}
@@ -221,11 +423,25 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 }
 
 func findTxtRecord(zone *internal.HostedZone, fqdn string) *internal.RRSet {
+	return findRRSet(zone, fqdn, "TXT")
+}
+
+func findRRSet(zone *internal.HostedZone, fqdn, rrType string) *internal.RRSet {
 	for _, set := range zone.RRSets {
-		if set.Type == "TXT" && (set.Name == dns01.UnFqdn(fqdn) || set.Name == fqdn) {
+		if strings.EqualFold(set.Type, rrType) && (set.Name == dns01.UnFqdn(fqdn) || set.Name == fqdn) {
 			return &set
 		}
 	}
 
 	return nil
 }
+
+func toRecord(set internal.RRSet, record internal.Record) records.Record {
+	ttl := set.TTL
+	if ttl == 0 {
+		// pre-v1 API
+		ttl = record.TTL
+	}
+
+	return records.Record{FQDN: dns01.ToFqdn(set.Name), Type: set.Type, Value: record.Content, TTL: ttl}
+}
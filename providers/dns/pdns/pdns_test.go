@@ -1,10 +1,17 @@
 package pdns
 
 import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 
+	"github.com/go-acme/lego/v4/challenge/dns01"
 	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/go-acme/lego/v4/providers/dns/records"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -130,6 +137,210 @@ func TestNewDNSProviderConfig(t *testing.T) {
 	}
 }
 
+func TestDNSProvider_Validate(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		apiKey   string
+		zones    string
+		expected string
+	}{
+		{
+			desc:   "success",
+			apiKey: "secret",
+			zones:  `[{"id":"example.com.","name":"example.com.","kind":"Native"}]`,
+		},
+		{
+			desc:     "no zone",
+			apiKey:   "secret",
+			zones:    `[]`,
+			expected: "pdns: no zone is accessible with these credentials",
+		},
+		{
+			desc:     "invalid API key",
+			apiKey:   "invalid",
+			expected: "pdns: get API version: ",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			mux := http.NewServeMux()
+			server := httptest.NewServer(mux)
+			t.Cleanup(server.Close)
+
+			mux.HandleFunc("/", func(rw http.ResponseWriter, req *http.Request) {
+				if req.Header.Get("X-API-Key") != "secret" {
+					http.Error(rw, "Unauthorized", http.StatusUnauthorized)
+					return
+				}
+
+				switch req.URL.Path {
+				case "/api":
+					_, _ = fmt.Fprint(rw, `[{"url":"/api/v1","version":1}]`)
+				case "/api/v1/servers/localhost/zones":
+					_, _ = fmt.Fprint(rw, test.zones)
+				default:
+					http.NotFound(rw, req)
+				}
+			})
+
+			config := NewDefaultConfig()
+			config.APIKey = test.apiKey
+			config.Host = mustParse(server.URL)
+
+			p, err := NewDNSProviderConfig(config)
+			require.NoError(t, err)
+
+			err = p.Validate()
+			if test.expected == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorContains(t, err, test.expected)
+		})
+	}
+}
+
+func setupBatchTest(t *testing.T, zone string) (*DNSProvider, *[]string) {
+	t.Helper()
+
+	var patches []string
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/api/v1/servers/localhost/zones/example.org.", func(rw http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+			_, _ = fmt.Fprint(rw, zone)
+		case http.MethodPatch:
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				http.Error(rw, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			patches = append(patches, string(body))
+			rw.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/api/v1/servers/localhost/zones/example.org./notify", func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = fmt.Fprint(rw, `{"result":"Notification queued"}`)
+	})
+
+	config := NewDefaultConfig()
+	config.APIKey = "secret"
+	config.APIVersion = 1
+	config.Host = mustParse(server.URL)
+
+	p, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	return p, &patches
+}
+
+func TestDNSProvider_PresentBatch(t *testing.T) {
+	p, patches := setupBatchTest(t, `{"id":"example.org.","name":"example.org.","kind":"Master","rrsets":[`+
+		`{"name":"_acme-challenge.example.org.","type":"TXT","ttl":120,"records":[{"content":"\"existing\"","disabled":false}]}]}`)
+
+	records := []dns01.BatchRecord{
+		{Domain: "example.org", Info: dns01.ChallengeInfo{EffectiveFQDN: "_acme-challenge.example.org.", Value: "a"}},
+		{Domain: "example.org", Info: dns01.ChallengeInfo{EffectiveFQDN: "_acme-challenge.example.org.", Value: "b"}},
+		{Domain: "www.example.org", Info: dns01.ChallengeInfo{EffectiveFQDN: "_acme-challenge.www.example.org.", Value: "c"}},
+	}
+
+	err := p.PresentBatch("example.org.", records)
+	require.NoError(t, err)
+
+	expected := `{"rrsets":[` +
+		`{"name":"_acme-challenge.example.org.","type":"TXT","kind":"Master","changetype":"REPLACE","records":[` +
+		`{"content":"\"existing\"","disabled":false,"name":"","type":""},` +
+		`{"content":"\"a\"","disabled":false,"name":"_acme-challenge.example.org.","type":"TXT","ttl":120},` +
+		`{"content":"\"b\"","disabled":false,"name":"_acme-challenge.example.org.","type":"TXT","ttl":120}],"ttl":120},` +
+		`{"name":"_acme-challenge.www.example.org.","type":"TXT","kind":"Master","changetype":"REPLACE","records":[` +
+		`{"content":"\"c\"","disabled":false,"name":"_acme-challenge.www.example.org.","type":"TXT","ttl":120}],"ttl":120}]}` + "\n"
+
+	assert.Equal(t, []string{expected}, *patches)
+}
+
+func TestDNSProvider_CleanUpBatch(t *testing.T) {
+	p, patches := setupBatchTest(t, `{"id":"example.org.","name":"example.org.","kind":"Master","rrsets":[`+
+		`{"name":"_acme-challenge.example.org.","type":"TXT","ttl":120,"records":[{"content":"\"a\"","disabled":false}]},`+
+		`{"name":"_acme-challenge.www.example.org.","type":"TXT","ttl":120,"records":[{"content":"\"c\"","disabled":false}]}]}`)
+
+	records := []dns01.BatchRecord{
+		{Domain: "example.org", Info: dns01.ChallengeInfo{EffectiveFQDN: "_acme-challenge.example.org.", Value: "a"}},
+		{Domain: "example.org", Info: dns01.ChallengeInfo{EffectiveFQDN: "_acme-challenge.example.org.", Value: "b"}},
+		{Domain: "www.example.org", Info: dns01.ChallengeInfo{EffectiveFQDN: "_acme-challenge.www.example.org.", Value: "c"}},
+		{Domain: "api.example.org", Info: dns01.ChallengeInfo{EffectiveFQDN: "_acme-challenge.api.example.org.", Value: "d"}},
+	}
+
+	err := p.CleanUpBatch("example.org.", records)
+	require.EqualError(t, err, "pdns: no existing record found for _acme-challenge.api.example.org.")
+
+	expected := `{"rrsets":[` +
+		`{"name":"_acme-challenge.example.org.","type":"TXT","kind":"","changetype":"DELETE"},` +
+		`{"name":"_acme-challenge.www.example.org.","type":"TXT","kind":"","changetype":"DELETE"}]}` + "\n"
+
+	assert.Equal(t, []string{expected}, *patches)
+}
+
+func TestDNSProvider_ListRecords(t *testing.T) {
+	p, _ := setupBatchTest(t, `{"id":"example.org.","name":"example.org.","kind":"Master","rrsets":[`+
+		`{"name":"example.org.","type":"A","ttl":300,"records":[{"content":"192.0.2.1","disabled":false}]},`+
+		`{"name":"example.org.","type":"CAA","ttl":3600,"records":[{"content":"0 issue \"letsencrypt.org\"","disabled":false},`+
+		`{"content":"0 issue \"example.net\"","disabled":true}]}]}`)
+
+	result, err := p.ListRecords("example.org.")
+	require.NoError(t, err)
+
+	expected := []records.Record{
+		{FQDN: "example.org.", Type: records.TypeCAA, Value: `0 issue "letsencrypt.org"`, TTL: 3600},
+	}
+
+	assert.Equal(t, expected, result)
+}
+
+func TestDNSProvider_UpsertRecord(t *testing.T) {
+	p, patches := setupBatchTest(t, `{"id":"example.org.","name":"example.org.","kind":"Master","rrsets":[`+
+		`{"name":"example.org.","type":"CAA","ttl":3600,"records":[{"content":"0 issue \"letsencrypt.org\"","disabled":false},`+
+		`{"content":"0 issue \"example.net\"","disabled":false}]}]}`)
+
+	err := p.UpsertRecord("example.org.", records.Record{FQDN: "example.org", Type: "CAA", Value: "0 issue letsencrypt.org", TTL: 300})
+	require.NoError(t, err)
+
+	expected := `{"rrsets":[` +
+		`{"name":"example.org.","type":"CAA","kind":"Master","changetype":"REPLACE","records":[` +
+		`{"content":"0 issue \"example.net\"","disabled":false,"name":"","type":""},` +
+		`{"content":"0 issue \"letsencrypt.org\"","disabled":false,"name":"example.org.","type":"CAA","ttl":300}],"ttl":300}]}` + "\n"
+
+	assert.Equal(t, []string{expected}, *patches)
+}
+
+func TestDNSProvider_DeleteRecord(t *testing.T) {
+	p, patches := setupBatchTest(t, `{"id":"example.org.","name":"example.org.","kind":"Master","rrsets":[`+
+		`{"name":"_443._tcp.example.org.","type":"TLSA","ttl":3600,"records":[{"content":"3 1 1 aaaa","disabled":false},`+
+		`{"content":"3 1 1 bbbb","disabled":false}]}]}`)
+
+	err := p.DeleteRecord("example.org.", records.Record{FQDN: "_443._tcp.example.org.", Type: "TLSA", Value: "3 1 1 AAAA"})
+	require.NoError(t, err)
+
+	// Unknown record.
+	err = p.DeleteRecord("example.org.", records.Record{FQDN: "_443._tcp.example.org.", Type: "TLSA", Value: "3 1 1 cccc"})
+	require.NoError(t, err)
+
+	expected := `{"rrsets":[` +
+		`{"name":"_443._tcp.example.org.","type":"TLSA","kind":"Master","changetype":"REPLACE","records":[` +
+		`{"content":"3 1 1 bbbb","disabled":false,"name":"","type":""}],"ttl":3600}]}` + "\n"
+
+	assert.Equal(t, []string{expected}, *patches)
+}
+
 func TestLivePresentAndCleanup(t *testing.T) {
 	if !envTest.IsLiveTest() {
 		t.Skip("skipping live test")
@@ -0,0 +1,125 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// client a JSON-RPC client.
+// The messages are JSON objects separated by new lines.
+type client struct {
+	mu sync.Mutex
+
+	encoder *json.Encoder
+	decoder *json.Decoder
+
+	lastID int64
+
+	// timeout the maximum duration of a call.
+	timeout time.Duration
+	// onTimeout is called when a call times out (kills the plugin).
+	onTimeout func()
+	// err the error of a timed out call: the connection cannot be used anymore.
+	err error
+}
+
+func newClient(r io.Reader, w io.Writer, timeout time.Duration, onTimeout func()) *client {
+	return &client{
+		encoder:   json.NewEncoder(w),
+		decoder:   json.NewDecoder(r),
+		timeout:   timeout,
+		onTimeout: onTimeout,
+	}
+}
+
+// call sends a request and waits for the response.
+// The calls are serialized: the plugin handles one request at a time.
+// If the plugin doesn't respond before the timeout, the plugin is stopped, and the following calls fail.
+func (c *client) call(method string, params, result any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return fmt.Errorf("%s: %w", method, c.err)
+	}
+
+	c.lastID++
+
+	req := request{JSONRPC: jsonRPCVersion, ID: c.lastID, Method: method}
+
+	if params != nil {
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("%s: marshal params: %w", method, err)
+		}
+
+		req.Params = raw
+	}
+
+	done := make(chan error, 1)
+
+	var resp response
+
+	go func() { done <- c.exchange(method, req, &resp) }()
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return err
+		}
+
+	case <-timer.C:
+		c.err = fmt.Errorf("the plugin has not responded within %s", c.timeout)
+
+		if c.onTimeout != nil {
+			c.onTimeout()
+		}
+
+		return fmt.Errorf("%s: %w", method, c.err)
+	}
+
+	if resp.ID != req.ID {
+		return fmt.Errorf("%s: unexpected response ID: %d (expected %d)", method, resp.ID, req.ID)
+	}
+
+	if resp.Error != nil {
+		return fmt.Errorf("%s: %w", method, resp.Error)
+	}
+
+	if result == nil || len(resp.Result) == 0 {
+		return nil
+	}
+
+	err := json.Unmarshal(resp.Result, result)
+	if err != nil {
+		return fmt.Errorf("%s: unmarshal result: %w", method, err)
+	}
+
+	return nil
+}
+
+// exchange sends the request and reads the response.
+func (c *client) exchange(method string, req request, resp *response) error {
+	err := c.encoder.Encode(req)
+	if err != nil {
+		return fmt.Errorf("%s: send request: %w", method, err)
+	}
+
+	err = c.decoder.Decode(resp)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%s: the plugin has closed the connection", method)
+		}
+
+		return fmt.Errorf("%s: read response: %w", method, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,341 @@
+// Package plugin implements a DNS provider which delegates the management of the DNS records to an external program (a plugin).
+//
+// The plugin is started once, and lego talks to it with JSON-RPC 2.0 messages (separated by new lines)
+// over the standard input and output of the plugin.
+// The plugin describes itself (name, configuration, timeouts, sequential resolution),
+// receives its configuration, then creates and removes the TXT records.
+//
+// Plugins written in Go can use Serve to implement the protocol.
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/go-acme/lego/v4/platform/config/env"
+)
+
+// EnvPluginsPath the environment variable containing the list of directories where the plugins are searched,
+// before the directories of the PATH.
+const EnvPluginsPath = "LEGO_DNS_PLUGINS_PATH"
+
+// EnvCallTimeout the environment variable containing the maximum duration (in seconds) of a request to a plugin.
+// When a plugin doesn't respond in time, it is stopped.
+const EnvCallTimeout = "LEGO_DNS_PLUGINS_CALL_TIMEOUT"
+
+// BinaryPrefix the prefix of the file name of the plugins: the plugin "example" is the program "lego-dns-example".
+const BinaryPrefix = "lego-dns-"
+
+// ProviderPrefix the prefix of the DNS provider names using a plugin: "plugin:example" uses the plugin "example".
+const ProviderPrefix = "plugin:"
+
+const (
+	defaultCallTimeout = 2 * time.Minute
+	shutdownTimeout    = 5 * time.Second
+)
+
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+var _ challenge.ProviderTimeout = (*DNSProvider)(nil)
+
+// DNSProvider implements the challenge.Provider interface.
+type DNSProvider struct {
+	info   Info
+	client *client
+
+	cmd    *exec.Cmd
+	closer io.Closer
+}
+
+// Find returns the path of the plugin with the given name.
+func Find(name string) (string, error) {
+	if !namePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid plugin name: %q", name)
+	}
+
+	binary := BinaryPrefix + name
+
+	for _, dir := range filepath.SplitList(os.Getenv(EnvPluginsPath)) {
+		if dir == "" {
+			continue
+		}
+
+		path, err := exec.LookPath(filepath.Join(dir, binary))
+		if err == nil {
+			return path, nil
+		}
+	}
+
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return "", fmt.Errorf("plugin %s not found: %w", name, err)
+	}
+
+	return path, nil
+}
+
+// NewDNSProvider starts the plugin with the given name.
+// The returned provider implements the Sequential method if the plugin requires it.
+func NewDNSProvider(name string) (challenge.Provider, error) {
+	path, err := Find(name)
+	if err != nil {
+		return nil, fmt.Errorf("plugin: %w", err)
+	}
+
+	provider, err := start(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if provider.info.Name != name {
+		_ = provider.Close()
+
+		return nil, fmt.Errorf("plugin: unexpected name: %q (expected %q)", provider.info.Name, name)
+	}
+
+	err = provider.configure()
+	if err != nil {
+		_ = provider.Close()
+
+		return nil, err
+	}
+
+	return provider.withSequential(), nil
+}
+
+// NewDNSProviderProgram starts the plugin program.
+func NewDNSProviderProgram(program string, args ...string) (*DNSProvider, error) {
+	provider, err := start(program, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = provider.configure()
+	if err != nil {
+		_ = provider.Close()
+
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+// Describe returns the description of the plugin with the given name, without configuring it.
+func Describe(name string) (Info, error) {
+	path, err := Find(name)
+	if err != nil {
+		return Info{}, fmt.Errorf("plugin: %w", err)
+	}
+
+	provider, err := start(path)
+	if err != nil {
+		return Info{}, err
+	}
+
+	return provider.info, provider.Close()
+}
+
+// start starts and initializes the plugin program.
+func start(program string, args ...string) (*DNSProvider, error) {
+	cmd := exec.Command(program, args...)
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("plugin: create pipe: %w", err)
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("plugin: create pipe: %w", err)
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return nil, fmt.Errorf("plugin: start %s: %w", program, err)
+	}
+
+	kill := func() { _ = cmd.Process.Kill() }
+
+	provider, err := initialize(stdout, stdin, env.GetOrDefaultSecond(EnvCallTimeout, defaultCallTimeout), kill)
+	if err != nil {
+		_ = stdin.Close()
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+
+		return nil, err
+	}
+
+	provider.cmd = cmd
+
+	return provider, nil
+}
+
+// initialize exchanges the protocol versions with the plugin connected to the reader and the writer,
+// and gets the description of the plugin.
+// onTimeout is called when the plugin doesn't respond before the timeout.
+func initialize(r io.Reader, w io.WriteCloser, timeout time.Duration, onTimeout func()) (*DNSProvider, error) {
+	provider := &DNSProvider{client: newClient(r, w, timeout, onTimeout), closer: w}
+
+	err := provider.client.call(MethodInitialize, InitializeParams{ProtocolVersion: ProtocolVersion}, &provider.info)
+	if err != nil {
+		return nil, fmt.Errorf("plugin: %w", err)
+	}
+
+	if provider.info.ProtocolVersion != ProtocolVersion {
+		return nil, fmt.Errorf("plugin %s: unsupported protocol version: %d", provider.info.Name, provider.info.ProtocolVersion)
+	}
+
+	return provider, nil
+}
+
+// configure sends the configuration described by the plugin.
+func (d *DNSProvider) configure() error {
+	config, err := readConfig(d.info.Config)
+	if err != nil {
+		return fmt.Errorf("plugin %s: %w", d.info.Name, err)
+	}
+
+	err = d.client.call(MethodConfigure, ConfigureParams{Config: config}, nil)
+	if err != nil {
+		return fmt.Errorf("plugin %s: %w", d.info.Name, err)
+	}
+
+	return nil
+}
+
+// Info returns the description of the plugin.
+func (d *DNSProvider) Info() Info {
+	return d.info
+}
+
+// Present creates a TXT record to fulfill the dns-01 challenge.
+func (d *DNSProvider) Present(domain, token, keyAuth string) error {
+	err := d.client.call(MethodPresent, newChallengeParams(domain, token, keyAuth), nil)
+	if err != nil {
+		return fmt.Errorf("plugin %s: %w", d.info.Name, err)
+	}
+
+	return nil
+}
+
+// CleanUp removes the TXT record matching the specified parameters.
+func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
+	err := d.client.call(MethodCleanUp, newChallengeParams(domain, token, keyAuth), nil)
+	if err != nil {
+		return fmt.Errorf("plugin %s: %w", d.info.Name, err)
+	}
+
+	return nil
+}
+
+// Timeout returns the timeout and interval to use when checking for DNS propagation.
+// The values are defined by the plugin.
+func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
+	timeout = dns01.DefaultPropagationTimeout
+	if d.info.PropagationTimeout > 0 {
+		timeout = time.Duration(d.info.PropagationTimeout) * time.Second
+	}
+
+	interval = dns01.DefaultPollingInterval
+	if d.info.PollingInterval > 0 {
+		interval = time.Duration(d.info.PollingInterval) * time.Second
+	}
+
+	return timeout, interval
+}
+
+// Close asks the plugin to exit, and waits for it.
+func (d *DNSProvider) Close() error {
+	err := d.client.call(MethodShutdown, nil, nil)
+
+	_ = d.closer.Close()
+
+	if d.cmd == nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- d.cmd.Wait() }()
+
+	select {
+	case errW := <-done:
+		return errors.Join(err, errW)
+	case <-time.After(shutdownTimeout):
+		_ = d.cmd.Process.Kill()
+		return errors.Join(err, <-done)
+	}
+}
+
+// withSequential returns a provider implementing the Sequential method if the plugin requires it.
+func (d *DNSProvider) withSequential() challenge.Provider {
+	if d.info.SequenceInterval > 0 {
+		return &sequentialDNSProvider{DNSProvider: d}
+	}
+
+	return d
+}
+
+// sequentialDNSProvider a plugin requiring the challenges to be solved sequentially.
+type sequentialDNSProvider struct {
+	*DNSProvider
+}
+
+// Sequential All DNS challenges for this provider will be resolved sequentially.
+// Returns the interval between each iteration.
+func (d *sequentialDNSProvider) Sequential() time.Duration {
+	return time.Duration(d.info.SequenceInterval) * time.Second
+}
+
+func newChallengeParams(domain, token, keyAuth string) ChallengeParams {
+	info := dns01.GetChallengeInfo(domain, keyAuth)
+
+	return ChallengeParams{
+		Domain:  domain,
+		Token:   token,
+		KeyAuth: keyAuth,
+		FQDN:    info.EffectiveFQDN,
+		Value:   info.Value,
+	}
+}
+
+// readConfig reads the configuration described by the plugin from the environment variables.
+func readConfig(fields []ConfigField) (map[string]string, error) {
+	var required []string
+
+	config := make(map[string]string)
+
+	for _, field := range fields {
+		if field.Name == "" {
+			return nil, errors.New("invalid configuration: a field has no name")
+		}
+
+		if field.Required {
+			required = append(required, field.Name)
+			continue
+		}
+
+		if value := env.GetOrFile(field.Name); value != "" {
+			config[field.Name] = value
+		}
+	}
+
+	values, err := env.Get(required...)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range values {
+		config[k] = v
+	}
+
+	return config, nil
+}
@@ -0,0 +1,245 @@
+package plugin
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const envHelperProcess = "LEGO_PLUGIN_TEST_HELPER"
+
+// TestMain runs the test binary as a plugin (used by TestNewDNSProvider) when envHelperProcess is defined.
+func TestMain(m *testing.M) {
+	if os.Getenv(envHelperProcess) != "true" {
+		os.Exit(m.Run())
+	}
+
+	info := Info{
+		Name:             "test",
+		Config:           []ConfigField{{Name: "PLUGIN_TEST_TOKEN", Required: true}},
+		SequenceInterval: 10,
+	}
+
+	err := Serve(info, func(config map[string]string) (challenge.Provider, error) {
+		if config["PLUGIN_TEST_TOKEN"] != "secret" {
+			return nil, errors.New("invalid token")
+		}
+
+		return &providerMock{}, nil
+	})
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+type providerMock struct {
+	config map[string]string
+
+	presents []ChallengeParams
+	cleanUp  error
+}
+
+func (p *providerMock) Present(domain, token, keyAuth string) error {
+	info := dns01.GetChallengeInfo(domain, keyAuth)
+
+	p.presents = append(p.presents, ChallengeParams{Domain: domain, Token: token, KeyAuth: keyAuth, FQDN: info.EffectiveFQDN, Value: info.Value})
+
+	return nil
+}
+
+func (p *providerMock) CleanUp(_, _, _ string) error {
+	return p.cleanUp
+}
+
+func setupTest(t *testing.T, info Info, factory Factory) *DNSProvider {
+	t.Helper()
+
+	t.Setenv("LEGO_DISABLE_CNAME_SUPPORT", "true")
+
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	done := make(chan error, 1)
+
+	go func() {
+		err := ServeConn(serverReader, serverWriter, info, factory)
+		_ = serverWriter.Close()
+		done <- err
+	}()
+
+	provider, err := initialize(clientReader, clientWriter, defaultCallTimeout, nil)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, provider.Close())
+		require.NoError(t, <-done)
+	})
+
+	return provider
+}
+
+func TestDNSProvider(t *testing.T) {
+	t.Setenv("PLUGIN_TEST_TOKEN", "secret")
+	t.Setenv("PLUGIN_TEST_ZONE", "")
+
+	mock := &providerMock{cleanUp: errors.New("OOPS")}
+
+	info := Info{
+		Name: "test",
+		Config: []ConfigField{
+			{Name: "PLUGIN_TEST_TOKEN", Description: "API token", Required: true},
+			{Name: "PLUGIN_TEST_ZONE", Description: "Zone"},
+		},
+		PropagationTimeout: 120,
+	}
+
+	provider := setupTest(t, info, func(config map[string]string) (challenge.Provider, error) {
+		mock.config = config
+		return mock, nil
+	})
+
+	assert.Equal(t, "test", provider.Info().Name)
+	assert.Equal(t, ProtocolVersion, provider.Info().ProtocolVersion)
+
+	require.NoError(t, provider.configure())
+	assert.Equal(t, map[string]string{"PLUGIN_TEST_TOKEN": "secret"}, mock.config)
+
+	timeout, interval := provider.Timeout()
+	assert.Equal(t, 120*time.Second, timeout)
+	assert.Equal(t, dns01.DefaultPollingInterval, interval)
+
+	assert.NotImplements(t, (*interface{ Sequential() time.Duration })(nil), provider.withSequential())
+
+	require.NoError(t, provider.Present("example.com", "token", "keyAuth"))
+
+	expected := []ChallengeParams{{
+		Domain:  "example.com",
+		Token:   "token",
+		KeyAuth: "keyAuth",
+		FQDN:    "_acme-challenge.example.com.",
+		Value:   "pW9ZKG0xz_PCriK-nCMOjADy9eJcgGWIzkkj2fN4uZM",
+	}}
+	assert.Equal(t, expected, mock.presents)
+
+	err := provider.CleanUp("example.com", "token", "keyAuth")
+	require.EqualError(t, err, "plugin test: cleanup: -32603: OOPS")
+}
+
+func TestDNSProvider_configure_missing(t *testing.T) {
+	t.Setenv("PLUGIN_TEST_TOKEN", "")
+
+	info := Info{
+		Name:   "test",
+		Config: []ConfigField{{Name: "PLUGIN_TEST_TOKEN", Required: true}},
+	}
+
+	provider := setupTest(t, info, func(_ map[string]string) (challenge.Provider, error) {
+		return &providerMock{}, nil
+	})
+
+	err := provider.configure()
+	require.EqualError(t, err, "plugin test: some credentials information are missing: PLUGIN_TEST_TOKEN")
+
+	err = provider.Present("example.com", "token", "keyAuth")
+	require.EqualError(t, err, "plugin test: present: -32603: the plugin is not configured")
+}
+
+func TestDNSProvider_sequential(t *testing.T) {
+	provider := setupTest(t, Info{Name: "test", SequenceInterval: 30}, func(_ map[string]string) (challenge.Provider, error) {
+		return &providerMock{}, nil
+	})
+
+	sequential, ok := provider.withSequential().(interface{ Sequential() time.Duration })
+	require.True(t, ok)
+
+	assert.Equal(t, 30*time.Second, sequential.Sequential())
+}
+
+func TestServeConn_unsupportedVersion(t *testing.T) {
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	go func() {
+		_ = ServeConn(serverReader, serverWriter, Info{Name: "test"}, nil)
+		_ = serverWriter.Close()
+	}()
+
+	t.Cleanup(func() { _ = clientWriter.Close() })
+
+	c := newClient(clientReader, clientWriter, defaultCallTimeout, nil)
+
+	err := c.call(MethodInitialize, InitializeParams{ProtocolVersion: 42}, nil)
+	require.EqualError(t, err, "initialize: -32602: unsupported protocol version: 42 (supported: 1)")
+
+	err = c.call("unknown", nil, nil)
+	require.EqualError(t, err, "unknown: -32601: unknown method: unknown")
+}
+
+func TestClient_call_timeout(t *testing.T) {
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	// the plugin reads the requests but never responds.
+	go func() { _, _ = io.Copy(io.Discard, serverReader) }()
+
+	var killed bool
+
+	c := newClient(clientReader, clientWriter, 50*time.Millisecond, func() {
+		killed = true
+
+		_ = serverWriter.Close()
+		_ = clientWriter.Close()
+	})
+
+	err := c.call(MethodPresent, nil, nil)
+	require.EqualError(t, err, "present: the plugin has not responded within 50ms")
+
+	assert.True(t, killed)
+
+	err = c.call(MethodShutdown, nil, nil)
+	require.EqualError(t, err, "shutdown: the plugin has not responded within 50ms")
+}
+
+func TestNewDNSProvider(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the plugin is a symbolic link to the test binary")
+	}
+
+	testBinary, err := os.Executable()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+
+	err = os.Symlink(testBinary, filepath.Join(dir, BinaryPrefix+"test"))
+	require.NoError(t, err)
+
+	t.Setenv(EnvPluginsPath, dir)
+	t.Setenv(envHelperProcess, "true")
+	t.Setenv("PLUGIN_TEST_TOKEN", "secret")
+
+	provider, err := NewDNSProvider("test")
+	require.NoError(t, err)
+
+	require.NoError(t, provider.(*sequentialDNSProvider).Close())
+
+	info, err := Describe("test")
+	require.NoError(t, err)
+
+	assert.Equal(t, "test", info.Name)
+
+	_, err = NewDNSProvider("unknown")
+	require.ErrorIs(t, err, exec.ErrNotFound)
+
+	_, err = NewDNSProvider("../test")
+	require.EqualError(t, err, `plugin: invalid plugin name: "../test"`)
+}
@@ -0,0 +1,107 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ProtocolVersion the version of the plugin protocol.
+// The version is increased when a change is not backward compatible.
+const ProtocolVersion = 1
+
+// JSON-RPC methods.
+const (
+	// MethodInitialize exchanges the protocol versions, and returns the description of the plugin (Info).
+	MethodInitialize = "initialize"
+	// MethodConfigure sends the configuration (ConfigureParams) to the plugin.
+	MethodConfigure = "configure"
+	// MethodPresent creates the TXT record of a challenge (ChallengeParams).
+	MethodPresent = "present"
+	// MethodCleanUp removes the TXT record of a challenge (ChallengeParams).
+	MethodCleanUp = "cleanup"
+	// MethodShutdown asks the plugin to exit.
+	MethodShutdown = "shutdown"
+)
+
+const jsonRPCVersion = "2.0"
+
+// InitializeParams the parameters of the initialize method.
+type InitializeParams struct {
+	ProtocolVersion int `json:"protocolVersion"`
+}
+
+// Info the description of a plugin, returned by the initialize method.
+type Info struct {
+	// Name is the name of the provider.
+	Name string `json:"name"`
+	// ProtocolVersion is the version of the protocol implemented by the plugin.
+	ProtocolVersion int `json:"protocolVersion"`
+
+	// Config describes the configuration of the plugin.
+	Config []ConfigField `json:"config,omitempty"`
+
+	// PropagationTimeout is the maximum waiting time for DNS propagation in seconds.
+	PropagationTimeout int `json:"propagationTimeout,omitempty"`
+	// PollingInterval is the time between DNS propagation check in seconds.
+	PollingInterval int `json:"pollingInterval,omitempty"`
+	// SequenceInterval is the time between sequential requests in seconds.
+	// If it's not zero, the challenges are solved sequentially.
+	SequenceInterval int `json:"sequenceInterval,omitempty"`
+}
+
+// ConfigField the description of a configuration value.
+// The value is read from the environment variable of the same name (or from the file defined by `<name>_FILE`).
+type ConfigField struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// ConfigureParams the parameters of the configure method.
+type ConfigureParams struct {
+	Config map[string]string `json:"config"`
+}
+
+// ChallengeParams the parameters of the present and cleanup methods.
+type ChallengeParams struct {
+	Domain  string `json:"domain"`
+	Token   string `json:"token"`
+	KeyAuth string `json:"keyAuth"`
+
+	// FQDN is the fully-qualified domain name of the TXT record.
+	FQDN string `json:"fqdn"`
+	// Value is the value of the TXT record.
+	Value string `json:"value"`
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int64           `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int64           `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// RPCError a JSON-RPC error.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("%d: %s", e.Code, e.Message)
+}
+
+// JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
@@ -0,0 +1,167 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/go-acme/lego/v4/challenge"
+)
+
+// Factory creates the DNS provider from the configuration sent by lego.
+type Factory func(config map[string]string) (challenge.Provider, error)
+
+// Serve implements the plugin side of the protocol on the standard input and output.
+// It returns when lego asks the plugin to exit, or when the standard input is closed.
+//
+// The standard output is used by the protocol: the plugin must write its logs on the standard error.
+func Serve(info Info, factory Factory) error {
+	return ServeConn(os.Stdin, os.Stdout, info, factory)
+}
+
+// ServeConn implements the plugin side of the protocol on the given reader and writer.
+func ServeConn(r io.Reader, w io.Writer, info Info, factory Factory) error {
+	s := &server{
+		info:    info,
+		factory: factory,
+		encoder: json.NewEncoder(w),
+	}
+
+	s.info.ProtocolVersion = ProtocolVersion
+
+	decoder := json.NewDecoder(r)
+
+	for {
+		var req request
+
+		err := decoder.Decode(&req)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			// The stream cannot be read anymore.
+			_ = s.reply(0, nil, &RPCError{Code: codeParseError, Message: err.Error()})
+
+			return err
+		}
+
+		result, rpcErr := s.handle(req)
+
+		err = s.reply(req.ID, result, rpcErr)
+		if err != nil {
+			return err
+		}
+
+		if req.Method == MethodShutdown {
+			return nil
+		}
+	}
+}
+
+type server struct {
+	info    Info
+	factory Factory
+	encoder *json.Encoder
+
+	provider challenge.Provider
+}
+
+func (s *server) handle(req request) (any, *RPCError) {
+	switch req.Method {
+	case MethodInitialize:
+		var params InitializeParams
+
+		rpcErr := decodeParams(req.Params, &params)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+
+		if params.ProtocolVersion != ProtocolVersion {
+			return nil, &RPCError{
+				Code:    codeInvalidParams,
+				Message: fmt.Sprintf("unsupported protocol version: %d (supported: %d)", params.ProtocolVersion, ProtocolVersion),
+			}
+		}
+
+		return s.info, nil
+
+	case MethodConfigure:
+		var params ConfigureParams
+
+		rpcErr := decodeParams(req.Params, &params)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+
+		provider, err := s.factory(params.Config)
+		if err != nil {
+			return nil, &RPCError{Code: codeInternalError, Message: err.Error()}
+		}
+
+		s.provider = provider
+
+		return nil, nil
+
+	case MethodPresent, MethodCleanUp:
+		if s.provider == nil {
+			return nil, &RPCError{Code: codeInternalError, Message: "the plugin is not configured"}
+		}
+
+		var params ChallengeParams
+
+		rpcErr := decodeParams(req.Params, &params)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+
+		var err error
+		if req.Method == MethodPresent {
+			err = s.provider.Present(params.Domain, params.Token, params.KeyAuth)
+		} else {
+			err = s.provider.CleanUp(params.Domain, params.Token, params.KeyAuth)
+		}
+
+		if err != nil {
+			return nil, &RPCError{Code: codeInternalError, Message: err.Error()}
+		}
+
+		return nil, nil
+
+	case MethodShutdown:
+		return nil, nil
+
+	default:
+		return nil, &RPCError{Code: codeMethodNotFound, Message: fmt.Sprintf("unknown method: %s", req.Method)}
+	}
+}
+
+func (s *server) reply(id int64, result any, rpcErr *RPCError) error {
+	resp := response{JSONRPC: jsonRPCVersion, ID: id, Error: rpcErr}
+
+	if rpcErr == nil {
+		raw, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+
+		resp.Result = raw
+	}
+
+	return s.encoder.Encode(resp)
+}
+
+func decodeParams(raw json.RawMessage, params any) *RPCError {
+	if len(raw) == 0 {
+		return &RPCError{Code: codeInvalidParams, Message: "missing params"}
+	}
+
+	err := json.Unmarshal(raw, params)
+	if err != nil {
+		return &RPCError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+// Package records defines an optional interface for the DNS providers able to manage records other than the TXT records of the DNS-01 challenge.
+package records
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// Record types.
+const (
+	TypeTXT   = "TXT"
+	TypeCAA   = "CAA"
+	TypeTLSA  = "TLSA"
+	TypeCNAME = "CNAME"
+)
+
+// ErrUnsupportedType the record type is not managed by the providers.
+var ErrUnsupportedType = errors.New("unsupported record type")
+
+// Record a DNS resource record.
+type Record struct {
+	// FQDN the fully qualified domain name of the record (e.g. `_443._tcp.example.com.`).
+	FQDN string
+	Type string
+	// Value the data of the record, in presentation format (RFC 1035 zone file):
+	// `"text"` (TXT), `0 issue "letsencrypt.org"` (CAA), `3 1 1 0123abcd...` (TLSA), `target.example.com.` (CNAME).
+	Value string
+	TTL   int
+}
+
+// Provider a DNS provider able to manage the records of a zone.
+type Provider interface {
+	// ListRecords returns the records of the zone, only the records of the supported types (TXT, CAA, TLSA, CNAME).
+	ListRecords(zone string) ([]Record, error)
+
+	// UpsertRecord creates the record, or updates its TTL if the record (same FQDN, type and value) already exists.
+	// The other records with the same FQDN and type are kept.
+	UpsertRecord(zone string, record Record) error
+
+	// DeleteRecord deletes the record (same FQDN, type and value).
+	// The other records with the same FQDN and type are kept.
+	// Deleting a record that doesn't exist is not an error.
+	DeleteRecord(zone string, record Record) error
+}
+
+// IsSupportedType returns true if the record type is managed by the providers.
+func IsSupportedType(rrType string) bool {
+	return slices.Contains([]string{TypeTXT, TypeCAA, TypeTLSA, TypeCNAME}, strings.ToUpper(rrType))
+}
+
+// NewTXT creates a TXT record.
+// Long texts are split into strings of 255 characters.
+func NewTXT(fqdn, text string, ttl int) Record {
+	var txt []string
+
+	for len(text) > 255 {
+		txt = append(txt, text[:255])
+		text = text[255:]
+	}
+
+	txt = append(txt, text)
+
+	return FromRR(&dns.TXT{Hdr: header(fqdn, dns.TypeTXT, ttl), Txt: txt})
+}
+
+// NewCAA creates a CAA record (e.g. `0 issue "letsencrypt.org"`).
+func NewCAA(fqdn string, flag uint8, tag, value string, ttl int) Record {
+	return FromRR(&dns.CAA{Hdr: header(fqdn, dns.TypeCAA, ttl), Flag: flag, Tag: tag, Value: value})
+}
+
+// NewCNAME creates a CNAME record.
+func NewCNAME(fqdn, target string, ttl int) Record {
+	return FromRR(&dns.CNAME{Hdr: header(fqdn, dns.TypeCNAME, ttl), Target: dns.Fqdn(target)})
+}
+
+// FromRR creates a record from a resource record.
+func FromRR(rr dns.RR) Record {
+	hdr := rr.Header()
+
+	return Record{
+		FQDN:  dns.Fqdn(hdr.Name),
+		Type:  dns.TypeToString[hdr.Rrtype],
+		Value: strings.TrimPrefix(rr.String(), hdr.String()),
+		TTL:   int(hdr.Ttl),
+	}
+}
+
+// RR parses the record.
+func (r Record) RR() (dns.RR, error) {
+	if !IsSupportedType(r.Type) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, r.Type)
+	}
+
+	rr, err := dns.NewRR(fmt.Sprintf("%s %d IN %s %s", dns.Fqdn(r.FQDN), r.TTL, strings.ToUpper(r.Type), r.Value))
+	if err != nil {
+		return nil, fmt.Errorf("parse %s record %s: %w", r.Type, r.FQDN, err)
+	}
+
+	if rr == nil {
+		return nil, fmt.Errorf("parse %s record %s: empty record", r.Type, r.FQDN)
+	}
+
+	// The hexadecimal data is case-insensitive.
+	if tlsa, ok := rr.(*dns.TLSA); ok {
+		tlsa.Certificate = strings.ToLower(tlsa.Certificate)
+	}
+
+	return rr, nil
+}
+
+// Normalize returns the record with its value in canonical presentation format.
+func (r Record) Normalize() (Record, error) {
+	rr, err := r.RR()
+	if err != nil {
+		return Record{}, err
+	}
+
+	return FromRR(rr), nil
+}
+
+// Same returns true if the records have the same FQDN, type and value (the TTL is ignored).
+func Same(a, b Record) bool {
+	rrA, err := a.RR()
+	if err != nil {
+		return false
+	}
+
+	rrB, err := b.RR()
+	if err != nil {
+		return false
+	}
+
+	return dns.IsDuplicate(rrA, rrB)
+}
+
+func header(fqdn string, rrType uint16, ttl int) dns.RR_Header {
+	return dns.RR_Header{Name: dns.Fqdn(fqdn), Rrtype: rrType, Class: dns.ClassINET, Ttl: uint32(ttl)}
+}
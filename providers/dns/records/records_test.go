@@ -0,0 +1,94 @@
+package records
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTXT(t *testing.T) {
+	record := NewTXT("_acme-challenge.example.com", `a "quoted" text`, 120)
+
+	expected := Record{FQDN: "_acme-challenge.example.com.", Type: TypeTXT, Value: `"a \"quoted\" text"`, TTL: 120}
+
+	assert.Equal(t, expected, record)
+}
+
+func TestNewTXT_long(t *testing.T) {
+	record := NewTXT("example.com", strings.Repeat("a", 300), 120)
+
+	assert.Equal(t, `"`+strings.Repeat("a", 255)+`" "`+strings.Repeat("a", 45)+`"`, record.Value)
+}
+
+func TestNewCAA(t *testing.T) {
+	record := NewCAA("example.com", 0, "issue", "letsencrypt.org", 3600)
+
+	expected := Record{FQDN: "example.com.", Type: TypeCAA, Value: `0 issue "letsencrypt.org"`, TTL: 3600}
+
+	assert.Equal(t, expected, record)
+}
+
+func TestNewCNAME(t *testing.T) {
+	record := NewCNAME("_acme-challenge.example.com", "example.acme-dns.net", 300)
+
+	expected := Record{FQDN: "_acme-challenge.example.com.", Type: TypeCNAME, Value: "example.acme-dns.net.", TTL: 300}
+
+	assert.Equal(t, expected, record)
+}
+
+func TestRecord_RR_unsupportedType(t *testing.T) {
+	_, err := Record{FQDN: "example.com.", Type: "A", Value: "192.0.2.1"}.RR()
+	require.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestRecord_Normalize(t *testing.T) {
+	record, err := Record{FQDN: "example.com", Type: "caa", Value: `0   issue letsencrypt.org`, TTL: 60}.Normalize()
+	require.NoError(t, err)
+
+	expected := Record{FQDN: "example.com.", Type: TypeCAA, Value: `0 issue "letsencrypt.org"`, TTL: 60}
+
+	assert.Equal(t, expected, record)
+}
+
+func TestSame(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		a, b     Record
+		expected bool
+	}{
+		{
+			desc:     "same",
+			a:        NewTXT("example.com", "a", 60),
+			b:        NewTXT("Example.com.", "a", 120),
+			expected: true,
+		},
+		{
+			desc:     "different value",
+			a:        NewTXT("example.com", "a", 60),
+			b:        NewTXT("example.com", "b", 60),
+			expected: false,
+		},
+		{
+			desc:     "different format",
+			a:        Record{FQDN: "_443._tcp.example.com.", Type: TypeTLSA, Value: "3 1 1 ABCDEF"},
+			b:        Record{FQDN: "_443._tcp.example.com.", Type: TypeTLSA, Value: "3 1 1 abcdef"},
+			expected: true,
+		},
+		{
+			desc:     "different type",
+			a:        NewCNAME("example.com", "target.example.org", 60),
+			b:        Record{FQDN: "example.com.", Type: TypeTXT, Value: `"target.example.org."`},
+			expected: false,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, Same(test.a, test.b))
+		})
+	}
+}
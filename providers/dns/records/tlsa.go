@@ -0,0 +1,46 @@
+package records
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// TLSA parameters.
+// - https://www.rfc-editor.org/rfc/rfc6698.html#section-2.1
+// - https://www.rfc-editor.org/rfc/rfc7218.html
+const (
+	TLSAUsageDANEEE        = 3
+	TLSASelectorSPKI       = 1
+	TLSAMatchingTypeSHA256 = 1
+)
+
+// TLSAName returns the FQDN of the TLSA records of a service (e.g. `_443._tcp.example.com.`).
+// - https://www.rfc-editor.org/rfc/rfc6698.html#section-3
+func TLSAName(port int, protocol, domain string) string {
+	return dns.Fqdn(fmt.Sprintf("_%d._%s.%s", port, strings.ToLower(protocol), domain))
+}
+
+// NewTLSA creates a `3 1 1` TLSA record (DANE-EE, SubjectPublicKeyInfo, SHA-256) matching the public key.
+// - https://www.rfc-editor.org/rfc/rfc7671.html#section-5.1
+func NewTLSA(fqdn string, publicKey crypto.PublicKey, ttl int) (Record, error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return Record{}, fmt.Errorf("TLSA: %w", err)
+	}
+
+	sum := sha256.Sum256(der)
+
+	return FromRR(&dns.TLSA{
+		Hdr:          header(fqdn, dns.TypeTLSA, ttl),
+		Usage:        TLSAUsageDANEEE,
+		Selector:     TLSASelectorSPKI,
+		MatchingType: TLSAMatchingTypeSHA256,
+		Certificate:  hex.EncodeToString(sum[:]),
+	}), nil
+}
@@ -0,0 +1,55 @@
+package records
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLSAName(t *testing.T) {
+	assert.Equal(t, "_443._tcp.example.com.", TLSAName(443, "tcp", "example.com"))
+	assert.Equal(t, "_25._tcp.mail.example.com.", TLSAName(25, "TCP", "mail.example.com."))
+}
+
+func TestNewTLSA(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	record, err := NewTLSA("_443._tcp.example.com", key.Public(), 3600)
+	require.NoError(t, err)
+
+	// The record matches the SubjectPublicKeyInfo of the certificate.
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+
+	expected := Record{
+		FQDN:  "_443._tcp.example.com.",
+		Type:  TypeTLSA,
+		Value: "3 1 1 " + hex.EncodeToString(sum[:]),
+		TTL:   3600,
+	}
+
+	assert.Equal(t, expected, record)
+}
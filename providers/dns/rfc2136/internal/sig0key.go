@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// SIG0Key a SIG(0) key.
+type SIG0Key struct {
+	// Key the public key (KEY or DNSKEY record).
+	Key *dns.DNSKEY
+	// PrivateKey the private key used to sign the messages.
+	PrivateKey crypto.Signer
+}
+
+// ReadSIG0File reads SIG(0) key files generated with `dnssec-keygen` (`K<name>+<alg>+<id>.key` and `K<name>+<alg>+<id>.private`).
+// The filename can be the path to the `.key` file, the `.private` file, or the path without extension.
+func ReadSIG0File(filename string) (*SIG0Key, error) {
+	base := strings.TrimSuffix(strings.TrimSuffix(filename, ".key"), ".private")
+
+	key, err := readPublicKey(base + ".key")
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(base + ".private")
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+
+	defer func() { _ = file.Close() }()
+
+	privateKey, err := key.ReadPrivateKey(file, file.Name())
+	if err != nil {
+		return nil, fmt.Errorf("read private key: %w", err)
+	}
+
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type: %T", privateKey)
+	}
+
+	return &SIG0Key{Key: key, PrivateKey: signer}, nil
+}
+
+func readPublicKey(filename string) (*dns.DNSKEY, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+
+	defer func() { _ = file.Close() }()
+
+	zp := dns.NewZoneParser(file, "", filename)
+
+	for rr, ok := zp.Next(); ok; rr, ok = zp.Next() {
+		switch v := rr.(type) {
+		case *dns.KEY:
+			return &v.DNSKEY, nil
+		case *dns.DNSKEY:
+			return v, nil
+		}
+	}
+
+	if err := zp.Err(); err != nil {
+		return nil, fmt.Errorf("read public key: %w", err)
+	}
+
+	return nil, errors.New("read public key: no KEY record")
+}
@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadSIG0File(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		filename string
+	}{
+		{
+			desc:     "public key file",
+			filename: "Kexample.com.+013+21727.key",
+		},
+		{
+			desc:     "private key file",
+			filename: "Kexample.com.+013+21727.private",
+		},
+		{
+			desc:     "without extension",
+			filename: "Kexample.com.+013+21727",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			key, err := ReadSIG0File(filepath.Join("fixtures", test.filename))
+			require.NoError(t, err)
+
+			assert.Equal(t, "example.com.", key.Key.Hdr.Name)
+			assert.Equal(t, dns.ECDSAP256SHA256, key.Key.Algorithm)
+			assert.Equal(t, uint16(21727), key.Key.KeyTag())
+			assert.NotNil(t, key.PrivateKey)
+		})
+	}
+}
+
+func TestReadSIG0File_error(t *testing.T) {
+	_, err := ReadSIG0File(filepath.Join("fixtures", "sample.conf"))
+	require.Error(t, err)
+}
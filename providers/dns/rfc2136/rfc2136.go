@@ -11,6 +11,7 @@ import (
 	"github.com/go-acme/lego/v4/challenge"
 	"github.com/go-acme/lego/v4/challenge/dns01"
 	"github.com/go-acme/lego/v4/platform/config/env"
+	"github.com/go-acme/lego/v4/providers/dns/records"
 	"github.com/go-acme/lego/v4/providers/dns/rfc2136/internal"
 	"github.com/miekg/dns"
 )
@@ -20,6 +21,7 @@ const (
 	envNamespace = "RFC2136_"
 
 	EnvTSIGFile = envNamespace + "TSIG_FILE"
+	EnvSIG0File = envNamespace + "SIG0_FILE"
 
 	EnvTSIGKey       = envNamespace + "TSIG_KEY"
 	EnvTSIGSecret    = envNamespace + "TSIG_SECRET"
@@ -36,11 +38,16 @@ const (
 
 var _ challenge.ProviderTimeout = (*DNSProvider)(nil)
 
+var _ dns01.BatchProvider = (*DNSProvider)(nil)
+
+var _ records.Provider = (*DNSProvider)(nil)
+
 // Config is used to configure the creation of the DNSProvider.
 type Config struct {
 	Nameserver string
 
 	TSIGFile string
+	SIG0File string
 
 	TSIGAlgorithm string
 	TSIGKey       string
@@ -68,27 +75,26 @@ func NewDefaultConfig() *Config {
 // DNSProvider implements the challenge.Provider interface.
 type DNSProvider struct {
 	config *Config
+	sig0   *internal.SIG0Key
 }
 
 // NewDNSProvider returns a DNSProvider instance configured for rfc2136
 // dynamic update. Configured with environment variables:
 // RFC2136_NAMESERVER: Network address in the form "host" or "host:port".
+// If not set, the updates are sent to the primary nameserver of the zone (SOA MNAME).
 // RFC2136_TSIG_ALGORITHM: Defaults to hmac-md5.sig-alg.reg.int. (HMAC-MD5).
 // See https://github.com/miekg/dns/blob/master/tsig.go for supported values.
 // RFC2136_TSIG_KEY: Name of the secret key as defined in DNS server configuration.
 // RFC2136_TSIG_SECRET: Secret key payload.
 // RFC2136_PROPAGATION_TIMEOUT: DNS propagation timeout in time.ParseDuration format. (60s)
+// RFC2136_SIG0_FILE: Path to a SIG(0) key file generated with dnssec-keygen (instead of TSIG).
 // To disable TSIG authentication, leave the RFC2136_TSIG* variables unset.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.Get(EnvNameserver)
-	if err != nil {
-		return nil, fmt.Errorf("rfc2136: %w", err)
-	}
-
 	config := NewDefaultConfig()
-	config.Nameserver = values[EnvNameserver]
+	config.Nameserver = env.GetOrDefaultString(EnvNameserver, "")
 
 	config.TSIGFile = env.GetOrDefaultString(EnvTSIGFile, "")
+	config.SIG0File = env.GetOrDefaultString(EnvSIG0File, "")
 
 	config.TSIGKey = env.GetOrFile(EnvTSIGKey)
 	config.TSIGSecret = env.GetOrFile(EnvTSIGSecret)
@@ -102,10 +108,6 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 		return nil, errors.New("rfc2136: the configuration of the DNS provider is nil")
 	}
 
-	if config.Nameserver == "" {
-		return nil, errors.New("rfc2136: nameserver missing")
-	}
-
 	if config.TSIGFile != "" {
 		key, err := internal.ReadTSIGFile(config.TSIGFile)
 		if err != nil {
@@ -118,11 +120,13 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 	}
 
 	// Append the default DNS port if none is specified.
-	if _, _, err := net.SplitHostPort(config.Nameserver); err != nil {
-		if strings.Contains(err.Error(), "missing port") {
-			config.Nameserver = net.JoinHostPort(config.Nameserver, "53")
-		} else {
-			return nil, fmt.Errorf("rfc2136: %w", err)
+	if config.Nameserver != "" {
+		if _, _, err := net.SplitHostPort(config.Nameserver); err != nil {
+			if strings.Contains(err.Error(), "missing port") {
+				config.Nameserver = net.JoinHostPort(config.Nameserver, "53")
+			} else {
+				return nil, fmt.Errorf("rfc2136: %w", err)
+			}
 		}
 	}
 
@@ -148,7 +152,22 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 		return nil, fmt.Errorf("rfc2136: unsupported TSIG algorithm: %s", config.TSIGAlgorithm)
 	}
 
-	return &DNSProvider{config: config}, nil
+	provider := &DNSProvider{config: config}
+
+	if config.SIG0File != "" {
+		if config.TSIGKey != "" {
+			return nil, errors.New("rfc2136: TSIG and SIG(0) authentications cannot be used together")
+		}
+
+		key, err := internal.ReadSIG0File(config.SIG0File)
+		if err != nil {
+			return nil, fmt.Errorf("rfc2136: read SIG(0) file %s: %w", config.SIG0File, err)
+		}
+
+		provider.sig0 = key
+	}
+
+	return provider, nil
 }
 
 // Timeout returns the timeout and interval to use when checking for DNS propagation.
@@ -185,18 +204,145 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 	return nil
 }
 
+// PresentBatch creates the TXT records of several challenges with a single dynamic update per zone.
+// The zones are determined by using the configured nameserver, or the recursive nameservers.
+func (d *DNSProvider) PresentBatch(_ string, records []dns01.BatchRecord) error {
+	err := d.changeRecords("INSERT", records)
+	if err != nil {
+		return fmt.Errorf("rfc2136: failed to insert: %w", err)
+	}
+	return nil
+}
+
+// CleanUpBatch removes the TXT records of several challenges with a single dynamic update per zone.
+// The zones are determined by using the configured nameserver, or the recursive nameservers.
+func (d *DNSProvider) CleanUpBatch(_ string, records []dns01.BatchRecord) error {
+	err := d.changeRecords("REMOVE", records)
+	if err != nil {
+		return fmt.Errorf("rfc2136: failed to remove: %w", err)
+	}
+	return nil
+}
+
+// ListRecords returns the TXT, CAA, TLSA and CNAME records of the zone, by using a zone transfer (AXFR).
+func (d *DNSProvider) ListRecords(zone string) ([]records.Record, error) {
+	nameserver, err := d.nameserver(dns.Fqdn(zone))
+	if err != nil {
+		return nil, fmt.Errorf("rfc2136: %w", err)
+	}
+
+	m := new(dns.Msg)
+	m.SetAxfr(dns.Fqdn(zone))
+
+	t := &dns.Transfer{
+		DialTimeout:  d.config.DNSTimeout,
+		ReadTimeout:  d.config.DNSTimeout,
+		WriteTimeout: d.config.DNSTimeout,
+	}
+
+	// TSIG authentication / msg signing
+	if d.config.TSIGKey != "" && d.config.TSIGSecret != "" {
+		m.SetTsig(d.config.TSIGKey, d.config.TSIGAlgorithm, 300, time.Now().Unix())
+
+		t.TsigSecret = map[string]string{d.config.TSIGKey: d.config.TSIGSecret}
+	}
+
+	err = d.signSIG0(m)
+	if err != nil {
+		return nil, fmt.Errorf("rfc2136: %w", err)
+	}
+
+	envelopes, err := t.In(m, nameserver)
+	if err != nil {
+		return nil, fmt.Errorf("rfc2136: zone transfer failed: %w", err)
+	}
+
+	var result []records.Record
+
+	for envelope := range envelopes {
+		if envelope.Error != nil {
+			return nil, fmt.Errorf("rfc2136: zone transfer failed: %w", envelope.Error)
+		}
+
+		for _, rr := range envelope.RR {
+			if records.IsSupportedType(dns.TypeToString[rr.Header().Rrtype]) {
+				result = append(result, records.FromRR(rr))
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// UpsertRecord creates the record, or updates its TTL if the record already exists.
+func (d *DNSProvider) UpsertRecord(zone string, record records.Record) error {
+	rr, err := record.RR()
+	if err != nil {
+		return fmt.Errorf("rfc2136: %w", err)
+	}
+
+	err = d.update("UPSERT", dns.Fqdn(zone), []dns.RR{rr})
+	if err != nil {
+		return fmt.Errorf("rfc2136: failed to upsert: %w", err)
+	}
+	return nil
+}
+
+// DeleteRecord deletes the record.
+func (d *DNSProvider) DeleteRecord(zone string, record records.Record) error {
+	rr, err := record.RR()
+	if err != nil {
+		return fmt.Errorf("rfc2136: %w", err)
+	}
+
+	err = d.update("REMOVE", dns.Fqdn(zone), []dns.RR{rr})
+	if err != nil {
+		return fmt.Errorf("rfc2136: failed to delete: %w", err)
+	}
+	return nil
+}
+
 func (d *DNSProvider) changeRecord(action, fqdn, value string, ttl int) error {
-	// Find the zone for the given fqdn
-	zone, err := dns01.FindZoneByFqdnCustom(fqdn, []string{d.config.Nameserver})
+	zone, err := d.findZone(fqdn)
 	if err != nil {
 		return err
 	}
 
-	// Create RR
-	rr := new(dns.TXT)
-	rr.Hdr = dns.RR_Header{Name: fqdn, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: uint32(ttl)}
-	rr.Txt = []string{value}
-	rrs := []dns.RR{rr}
+	return d.update(action, zone, []dns.RR{newTXTRecord(fqdn, value, ttl)})
+}
+
+func (d *DNSProvider) changeRecords(action string, records []dns01.BatchRecord) error {
+	var zones []string
+	rrs := make(map[string][]dns.RR)
+
+	for _, record := range records {
+		zone, err := d.findZone(record.Info.EffectiveFQDN)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := rrs[zone]; !ok {
+			zones = append(zones, zone)
+		}
+
+		rrs[zone] = append(rrs[zone], newTXTRecord(record.Info.EffectiveFQDN, record.Info.Value, d.config.TTL))
+	}
+
+	for _, zone := range zones {
+		err := d.update(action, zone, rrs[zone])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *DNSProvider) update(action, zone string, rrs []dns.RR) error {
+	nameserver, err := d.nameserver(zone)
+	if err != nil {
+		return err
+	}
 
 	// Create dynamic update packet
 	m := new(dns.Msg)
@@ -204,7 +350,11 @@ func (d *DNSProvider) changeRecord(action, fqdn, value string, ttl int) error {
 	switch action {
 	case "INSERT":
 		// Always remove old challenge left over from who knows what.
-		m.RemoveRRset(rrs)
+		m.RemoveRRset(uniqueRRsets(rrs))
+		m.Insert(rrs)
+	case "UPSERT":
+		// Only the same record is removed, the other records of the RRset are kept.
+		m.Remove(rrs)
 		m.Insert(rrs)
 	case "REMOVE":
 		m.Remove(rrs)
@@ -223,8 +373,13 @@ func (d *DNSProvider) changeRecord(action, fqdn, value string, ttl int) error {
 		c.TsigSecret = map[string]string{d.config.TSIGKey: d.config.TSIGSecret}
 	}
 
+	err = d.signSIG0(m)
+	if err != nil {
+		return err
+	}
+
 	// Send the query
-	reply, _, err := c.Exchange(m, d.config.Nameserver)
+	reply, _, err := c.Exchange(m, nameserver)
 	if err != nil {
 		return fmt.Errorf("DNS update failed: %w", err)
 	}
@@ -234,3 +389,82 @@ func (d *DNSProvider) changeRecord(action, fqdn, value string, ttl int) error {
 
 	return nil
 }
+
+// findZone returns the zone of the FQDN.
+// The zone is determined by using the configured nameserver, or the recursive nameservers.
+func (d *DNSProvider) findZone(fqdn string) (string, error) {
+	if d.config.Nameserver == "" {
+		return dns01.FindZoneByFqdn(fqdn)
+	}
+
+	return dns01.FindZoneByFqdnCustom(fqdn, []string{d.config.Nameserver})
+}
+
+// nameserver returns the address of the nameserver receiving the updates of the zone:
+// the configured nameserver, or the primary nameserver of the zone (SOA MNAME).
+func (d *DNSProvider) nameserver(zone string) (string, error) {
+	if d.config.Nameserver != "" {
+		return d.config.Nameserver, nil
+	}
+
+	primary, err := dns01.FindPrimaryNsByFqdn(zone)
+	if err != nil {
+		return "", fmt.Errorf("could not find the primary nameserver of %s: %w", zone, err)
+	}
+
+	return net.JoinHostPort(dns01.UnFqdn(primary), "53"), nil
+}
+
+// signSIG0 signs the message with the SIG(0) key (RFC 2931), if any.
+func (d *DNSProvider) signSIG0(m *dns.Msg) error {
+	if d.sig0 == nil {
+		return nil
+	}
+
+	now := time.Now().UTC()
+
+	sig := &dns.SIG{RRSIG: dns.RRSIG{
+		Algorithm:  d.sig0.Key.Algorithm,
+		SignerName: d.sig0.Key.Hdr.Name,
+		KeyTag:     d.sig0.Key.KeyTag(),
+		// Allows a clock skew between lego and the nameserver.
+		Inception:  uint32(now.Add(-5 * time.Minute).Unix()),
+		Expiration: uint32(now.Add(5 * time.Minute).Unix()),
+	}}
+
+	_, err := sig.Sign(d.sig0.PrivateKey, m)
+	if err != nil {
+		return fmt.Errorf("SIG(0) signature: %w", err)
+	}
+
+	m.Extra = append(m.Extra, sig)
+
+	return nil
+}
+
+func newTXTRecord(fqdn, value string, ttl int) dns.RR {
+	rr := new(dns.TXT)
+	rr.Hdr = dns.RR_Header{Name: fqdn, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: uint32(ttl)}
+	rr.Txt = []string{value}
+
+	return rr
+}
+
+// uniqueRRsets returns one RR by RRset (name and type).
+func uniqueRRsets(rrs []dns.RR) []dns.RR {
+	var unique []dns.RR
+
+	seen := make(map[string]struct{})
+
+	for _, rr := range rrs {
+		key := strings.ToLower(rr.Header().Name) + "/" + dns.TypeToString[rr.Header().Rrtype]
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		seen[key] = struct{}{}
+		unique = append(unique, rr)
+	}
+
+	return unique
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/go-acme/lego/v4/challenge/dns01"
 	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/go-acme/lego/v4/providers/dns/records"
 	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -31,6 +32,7 @@ const envDomain = envNamespace + "DOMAIN"
 
 var envTest = tester.NewEnvTest(
 	EnvTSIGFile,
+	EnvSIG0File,
 	EnvTSIGKey,
 	EnvTSIGSecret,
 	EnvTSIGAlgorithm,
@@ -51,11 +53,10 @@ func TestNewDNSProvider(t *testing.T) {
 			},
 		},
 		{
-			desc: "missing nameserver",
+			desc: "without nameserver (discovery)",
 			envVars: map[string]string{
 				EnvNameserver: "",
 			},
-			expected: "rfc2136: some credentials information are missing: RFC2136_NAMESERVER",
 		},
 		{
 			desc: "invalid algorithm",
@@ -82,6 +83,22 @@ func TestNewDNSProvider(t *testing.T) {
 			},
 			expected: "rfc2136: read TSIG file ./internal/fixtures/invalid_key.conf: invalid key line: key {",
 		},
+		{
+			desc: "valid SIG(0) file",
+			envVars: map[string]string{
+				EnvNameserver: "example.com",
+				EnvSIG0File:   "./internal/fixtures/Kexample.com.+013+21727.key",
+			},
+		},
+		{
+			desc: "TSIG and SIG(0)",
+			envVars: map[string]string{
+				EnvNameserver: "example.com",
+				EnvTSIGFile:   "./internal/fixtures/sample.conf",
+				EnvSIG0File:   "./internal/fixtures/Kexample.com.+013+21727.key",
+			},
+			expected: "rfc2136: TSIG and SIG(0) authentications cannot be used together",
+		},
 	}
 
 	for _, test := range testCases {
@@ -110,6 +127,7 @@ func TestNewDNSProviderConfig(t *testing.T) {
 		expected      string
 		nameserver    string
 		tsigFile      string
+		sig0File      string
 		tsigAlgorithm string
 		tsigKey       string
 		tsigSecret    string
@@ -119,8 +137,7 @@ func TestNewDNSProviderConfig(t *testing.T) {
 			nameserver: "example.com",
 		},
 		{
-			desc:     "missing nameserver",
-			expected: "rfc2136: nameserver missing",
+			desc: "without nameserver (discovery)",
 		},
 		{
 			desc:          "invalid algorithm",
@@ -139,6 +156,17 @@ func TestNewDNSProviderConfig(t *testing.T) {
 			tsigFile:   "./internal/fixtures/invalid_key.conf",
 			expected:   "rfc2136: read TSIG file ./internal/fixtures/invalid_key.conf: invalid key line: key {",
 		},
+		{
+			desc:       "valid SIG(0) file",
+			nameserver: "example.com",
+			sig0File:   "./internal/fixtures/Kexample.com.+013+21727.private",
+		},
+		{
+			desc:       "invalid SIG(0) file",
+			nameserver: "example.com",
+			sig0File:   "./internal/fixtures/missing.key",
+			expected:   "rfc2136: read SIG(0) file ./internal/fixtures/missing.key: open file: open ./internal/fixtures/missing.key: no such file or directory",
+		},
 	}
 
 	for _, test := range testCases {
@@ -146,6 +174,7 @@ func TestNewDNSProviderConfig(t *testing.T) {
 			config := NewDefaultConfig()
 			config.Nameserver = test.nameserver
 			config.TSIGFile = test.tsigFile
+			config.SIG0File = test.sig0File
 			config.TSIGAlgorithm = test.tsigAlgorithm
 			config.TSIGKey = test.tsigKey
 			config.TSIGSecret = test.tsigSecret
@@ -247,6 +276,45 @@ func TestTsigClient(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestSIG0Client(t *testing.T) {
+	reqChan := make(chan *dns.Msg, 10)
+
+	dns01.ClearFqdnCache()
+	dns.HandleFunc(fakeZone, serverHandlerPassBackRequest(reqChan))
+	defer dns.HandleRemove(fakeZone)
+
+	server, addr, err := runLocalDNSTestServer(false)
+	require.NoError(t, err, "Failed to start test server")
+	defer func() { _ = server.Shutdown() }()
+
+	config := NewDefaultConfig()
+	config.Nameserver = addr
+	config.SIG0File = "./internal/fixtures/Kexample.com.+013+21727.key"
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	err = provider.Present(fakeDomain, "", fakeKeyAuth)
+	require.NoError(t, err)
+
+	rcvMsg := <-reqChan
+
+	require.NotEmpty(t, rcvMsg.Extra)
+
+	sig, ok := rcvMsg.Extra[len(rcvMsg.Extra)-1].(*dns.SIG)
+	require.True(t, ok, "the last additional record must be a SIG record")
+
+	assert.Equal(t, "example.com.", sig.SignerName)
+	assert.Equal(t, uint16(21727), sig.KeyTag)
+
+	buf, err := rcvMsg.Pack()
+	require.NoError(t, err)
+
+	key := &dns.KEY{DNSKEY: *provider.sig0.Key}
+
+	require.NoError(t, sig.Verify(key, buf))
+}
+
 func TestValidUpdatePacket(t *testing.T) {
 	reqChan := make(chan *dns.Msg, 10)
 
@@ -294,6 +362,139 @@ func TestValidUpdatePacket(t *testing.T) {
 	}
 }
 
+func TestValidBatchUpdatePacket(t *testing.T) {
+	reqChan := make(chan *dns.Msg, 10)
+
+	dns01.ClearFqdnCache()
+	dns.HandleFunc(fakeZone, serverHandlerPassBackRequest(reqChan))
+	defer dns.HandleRemove(fakeZone)
+
+	server, addr, err := runLocalDNSTestServer(false)
+	require.NoError(t, err, "Failed to start test server")
+	defer func() { _ = server.Shutdown() }()
+
+	txtRR1, _ := dns.NewRR(fmt.Sprintf("%s %d IN TXT %s", fakeFqdn, fakeTTL, "value1"))
+	txtRR2, _ := dns.NewRR(fmt.Sprintf("%s %d IN TXT %s", fakeFqdn, fakeTTL, "value2"))
+	txtRR3, _ := dns.NewRR(fmt.Sprintf("_acme-challenge.example.com. %d IN TXT %s", fakeTTL, "value3"))
+
+	m := new(dns.Msg)
+	m.SetUpdate(fakeZone)
+	m.RemoveRRset([]dns.RR{txtRR1, txtRR3})
+	m.Insert([]dns.RR{txtRR1, txtRR2, txtRR3})
+	expectStr := m.String()
+
+	expect, err := m.Pack()
+	require.NoError(t, err, "error packing")
+
+	config := NewDefaultConfig()
+	config.Nameserver = addr
+	config.TTL = fakeTTL
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	err = provider.PresentBatch(fakeZone, []dns01.BatchRecord{
+		{Domain: fakeDomain, Info: dns01.ChallengeInfo{EffectiveFQDN: fakeFqdn, Value: "value1"}},
+		{Domain: fakeDomain, Info: dns01.ChallengeInfo{EffectiveFQDN: fakeFqdn, Value: "value2"}},
+		{Domain: "example.com", Info: dns01.ChallengeInfo{EffectiveFQDN: "_acme-challenge.example.com.", Value: "value3"}},
+	})
+	require.NoError(t, err)
+
+	rcvMsg := <-reqChan
+	rcvMsg.Id = m.Id
+
+	actual, err := rcvMsg.Pack()
+	require.NoError(t, err, "error packing")
+
+	if !bytes.Equal(actual, expect) {
+		tmp := new(dns.Msg)
+		if err := tmp.Unpack(actual); err != nil {
+			t.Fatalf("Error unpacking actual msg: %v", err)
+		}
+		t.Errorf("Expected msg:\n%s", expectStr)
+		t.Errorf("Actual msg:\n%v", tmp)
+	}
+
+	assert.Empty(t, reqChan, "Expected a single update")
+}
+
+func TestUpsertRecordPacket(t *testing.T) {
+	reqChan := make(chan *dns.Msg, 10)
+
+	dns.HandleFunc(fakeZone, serverHandlerPassBackRequest(reqChan))
+	defer dns.HandleRemove(fakeZone)
+
+	server, addr, err := runLocalDNSTestServer(false)
+	require.NoError(t, err, "Failed to start test server")
+	defer func() { _ = server.Shutdown() }()
+
+	record := records.Record{
+		FQDN:  "_443._tcp.www.example.com.",
+		Type:  records.TypeTLSA,
+		Value: "3 1 1 0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		TTL:   fakeTTL,
+	}
+
+	tlsaRR, _ := dns.NewRR(fmt.Sprintf("%s %d IN TLSA %s", record.FQDN, record.TTL, record.Value))
+	rrs := []dns.RR{tlsaRR}
+	m := new(dns.Msg)
+	m.SetUpdate(fakeZone)
+	m.Remove(rrs)
+	m.Insert(rrs)
+	expectStr := m.String()
+
+	config := NewDefaultConfig()
+	config.Nameserver = addr
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	err = provider.UpsertRecord("example.com", record)
+	require.NoError(t, err)
+
+	rcvMsg := <-reqChan
+	rcvMsg.Id = m.Id
+
+	assert.Equal(t, expectStr, rcvMsg.String())
+}
+
+func TestListRecords(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		soaRR, _ := dns.NewRR(fmt.Sprintf("%s %d IN SOA ns1.%s admin.%s 2016022801 28800 7200 2419200 1200", fakeZone, fakeTTL, fakeZone, fakeZone))
+		aRR, _ := dns.NewRR("www.example.com. 300 IN A 192.0.2.1")
+		txtRR, _ := dns.NewRR(`example.com. 300 IN TXT "v=spf1 -all"`)
+		caaRR, _ := dns.NewRR(`example.com. 3600 IN CAA 0 issue "letsencrypt.org"`)
+
+		m := new(dns.Msg)
+		m.SetReply(req)
+		m.Answer = []dns.RR{soaRR, aRR, txtRR, caaRR, soaRR}
+		_ = w.WriteMsg(m)
+	})
+
+	server := &dns.Server{Listener: listener, Handler: handler}
+	go func() { _ = server.ActivateAndServe() }()
+	defer func() { _ = server.Shutdown() }()
+
+	config := NewDefaultConfig()
+	config.Nameserver = listener.Addr().String()
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	result, err := provider.ListRecords("example.com")
+	require.NoError(t, err)
+
+	expected := []records.Record{
+		{FQDN: "example.com.", Type: records.TypeTXT, Value: `"v=spf1 -all"`, TTL: 300},
+		{FQDN: "example.com.", Type: records.TypeCAA, Value: `0 issue "letsencrypt.org"`, TTL: 3600},
+	}
+
+	assert.Equal(t, expected, result)
+}
+
 func runLocalDNSTestServer(tsig bool) (*dns.Server, string, error) {
 	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
 	if err != nil {
@@ -37,3 +37,27 @@ const GetChangeResponse = `<?xml version="1.0" encoding="UTF-8"?>
       <SubmittedAt>2016-02-10T01:36:41.958Z</SubmittedAt>
    </ChangeInfo>
 </GetChangeResponse>`
+
+const ListHostedZonesByNameEmptyResponse = `<?xml version="1.0" encoding="UTF-8"?>
+<ListHostedZonesByNameResponse xmlns="https://route53.amazonaws.com/doc/2013-04-01/">
+   <HostedZones/>
+   <IsTruncated>false</IsTruncated>
+   <MaxItems>1</MaxItems>
+</ListHostedZonesByNameResponse>`
+
+const ListResourceRecordSetsEmptyResponse = `<?xml version="1.0" encoding="UTF-8"?>
+<ListResourceRecordSetsResponse xmlns="https://route53.amazonaws.com/doc/2013-04-01/">
+   <ResourceRecordSets/>
+   <IsTruncated>false</IsTruncated>
+   <MaxItems>1</MaxItems>
+</ListResourceRecordSetsResponse>`
+
+const AccessDeniedResponse = `<?xml version="1.0" encoding="UTF-8"?>
+<ErrorResponse xmlns="https://route53.amazonaws.com/doc/2013-04-01/">
+   <Error>
+      <Type>Sender</Type>
+      <Code>AccessDenied</Code>
+      <Message>User is not authorized to perform: route53:ListResourceRecordSets</Message>
+   </Error>
+   <RequestId>ef0a8ef5-3a2e-4f0d-8c4f-3b1a9d1e9c5e</RequestId>
+</ErrorResponse>`
@@ -0,0 +1,195 @@
+package route53
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/go-acme/lego/v4/providers/dns/internal/ptr"
+	"github.com/go-acme/lego/v4/providers/dns/records"
+)
+
+var _ records.Provider = (*DNSProvider)(nil)
+
+// ListRecords returns the TXT, CAA, TLSA and CNAME records of the zone.
+func (d *DNSProvider) ListRecords(zone string) ([]records.Record, error) {
+	ctx := context.Background()
+
+	hostedZoneID, err := d.findHostedZoneID(ctx, staticZone(dns01.ToFqdn(zone)))
+	if err != nil {
+		return nil, fmt.Errorf("route53: failed to determine hosted zone ID: %w", err)
+	}
+
+	input := &route53.ListResourceRecordSetsInput{HostedZoneId: aws.String(hostedZoneID)}
+
+	var result []records.Record
+
+	for {
+		resp, err := d.client.ListResourceRecordSets(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("route53: failed to list records: %w", err)
+		}
+
+		for _, recordSet := range resp.ResourceRecordSets {
+			if !records.IsSupportedType(string(recordSet.Type)) {
+				continue
+			}
+
+			for _, rr := range recordSet.ResourceRecords {
+				record, err := toRecord(recordSet, rr)
+				if err != nil {
+					return nil, fmt.Errorf("route53: %w", err)
+				}
+
+				result = append(result, record)
+			}
+		}
+
+		if !resp.IsTruncated {
+			return result, nil
+		}
+
+		input.StartRecordName = resp.NextRecordName
+		input.StartRecordType = resp.NextRecordType
+		input.StartRecordIdentifier = resp.NextRecordIdentifier
+	}
+}
+
+// UpsertRecord creates the record, or updates its TTL if the record already exists.
+// The TTL applies to all the records with the same FQDN and type (resource record set).
+func (d *DNSProvider) UpsertRecord(zone string, record records.Record) error {
+	record, err := record.Normalize()
+	if err != nil {
+		return fmt.Errorf("route53: %w", err)
+	}
+
+	ctx := context.Background()
+
+	hostedZoneID, recordSet, err := d.findRecordSet(ctx, zone, record)
+	if err != nil {
+		return err
+	}
+
+	values := []awstypes.ResourceRecord{{Value: aws.String(record.Value)}}
+
+	if recordSet != nil {
+		for _, rr := range recordSet.ResourceRecords {
+			existing, errR := toRecord(*recordSet, rr)
+			if errR != nil {
+				return fmt.Errorf("route53: %w", errR)
+			}
+
+			if records.Same(record, existing) {
+				if existing.TTL == record.TTL {
+					return nil
+				}
+
+				continue
+			}
+
+			values = append(values, rr)
+		}
+	}
+
+	err = d.changeRecord(ctx, awstypes.ChangeActionUpsert, hostedZoneID, &awstypes.ResourceRecordSet{
+		Name:            aws.String(record.FQDN),
+		Type:            awstypes.RRType(record.Type),
+		TTL:             aws.Int64(int64(record.TTL)),
+		ResourceRecords: values,
+	})
+	if err != nil {
+		return fmt.Errorf("route53: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteRecord deletes the record.
+func (d *DNSProvider) DeleteRecord(zone string, record records.Record) error {
+	ctx := context.Background()
+
+	hostedZoneID, recordSet, err := d.findRecordSet(ctx, zone, record)
+	if err != nil {
+		return err
+	}
+
+	if recordSet == nil {
+		return nil
+	}
+
+	var values []awstypes.ResourceRecord
+
+	for _, rr := range recordSet.ResourceRecords {
+		existing, errR := toRecord(*recordSet, rr)
+		if errR != nil {
+			return fmt.Errorf("route53: %w", errR)
+		}
+
+		if !records.Same(record, existing) {
+			values = append(values, rr)
+		}
+	}
+
+	if len(values) == len(recordSet.ResourceRecords) {
+		return nil
+	}
+
+	// The deletion of a resource record set requires the exact values and TTL of the set.
+	if len(values) == 0 {
+		err = d.changeRecord(ctx, awstypes.ChangeActionDelete, hostedZoneID, recordSet)
+	} else {
+		err = d.changeRecord(ctx, awstypes.ChangeActionUpsert, hostedZoneID, &awstypes.ResourceRecordSet{
+			Name:            recordSet.Name,
+			Type:            recordSet.Type,
+			TTL:             recordSet.TTL,
+			ResourceRecords: values,
+		})
+	}
+
+	if err != nil {
+		return fmt.Errorf("route53: %w", err)
+	}
+
+	return nil
+}
+
+// findRecordSet returns the hosted zone ID, and the resource record set with the same FQDN and type as the record.
+func (d *DNSProvider) findRecordSet(ctx context.Context, zone string, record records.Record) (string, *awstypes.ResourceRecordSet, error) {
+	hostedZoneID, err := d.findHostedZoneID(ctx, staticZone(dns01.ToFqdn(zone)))
+	if err != nil {
+		return "", nil, fmt.Errorf("route53: failed to determine hosted zone ID: %w", err)
+	}
+
+	rrType := strings.ToUpper(record.Type)
+
+	resp, err := d.client.ListResourceRecordSets(ctx, &route53.ListResourceRecordSetsInput{
+		HostedZoneId:    aws.String(hostedZoneID),
+		StartRecordName: aws.String(dns01.ToFqdn(record.FQDN)),
+		StartRecordType: awstypes.RRType(rrType),
+		MaxItems:        aws.Int32(1),
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("route53: failed to list records: %w", err)
+	}
+
+	for _, recordSet := range resp.ResourceRecordSets {
+		if strings.EqualFold(ptr.Deref(recordSet.Name), dns01.ToFqdn(record.FQDN)) && string(recordSet.Type) == rrType {
+			return hostedZoneID, &recordSet, nil
+		}
+	}
+
+	return hostedZoneID, nil, nil
+}
+
+func toRecord(recordSet awstypes.ResourceRecordSet, rr awstypes.ResourceRecord) (records.Record, error) {
+	return records.Record{
+		FQDN:  ptr.Deref(recordSet.Name),
+		Type:  string(recordSet.Type),
+		Value: ptr.Deref(rr.Value),
+		TTL:   int(ptr.Deref(recordSet.TTL)),
+	}.Normalize()
+}
@@ -0,0 +1,152 @@
+package route53
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-acme/lego/v4/providers/dns/records"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const listResourceRecordSetsResponse = `<?xml version="1.0" encoding="UTF-8"?>
+<ListResourceRecordSetsResponse xmlns="https://route53.amazonaws.com/doc/2013-04-01/">
+   <ResourceRecordSets>
+      <ResourceRecordSet>
+         <Name>example.com.</Name>
+         <Type>A</Type>
+         <TTL>300</TTL>
+         <ResourceRecords>
+            <ResourceRecord><Value>192.0.2.1</Value></ResourceRecord>
+         </ResourceRecords>
+      </ResourceRecordSet>
+      <ResourceRecordSet>
+         <Name>example.com.</Name>
+         <Type>CAA</Type>
+         <TTL>3600</TTL>
+         <ResourceRecords>
+            <ResourceRecord><Value>0 issue "letsencrypt.org"</Value></ResourceRecord>
+            <ResourceRecord><Value>0 iodef "mailto:admin@example.com"</Value></ResourceRecord>
+         </ResourceRecords>
+      </ResourceRecordSet>
+      <ResourceRecordSet>
+         <Name>_443._tcp.example.com.</Name>
+         <Type>TLSA</Type>
+         <TTL>3600</TTL>
+         <ResourceRecords>
+            <ResourceRecord><Value>3 1 1 ABCDEF</Value></ResourceRecord>
+         </ResourceRecords>
+      </ResourceRecordSet>
+      <ResourceRecordSet>
+         <Name>www.example.com.</Name>
+         <Type>CNAME</Type>
+         <TTL>300</TTL>
+         <ResourceRecords>
+            <ResourceRecord><Value>example.com</Value></ResourceRecord>
+         </ResourceRecords>
+      </ResourceRecordSet>
+   </ResourceRecordSets>
+   <IsTruncated>false</IsTruncated>
+   <MaxItems>100</MaxItems>
+</ListResourceRecordSetsResponse>`
+
+func setupRecordsTest(t *testing.T) (*DNSProvider, *[]string) {
+	t.Helper()
+
+	var changes []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/xml")
+
+		switch {
+		case req.URL.Path == "/2013-04-01/hostedzonesbyname":
+			_, _ = io.WriteString(rw, ListHostedZonesByNameResponse)
+
+		case req.URL.Path == "/2013-04-01/hostedzone/ABCDEFG/rrset" && req.Method == http.MethodGet:
+			_, _ = io.WriteString(rw, listResourceRecordSetsResponse)
+
+		case req.URL.Path == "/2013-04-01/hostedzone/ABCDEFG/rrset" && req.Method == http.MethodPost:
+			body, _ := io.ReadAll(req.Body)
+			changes = append(changes, string(body))
+
+			_, _ = io.WriteString(rw, ChangeResourceRecordSetsResponse)
+
+		case req.URL.Path == "/2013-04-01/change/123456":
+			_, _ = io.WriteString(rw, GetChangeResponse)
+
+		default:
+			http.NotFound(rw, req)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return makeTestProvider(t, server.URL), &changes
+}
+
+func TestDNSProvider_ListRecords(t *testing.T) {
+	provider, _ := setupRecordsTest(t)
+
+	result, err := provider.ListRecords("example.com.")
+	require.NoError(t, err)
+
+	expected := []records.Record{
+		{FQDN: "example.com.", Type: records.TypeCAA, Value: `0 issue "letsencrypt.org"`, TTL: 3600},
+		{FQDN: "example.com.", Type: records.TypeCAA, Value: `0 iodef "mailto:admin@example.com"`, TTL: 3600},
+		{FQDN: "_443._tcp.example.com.", Type: records.TypeTLSA, Value: "3 1 1 abcdef", TTL: 3600},
+		{FQDN: "www.example.com.", Type: records.TypeCNAME, Value: "example.com.", TTL: 300},
+	}
+
+	assert.Equal(t, expected, result)
+}
+
+func TestDNSProvider_UpsertRecord(t *testing.T) {
+	provider, changes := setupRecordsTest(t)
+
+	// Already exists.
+	err := provider.UpsertRecord("example.com.", records.NewCAA("example.com.", 0, "issue", "letsencrypt.org", 3600))
+	require.NoError(t, err)
+
+	require.Empty(t, *changes)
+
+	err = provider.UpsertRecord("example.com.", records.NewCAA("example.com.", 0, "issuewild", ";", 3600))
+	require.NoError(t, err)
+
+	require.Len(t, *changes, 1)
+
+	change := (*changes)[0]
+	assert.Contains(t, change, "<Action>UPSERT</Action>")
+	assert.Equal(t, 3, strings.Count(change, "<ResourceRecord>"))
+	assert.Contains(t, change, "<Value>0 issuewild &#34;;&#34;</Value>")
+	assert.Contains(t, change, "<Value>0 issue &#34;letsencrypt.org&#34;</Value>")
+}
+
+func TestDNSProvider_DeleteRecord(t *testing.T) {
+	provider, changes := setupRecordsTest(t)
+
+	// Doesn't exist.
+	err := provider.DeleteRecord("example.com.", records.NewCAA("example.com.", 0, "issue", "example.org", 3600))
+	require.NoError(t, err)
+
+	require.Empty(t, *changes)
+
+	// Other records in the record set.
+	err = provider.DeleteRecord("example.com.", records.NewCAA("example.com.", 0, "issue", "letsencrypt.org", 3600))
+	require.NoError(t, err)
+
+	// Last record of the record set.
+	err = provider.DeleteRecord("example.com.", records.Record{FQDN: "_443._tcp.example.com.", Type: records.TypeTLSA, Value: "3 1 1 abcdef"})
+	require.NoError(t, err)
+
+	require.Len(t, *changes, 2)
+
+	assert.Contains(t, (*changes)[0], "<Action>UPSERT</Action>")
+	assert.Equal(t, 1, strings.Count((*changes)[0], "<ResourceRecord>"))
+	assert.Contains(t, (*changes)[0], "<Value>0 iodef &#34;mailto:admin@example.com&#34;</Value>")
+
+	assert.Contains(t, (*changes)[1], "<Action>DELETE</Action>")
+	assert.Contains(t, (*changes)[1], "<Value>3 1 1 ABCDEF</Value>")
+	assert.Contains(t, (*changes)[1], "<TTL>3600</TTL>")
+}
@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -45,6 +47,12 @@ const (
 
 var _ challenge.ProviderTimeout = (*DNSProvider)(nil)
 
+var _ challenge.ProviderValidator = (*DNSProvider)(nil)
+
+var _ dns01.BatchProvider = (*DNSProvider)(nil)
+
+var _ dns01.PropagationProvider = (*DNSProvider)(nil)
+
 // Config is used to configure the creation of the DNSProvider.
 type Config struct {
 	// Static credential chain.
@@ -88,6 +96,10 @@ func NewDefaultConfig() *Config {
 type DNSProvider struct {
 	client *route53.Client
 	config *Config
+
+	// the IDs of the changes of the TXT records, by FQDN and value.
+	changeIDs   map[string]*string
+	changeIDsMu sync.Mutex
 }
 
 // NewDNSProvider returns a DNSProvider instance configured for the AWS Route 53 service.
@@ -133,6 +145,36 @@ func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
 	return d.config.PropagationTimeout, d.config.PollingInterval
 }
 
+// Validate checks the credentials and the access to the hosted zones.
+// If a hosted zone ID is defined, the access to the records of this hosted zone is checked.
+// Only the API calls already required by the DNS challenge are used (see the IAM policy examples).
+func (d *DNSProvider) Validate() error {
+	ctx := context.Background()
+
+	if d.config.HostedZoneID != "" {
+		_, err := d.client.ListResourceRecordSets(ctx, &route53.ListResourceRecordSetsInput{
+			HostedZoneId: aws.String(d.config.HostedZoneID),
+			MaxItems:     aws.Int32(1),
+		})
+		if err != nil {
+			return fmt.Errorf("route53: list resource record sets of the hosted zone %s: %w", d.config.HostedZoneID, err)
+		}
+
+		return nil
+	}
+
+	resp, err := d.client.ListHostedZonesByName(ctx, &route53.ListHostedZonesByNameInput{MaxItems: aws.Int32(1)})
+	if err != nil {
+		return fmt.Errorf("route53: list hosted zones by name: %w", err)
+	}
+
+	if len(resp.HostedZones) == 0 {
+		return errors.New("route53: no hosted zone is accessible with these credentials")
+	}
+
+	return nil
+}
+
 // Present creates a TXT record using the specified parameters.
 func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 	ctx := context.Background()
@@ -226,15 +268,146 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 	return nil
 }
 
+// PresentBatch creates the TXT records of several challenges of a zone with a single change batch.
+func (d *DNSProvider) PresentBatch(zone string, records []dns01.BatchRecord) error {
+	ctx := context.Background()
+
+	hostedZoneID, err := d.findHostedZoneID(ctx, staticZone(zone))
+	if err != nil {
+		return fmt.Errorf("route53: failed to determine hosted zone ID: %w", err)
+	}
+
+	var fqdns []string
+	values := make(map[string][]awstypes.ResourceRecord)
+
+	for _, record := range records {
+		fqdn := record.Info.EffectiveFQDN
+
+		if _, ok := values[fqdn]; !ok {
+			existingRecords, errR := d.getExistingRecordSets(ctx, hostedZoneID, fqdn)
+			if errR != nil {
+				return fmt.Errorf("route53: %w", errR)
+			}
+
+			fqdns = append(fqdns, fqdn)
+			values[fqdn] = existingRecords
+		}
+
+		realValue := `"` + record.Info.Value + `"`
+
+		if !slices.ContainsFunc(values[fqdn], func(r awstypes.ResourceRecord) bool { return ptr.Deref(r.Value) == realValue }) {
+			values[fqdn] = append(values[fqdn], awstypes.ResourceRecord{Value: aws.String(realValue)})
+		}
+	}
+
+	var changes []awstypes.Change
+
+	for _, fqdn := range fqdns {
+		changes = append(changes, awstypes.Change{
+			Action: awstypes.ChangeActionUpsert,
+			ResourceRecordSet: &awstypes.ResourceRecordSet{
+				Name:            aws.String(fqdn),
+				Type:            "TXT",
+				TTL:             aws.Int64(int64(d.config.TTL)),
+				ResourceRecords: values[fqdn],
+			},
+		})
+	}
+
+	err = d.changeRecords(ctx, hostedZoneID, changes)
+	if err != nil {
+		return fmt.Errorf("route53: %w", err)
+	}
+
+	return nil
+}
+
+// CleanUpBatch removes the TXT records of several challenges of a zone with a single change batch.
+func (d *DNSProvider) CleanUpBatch(zone string, records []dns01.BatchRecord) error {
+	ctx := context.Background()
+
+	hostedZoneID, err := d.findHostedZoneID(ctx, staticZone(zone))
+	if err != nil {
+		return fmt.Errorf("failed to determine Route 53 hosted zone ID: %w", err)
+	}
+
+	var fqdns []string
+	legoValues := make(map[string][]string)
+
+	for _, record := range records {
+		fqdn := record.Info.EffectiveFQDN
+
+		if _, ok := legoValues[fqdn]; !ok {
+			fqdns = append(fqdns, fqdn)
+		}
+
+		legoValues[fqdn] = append(legoValues[fqdn], `"`+record.Info.Value+`"`)
+	}
+
+	var changes []awstypes.Change
+
+	for _, fqdn := range fqdns {
+		existingRecords, errR := d.getExistingRecordSets(ctx, hostedZoneID, fqdn)
+		if errR != nil {
+			return fmt.Errorf("route53: %w", errR)
+		}
+
+		if len(existingRecords) == 0 {
+			continue
+		}
+
+		var nonLegoRecords []awstypes.ResourceRecord
+		for _, record := range existingRecords {
+			if !slices.Contains(legoValues[fqdn], ptr.Deref(record.Value)) {
+				nonLegoRecords = append(nonLegoRecords, record)
+			}
+		}
+
+		change := awstypes.Change{
+			Action: awstypes.ChangeActionUpsert,
+			ResourceRecordSet: &awstypes.ResourceRecordSet{
+				Name:            aws.String(fqdn),
+				Type:            "TXT",
+				TTL:             aws.Int64(int64(d.config.TTL)),
+				ResourceRecords: nonLegoRecords,
+			},
+		}
+
+		// If the records are only records created by lego.
+		if len(nonLegoRecords) == 0 {
+			change.Action = awstypes.ChangeActionDelete
+
+			change.ResourceRecordSet.ResourceRecords = existingRecords
+		}
+
+		changes = append(changes, change)
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	err = d.changeRecords(ctx, hostedZoneID, changes)
+	if err != nil {
+		return fmt.Errorf("route53: %w", err)
+	}
+
+	return nil
+}
+
 func (d *DNSProvider) changeRecord(ctx context.Context, action awstypes.ChangeAction, hostedZoneID string, recordSet *awstypes.ResourceRecordSet) error {
+	return d.changeRecords(ctx, hostedZoneID, []awstypes.Change{{
+		Action:            action,
+		ResourceRecordSet: recordSet,
+	}})
+}
+
+func (d *DNSProvider) changeRecords(ctx context.Context, hostedZoneID string, changes []awstypes.Change) error {
 	recordSetInput := &route53.ChangeResourceRecordSetsInput{
 		HostedZoneId: aws.String(hostedZoneID),
 		ChangeBatch: &awstypes.ChangeBatch{
 			Comment: aws.String("Managed by Lego"),
-			Changes: []awstypes.Change{{
-				Action:            action,
-				ResourceRecordSet: recordSet,
-			}},
+			Changes: changes,
 		},
 	}
 
@@ -245,6 +418,8 @@ func (d *DNSProvider) changeRecord(ctx context.Context, action awstypes.ChangeAc
 
 	changeID := resp.ChangeInfo.Id
 
+	d.storeChangeID(changes, changeID)
+
 	if d.config.WaitForRecordSetsChanged {
 		return wait.For("route53", d.config.PropagationTimeout, d.config.PollingInterval, func() (bool, error) {
 			resp, err := d.client.GetChange(ctx, &route53.GetChangeInput{Id: changeID})
@@ -263,6 +438,58 @@ func (d *DNSProvider) changeRecord(ctx context.Context, action awstypes.ChangeAc
 	return nil
 }
 
+// IsPropagated returns true if the change of the TXT record is applied to all the Route 53 authoritative nameservers (INSYNC).
+func (d *DNSProvider) IsPropagated(fqdn, value string) (bool, error) {
+	d.changeIDsMu.Lock()
+	changeID, ok := d.changeIDs[changeKey(fqdn, `"`+value+`"`)]
+	d.changeIDsMu.Unlock()
+
+	if !ok {
+		return false, fmt.Errorf("route53: unknown change for the TXT record %s", fqdn)
+	}
+
+	resp, err := d.client.GetChange(context.Background(), &route53.GetChangeInput{Id: changeID})
+	if err != nil {
+		return false, fmt.Errorf("route53: failed to query change status: %w", err)
+	}
+
+	return resp.ChangeInfo.Status == awstypes.ChangeStatusInsync, nil
+}
+
+// storeChangeID stores the ID of the change of the TXT records created or updated by the changes,
+// and forgets the IDs of the deleted TXT records.
+func (d *DNSProvider) storeChangeID(changes []awstypes.Change, changeID *string) {
+	d.changeIDsMu.Lock()
+	defer d.changeIDsMu.Unlock()
+
+	if d.changeIDs == nil {
+		d.changeIDs = make(map[string]*string)
+	}
+
+	for _, change := range changes {
+		recordSet := change.ResourceRecordSet
+		if recordSet == nil || recordSet.Type != awstypes.RRTypeTxt {
+			continue
+		}
+
+		if change.Action == awstypes.ChangeActionDelete {
+			for _, rr := range recordSet.ResourceRecords {
+				delete(d.changeIDs, changeKey(ptr.Deref(recordSet.Name), ptr.Deref(rr.Value)))
+			}
+
+			continue
+		}
+
+		for _, rr := range recordSet.ResourceRecords {
+			d.changeIDs[changeKey(ptr.Deref(recordSet.Name), ptr.Deref(rr.Value))] = changeID
+		}
+	}
+}
+
+func changeKey(fqdn, value string) string {
+	return strings.ToLower(dns01.ToFqdn(fqdn)) + " " + value
+}
+
 func (d *DNSProvider) getExistingRecordSets(ctx context.Context, hostedZoneID, fqdn string) ([]awstypes.ResourceRecord, error) {
 	listInput := &route53.ListResourceRecordSetsInput{
 		HostedZoneId:    aws.String(hostedZoneID),
@@ -291,13 +518,26 @@ func (d *DNSProvider) getExistingRecordSets(ctx context.Context, hostedZoneID, f
 }
 
 func (d *DNSProvider) getHostedZoneID(ctx context.Context, fqdn string) (string, error) {
+	return d.findHostedZoneID(ctx, func() (string, error) {
+		authZone, err := dns01.FindZoneByFqdn(fqdn)
+		if err != nil {
+			return "", fmt.Errorf("could not find zone for FQDN %q: %w", fqdn, err)
+		}
+
+		return authZone, nil
+	})
+}
+
+// findHostedZoneID returns the hosted zone ID defined in the configuration,
+// or the ID of the public hosted zone matching the authoritative zone (only resolved when the ID is not configured).
+func (d *DNSProvider) findHostedZoneID(ctx context.Context, resolveZone func() (string, error)) (string, error) {
 	if d.config.HostedZoneID != "" {
 		return d.config.HostedZoneID, nil
 	}
 
-	authZone, err := dns01.FindZoneByFqdn(fqdn)
+	authZone, err := resolveZone()
 	if err != nil {
-		return "", fmt.Errorf("could not find zone for FQDN %q: %w", fqdn, err)
+		return "", err
 	}
 
 	// .DNSName should not have a trailing dot
@@ -319,7 +559,7 @@ func (d *DNSProvider) getHostedZoneID(ctx context.Context, fqdn string) (string,
 	}
 
 	if hostedZoneID == "" {
-		return "", fmt.Errorf("zone %s not found for domain %s", authZone, fqdn)
+		return "", fmt.Errorf("zone %s not found", authZone)
 	}
 
 	hostedZoneID = strings.TrimPrefix(hostedZoneID, "/hostedzone/")
@@ -395,3 +635,8 @@ func createAWSConfigCheckParams(config *Config) error {
 
 	return nil
 }
+
+// staticZone returns a zone resolver for an already known authoritative zone.
+func staticZone(zone string) func() (string, error) {
+	return func() (string, error) { return zone, nil }
+}
@@ -2,7 +2,11 @@ package route53
 
 import (
 	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,6 +14,7 @@ import (
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/route53"
+	"github.com/go-acme/lego/v4/challenge/dns01"
 	"github.com/go-acme/lego/v4/platform/tester"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -177,6 +182,120 @@ func TestDNSProvider_Present(t *testing.T) {
 	require.NoError(t, err, "Expected Present to return no error")
 }
 
+func TestDNSProvider_Validate(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		hostedZoneID string
+		responses    MockResponseMap
+		expected     string
+	}{
+		{
+			desc: "hosted zones",
+			responses: MockResponseMap{
+				"/2013-04-01/hostedzonesbyname": {StatusCode: 200, Body: ListHostedZonesByNameResponse},
+			},
+		},
+		{
+			desc: "no hosted zone",
+			responses: MockResponseMap{
+				"/2013-04-01/hostedzonesbyname": {StatusCode: 200, Body: ListHostedZonesByNameEmptyResponse},
+			},
+			expected: "route53: no hosted zone is accessible with these credentials",
+		},
+		{
+			desc:         "hosted zone ID",
+			hostedZoneID: "ABCDEFG",
+			responses: MockResponseMap{
+				"/2013-04-01/hostedzone/ABCDEFG/rrset": {StatusCode: 200, Body: ListResourceRecordSetsEmptyResponse},
+			},
+		},
+		{
+			desc:         "hosted zone ID access denied",
+			hostedZoneID: "ABCDEFG",
+			responses: MockResponseMap{
+				"/2013-04-01/hostedzone/ABCDEFG/rrset": {StatusCode: 403, Body: AccessDeniedResponse},
+			},
+			expected: "route53: list resource record sets of the hosted zone ABCDEFG: ",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			serverURL := setupTest(t, test.responses)
+
+			defer envTest.RestoreEnv()
+			envTest.ClearEnv()
+			provider := makeTestProvider(t, serverURL)
+			provider.config.HostedZoneID = test.hostedZoneID
+
+			err := provider.Validate()
+			if test.expected == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorContains(t, err, test.expected)
+		})
+	}
+}
+
+func TestDNSProvider_PresentBatch(t *testing.T) {
+	var changes []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/xml")
+
+		switch {
+		case req.URL.Path == "/2013-04-01/hostedzonesbyname":
+			_, _ = io.WriteString(rw, ListHostedZonesByNameResponse)
+
+		case req.URL.Path == "/2013-04-01/hostedzone/ABCDEFG/rrset" && req.Method == http.MethodGet:
+			_, _ = io.WriteString(rw, `<?xml version="1.0" encoding="UTF-8"?>
+<ListResourceRecordSetsResponse xmlns="https://route53.amazonaws.com/doc/2013-04-01/">
+   <ResourceRecordSets/>
+   <IsTruncated>false</IsTruncated>
+   <MaxItems>100</MaxItems>
+</ListResourceRecordSetsResponse>`)
+
+		case req.URL.Path == "/2013-04-01/hostedzone/ABCDEFG/rrset" && req.Method == http.MethodPost:
+			body, _ := io.ReadAll(req.Body)
+			changes = append(changes, string(body))
+
+			_, _ = io.WriteString(rw, ChangeResourceRecordSetsResponse)
+
+		case req.URL.Path == "/2013-04-01/change/123456":
+			_, _ = io.WriteString(rw, GetChangeResponse)
+
+		default:
+			http.NotFound(rw, req)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	defer envTest.RestoreEnv()
+	envTest.ClearEnv()
+	provider := makeTestProvider(t, server.URL)
+
+	records := []dns01.BatchRecord{
+		{Domain: "example.com", Info: dns01.ChallengeInfo{EffectiveFQDN: "_acme-challenge.example.com.", Value: "a"}},
+		{Domain: "example.com", Info: dns01.ChallengeInfo{EffectiveFQDN: "_acme-challenge.example.com.", Value: "b"}},
+		{Domain: "www.example.com", Info: dns01.ChallengeInfo{EffectiveFQDN: "_acme-challenge.www.example.com.", Value: "c"}},
+	}
+
+	err := provider.PresentBatch("example.com.", records)
+	require.NoError(t, err)
+
+	require.Len(t, changes, 1)
+
+	assert.Equal(t, 2, strings.Count(changes[0], "<Change>"))
+	assert.Contains(t, changes[0], "<Name>_acme-challenge.example.com.</Name>")
+	assert.Contains(t, changes[0], "<Name>_acme-challenge.www.example.com.</Name>")
+
+	for _, value := range []string{"a", "b", "c"} {
+		assert.Contains(t, changes[0], "<Value>&#34;"+value+"&#34;</Value>")
+	}
+}
+
 func Test_createAWSConfig(t *testing.T) {
 	testCases := []struct {
 		desc             string
@@ -308,3 +427,49 @@ func requireErr(t *testing.T, err error, wantErr string) {
 		require.EqualError(t, err, wantErr)
 	}
 }
+
+func TestDNSProvider_IsPropagated(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/xml")
+
+		switch {
+		case req.URL.Path == "/2013-04-01/hostedzone/ABCDEFG/rrset" && req.Method == http.MethodGet:
+			_, _ = io.WriteString(rw, `<?xml version="1.0" encoding="UTF-8"?>
+<ListResourceRecordSetsResponse xmlns="https://route53.amazonaws.com/doc/2013-04-01/">
+   <ResourceRecordSets/>
+   <IsTruncated>false</IsTruncated>
+   <MaxItems>100</MaxItems>
+</ListResourceRecordSetsResponse>`)
+
+		case req.URL.Path == "/2013-04-01/hostedzone/ABCDEFG/rrset" && req.Method == http.MethodPost:
+			_, _ = io.WriteString(rw, ChangeResourceRecordSetsResponse)
+
+		case req.URL.Path == "/2013-04-01/change/123456":
+			_, _ = io.WriteString(rw, GetChangeResponse)
+
+		default:
+			http.NotFound(rw, req)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	provider := makeTestProvider(t, server.URL)
+	provider.config.HostedZoneID = "ABCDEFG"
+	provider.config.WaitForRecordSetsChanged = false
+
+	info := dns01.GetChallengeInfo("example.com", "123d==")
+
+	_, err := provider.IsPropagated(info.EffectiveFQDN, info.Value)
+	require.EqualError(t, err, "route53: unknown change for the TXT record _acme-challenge.example.com.")
+
+	err = provider.Present("example.com", "", "123d==")
+	require.NoError(t, err)
+
+	propagated, err := provider.IsPropagated(info.EffectiveFQDN, info.Value)
+	require.NoError(t, err)
+
+	assert.True(t, propagated)
+
+	err = provider.CleanUp("example.com", "", "123d==")
+	require.NoError(t, err)
+}
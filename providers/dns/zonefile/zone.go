@@ -0,0 +1,193 @@
+package zonefile
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// token a token of a zone file, with its position.
+type token struct {
+	value      string
+	start, end int
+}
+
+// addRecord appends the record at the end of the zone file, unless the record already exists.
+// The owner name of the record is absolute, so the record doesn't depend on the $ORIGIN directives.
+func addRecord(content []byte, rr dns.RR) ([]byte, bool) {
+	for _, line := range strings.SplitAfter(string(content), "\n") {
+		if matchRecord(line, rr) {
+			return content, false
+		}
+	}
+
+	data := string(content)
+	if data != "" && !strings.HasSuffix(data, "\n") {
+		data += "\n"
+	}
+
+	return []byte(data + rr.String() + "\n"), true
+}
+
+// removeRecord removes the lines of the record from the zone file.
+// The other lines (records, comments, directives) are kept as they are.
+func removeRecord(content []byte, rr dns.RR) ([]byte, bool) {
+	var (
+		sb      strings.Builder
+		changed bool
+	)
+
+	for _, line := range strings.SplitAfter(string(content), "\n") {
+		if matchRecord(line, rr) {
+			changed = true
+			continue
+		}
+
+		sb.WriteString(line)
+	}
+
+	return []byte(sb.String()), changed
+}
+
+// matchRecord returns true if the line is a complete record identical to the record (the TTL is ignored).
+// Only the lines with an explicit owner name are considered.
+func matchRecord(line string, rr dns.RR) bool {
+	if line == "" || line[0] == ' ' || line[0] == '\t' || line[0] == ';' || line[0] == '$' {
+		return false
+	}
+
+	parsed, err := dns.NewRR(strings.TrimSpace(line))
+	if err != nil || parsed == nil {
+		return false
+	}
+
+	return dns.IsDuplicate(parsed, rr)
+}
+
+// incrementSerial increments the serial of the SOA record of the zone file.
+func incrementSerial(content []byte, now time.Time) ([]byte, error) {
+	serial, err := findSerial(content)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := strconv.ParseUint(serial.value, 10, 32)
+	if err != nil {
+		return nil, errors.New("invalid SOA serial: " + serial.value)
+	}
+
+	next := strconv.FormatUint(uint64(nextSerial(uint32(current), now)), 10)
+
+	data := make([]byte, 0, len(content)+len(next)-len(serial.value))
+	data = append(data, content[:serial.start]...)
+	data = append(data, next...)
+	data = append(data, content[serial.end:]...)
+
+	return data, nil
+}
+
+// nextSerial returns the next serial with a date-based scheme (YYYYMMDDnn).
+// A serial already greater than or equal to the first serial of the day is incremented by one.
+func nextSerial(current uint32, now time.Time) uint32 {
+	year, month, day := now.Date()
+
+	first := uint32(year*1000000 + int(month)*10000 + day*100)
+
+	if current < first {
+		return first
+	}
+
+	return current + 1
+}
+
+// findSerial returns the token of the serial of the SOA record.
+// The SOA record must be defined in the zone file itself (not in an included file).
+func findSerial(content []byte) (token, error) {
+	tokens := tokenize(content)
+
+	for i, tok := range tokens {
+		if !strings.EqualFold(tok.value, "SOA") {
+			continue
+		}
+
+		// MNAME, RNAME, SERIAL
+		var fields []token
+
+		for _, next := range tokens[i+1:] {
+			if next.value == "(" || next.value == ")" {
+				continue
+			}
+
+			fields = append(fields, next)
+
+			if len(fields) == 3 {
+				break
+			}
+		}
+
+		if len(fields) != 3 {
+			continue
+		}
+
+		if _, err := strconv.ParseUint(fields[2].value, 10, 32); err == nil {
+			return fields[2], nil
+		}
+	}
+
+	return token{}, errors.New("SOA record not found in the zone file")
+}
+
+// tokenize splits the zone file content into tokens.
+// The comments are ignored, the quoted strings and the parentheses are single tokens.
+func tokenize(content []byte) []token {
+	var tokens []token
+
+	for i := 0; i < len(content); {
+		switch c := content[i]; {
+		case c == ';':
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+
+		case c == '(' || c == ')':
+			tokens = append(tokens, token{value: string(c), start: i, end: i + 1})
+			i++
+
+		case c == '"':
+			start := i
+
+			for i++; i < len(content) && content[i] != '"'; i++ {
+				if content[i] == '\\' {
+					i++
+				}
+			}
+
+			i = min(i+1, len(content))
+
+			tokens = append(tokens, token{value: string(content[start:i]), start: start, end: i})
+
+		default:
+			start := i
+
+			for i < len(content) && !strings.ContainsRune(" \t\r\n;()\"", rune(content[i])) {
+				if content[i] == '\\' {
+					i++
+				}
+
+				i++
+			}
+
+			i = min(i, len(content))
+
+			tokens = append(tokens, token{value: string(content[start:i]), start: start, end: i})
+		}
+	}
+
+	return tokens
+}
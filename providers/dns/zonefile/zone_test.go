@@ -0,0 +1,95 @@
+package zonefile
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_nextSerial(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc     string
+		current  uint32
+		expected uint32
+	}{
+		{
+			desc:     "older date",
+			current:  2024010101,
+			expected: 2026101900,
+		},
+		{
+			desc:     "same day",
+			current:  2026101905,
+			expected: 2026101906,
+		},
+		{
+			desc:     "more than 100 changes the same day",
+			current:  2026101999,
+			expected: 2026102000,
+		},
+		{
+			desc:     "serial in the future",
+			current:  2030010100,
+			expected: 2030010101,
+		},
+		{
+			desc:     "not date-based",
+			current:  42,
+			expected: 2026101900,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, nextSerial(test.current, now))
+		})
+	}
+}
+
+func Test_incrementSerial(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc     string
+		content  string
+		expected string
+	}{
+		{
+			desc:     "single line",
+			content:  "example.com. 3600 IN SOA ns1.example.com. admin.example.com. 1 7200 3600 1209600 3600\n",
+			expected: "example.com. 3600 IN SOA ns1.example.com. admin.example.com. 2026101900 7200 3600 1209600 3600\n",
+		},
+		{
+			desc:     "multiple lines",
+			content:  "@ IN SOA ns1 admin (\n  ; comment SOA a b 5\n  2026101901 ; serial\n  7200 3600 1209600 3600 )\n",
+			expected: "@ IN SOA ns1 admin (\n  ; comment SOA a b 5\n  2026101902 ; serial\n  7200 3600 1209600 3600 )\n",
+		},
+		{
+			desc:     "parenthesis before the names",
+			content:  "@ IN SOA ( ns1 admin\n  2026101901\n  7200 3600 1209600 3600 )\n",
+			expected: "@ IN SOA ( ns1 admin\n  2026101902\n  7200 3600 1209600 3600 )\n",
+		},
+		{
+			desc:     "quoted SOA",
+			content:  "txt IN TXT \"SOA a b 5\"\n@ IN SOA ns1 admin 3 7200 3600 1209600 3600\n",
+			expected: "txt IN TXT \"SOA a b 5\"\n@ IN SOA ns1 admin 2026101900 7200 3600 1209600 3600\n",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			content, err := incrementSerial([]byte(test.content), now)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, string(content))
+		})
+	}
+}
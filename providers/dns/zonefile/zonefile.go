@@ -0,0 +1,284 @@
+// Package zonefile implements a DNS provider for solving the DNS-01 challenge by editing a local zone file (RFC 1035).
+package zonefile
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/go-acme/lego/v4/log"
+	"github.com/go-acme/lego/v4/platform/config/env"
+	"github.com/miekg/dns"
+)
+
+// Environment variables names.
+const (
+	envNamespace = "ZONEFILE_"
+
+	EnvPath          = envNamespace + "PATH"
+	EnvZone          = envNamespace + "ZONE"
+	EnvReloadCommand = envNamespace + "RELOAD_COMMAND"
+
+	EnvTTL                = envNamespace + "TTL"
+	EnvPropagationTimeout = envNamespace + "PROPAGATION_TIMEOUT"
+	EnvPollingInterval    = envNamespace + "POLLING_INTERVAL"
+)
+
+var _ challenge.ProviderTimeout = (*DNSProvider)(nil)
+
+// Config is used to configure the creation of the DNSProvider.
+type Config struct {
+	// Path the path of the zone file.
+	Path string
+	// Zone the origin of the zone file.
+	// If empty, the zone is determined by using the recursive nameservers.
+	Zone string
+	// ReloadCommand the command run after each change of the zone file (e.g. `rndc reload example.com`).
+	ReloadCommand string
+
+	TTL                int
+	PropagationTimeout time.Duration
+	PollingInterval    time.Duration
+}
+
+// NewDefaultConfig returns a default configuration for the DNSProvider.
+func NewDefaultConfig() *Config {
+	return &Config{
+		TTL:                env.GetOrDefaultInt(EnvTTL, dns01.DefaultTTL),
+		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
+		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
+	}
+}
+
+// DNSProvider implements the challenge.Provider interface.
+type DNSProvider struct {
+	config *Config
+}
+
+// NewDNSProvider returns a DNSProvider instance configured for a zone file.
+// Configured with environment variables:
+// ZONEFILE_PATH: the path of the zone file.
+// ZONEFILE_ZONE: the origin of the zone file (optional).
+// ZONEFILE_RELOAD_COMMAND: the command run after each change of the zone file (optional).
+func NewDNSProvider() (*DNSProvider, error) {
+	values, err := env.Get(EnvPath)
+	if err != nil {
+		return nil, fmt.Errorf("zonefile: %w", err)
+	}
+
+	config := NewDefaultConfig()
+	config.Path = values[EnvPath]
+	config.Zone = env.GetOrDefaultString(EnvZone, "")
+	config.ReloadCommand = env.GetOrDefaultString(EnvReloadCommand, "")
+
+	return NewDNSProviderConfig(config)
+}
+
+// NewDNSProviderConfig return a DNSProvider instance configured for a zone file.
+func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
+	if config == nil {
+		return nil, errors.New("zonefile: the configuration of the DNS provider is nil")
+	}
+
+	if config.Path == "" {
+		return nil, errors.New("zonefile: missing zone file path")
+	}
+
+	path, err := filepath.Abs(config.Path)
+	if err != nil {
+		return nil, fmt.Errorf("zonefile: %w", err)
+	}
+
+	config.Path = path
+
+	if config.Zone != "" {
+		config.Zone = dns.Fqdn(config.Zone)
+	}
+
+	return &DNSProvider{config: config}, nil
+}
+
+// Timeout returns the timeout and interval to use when checking for DNS propagation.
+// Adjusting here to cope with spikes in propagation times.
+func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
+	return d.config.PropagationTimeout, d.config.PollingInterval
+}
+
+// Present creates a TXT record to fulfill the dns-01 challenge.
+func (d *DNSProvider) Present(domain, token, keyAuth string) error {
+	info := dns01.GetChallengeInfo(domain, keyAuth)
+
+	err := d.changeRecord(info.EffectiveFQDN, info.Value, addRecord)
+	if err != nil {
+		return fmt.Errorf("zonefile: %w", err)
+	}
+
+	return nil
+}
+
+// CleanUp removes the TXT record matching the specified parameters.
+func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
+	info := dns01.GetChallengeInfo(domain, keyAuth)
+
+	err := d.changeRecord(info.EffectiveFQDN, info.Value, removeRecord)
+	if err != nil {
+		return fmt.Errorf("zonefile: %w", err)
+	}
+
+	return nil
+}
+
+func (d *DNSProvider) changeRecord(fqdn, value string, change func(content []byte, rr dns.RR) ([]byte, bool)) error {
+	zone := d.config.Zone
+	if zone == "" {
+		var err error
+
+		zone, err = dns01.FindZoneByFqdn(fqdn)
+		if err != nil {
+			return fmt.Errorf("could not find zone for domain %q: %w", fqdn, err)
+		}
+	}
+
+	rr := &dns.TXT{
+		Hdr: dns.RR_Header{Name: fqdn, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: uint32(d.config.TTL)},
+		Txt: []string{value},
+	}
+
+	changed, err := d.editZoneFile(zone, rr, change)
+	if err != nil {
+		return err
+	}
+
+	if !changed {
+		return nil
+	}
+
+	return d.reload(context.Background())
+}
+
+// editZoneFile changes the zone file, and increments the serial of the SOA record.
+// The zone file is locked during the change.
+func (d *DNSProvider) editZoneFile(zone string, rr dns.RR, change func(content []byte, rr dns.RR) ([]byte, bool)) (bool, error) {
+	unlock, err := lockFile(d.config.Path + ".lock")
+	if err != nil {
+		return false, fmt.Errorf("lock zone file: %w", err)
+	}
+
+	defer unlock()
+
+	content, err := os.ReadFile(d.config.Path)
+	if err != nil {
+		return false, fmt.Errorf("read zone file: %w", err)
+	}
+
+	content, changed := change(content, rr)
+	if !changed {
+		return false, nil
+	}
+
+	content, err = incrementSerial(content, time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+
+	err = validateZone(content, zone, d.config.Path)
+	if err != nil {
+		return false, err
+	}
+
+	err = writeFile(d.config.Path, content)
+	if err != nil {
+		return false, fmt.Errorf("write zone file: %w", err)
+	}
+
+	return true, nil
+}
+
+func (d *DNSProvider) reload(ctx context.Context) error {
+	args := strings.Fields(d.config.ReloadCommand)
+	if len(args) == 0 {
+		return nil
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("create pipe: %w", err)
+	}
+
+	cmd.Stderr = cmd.Stdout
+
+	err = cmd.Start()
+	if err != nil {
+		return fmt.Errorf("start reload command: %w", err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		log.Println(scanner.Text())
+	}
+
+	err = cmd.Wait()
+	if err != nil {
+		return fmt.Errorf("wait reload command: %w", err)
+	}
+
+	return nil
+}
+
+// validateZone parses the zone file content, including the $INCLUDE files.
+func validateZone(content []byte, zone, filename string) error {
+	zp := dns.NewZoneParser(strings.NewReader(string(content)), zone, filename)
+	zp.SetIncludeAllowed(true)
+
+	for _, ok := zp.Next(); ok; _, ok = zp.Next() {
+	}
+
+	if err := zp.Err(); err != nil {
+		return fmt.Errorf("invalid zone file: %w", err)
+	}
+
+	return nil
+}
+
+// writeFile replaces the file atomically, with the same permissions.
+func writeFile(filename string, content []byte) error {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*")
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	_, err = tmp.Write(content)
+	if err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	err = os.Chmod(tmp.Name(), info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filename)
+}
@@ -0,0 +1,170 @@
+package zonefile
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/go-acme/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var envTest = tester.NewEnvTest(EnvPath, EnvZone, EnvReloadCommand)
+
+func TestNewDNSProvider(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		envVars  map[string]string
+		expected string
+	}{
+		{
+			desc: "success",
+			envVars: map[string]string{
+				EnvPath: "/var/lib/bind/example.com.zone",
+			},
+		},
+		{
+			desc: "missing path",
+			envVars: map[string]string{
+				EnvPath: "",
+			},
+			expected: "zonefile: some credentials information are missing: ZONEFILE_PATH",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			defer envTest.RestoreEnv()
+			envTest.ClearEnv()
+
+			envTest.Apply(test.envVars)
+
+			p, err := NewDNSProvider()
+
+			if test.expected == "" {
+				require.NoError(t, err)
+				require.NotNil(t, p)
+				require.NotNil(t, p.config)
+			} else {
+				require.EqualError(t, err, test.expected)
+			}
+		})
+	}
+}
+
+func TestNewDNSProviderConfig(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		path     string
+		expected string
+	}{
+		{
+			desc: "success",
+			path: "/var/lib/bind/example.com.zone",
+		},
+		{
+			desc:     "missing path",
+			expected: "zonefile: missing zone file path",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			config := NewDefaultConfig()
+			config.Path = test.path
+
+			p, err := NewDNSProviderConfig(config)
+
+			if test.expected == "" {
+				require.NoError(t, err)
+				require.NotNil(t, p)
+				require.NotNil(t, p.config)
+			} else {
+				require.EqualError(t, err, test.expected)
+			}
+		})
+	}
+}
+
+func TestDNSProvider_Present_CleanUp(t *testing.T) {
+	dir := t.TempDir()
+
+	original, err := os.ReadFile(filepath.Join("fixtures", "example.com.zone"))
+	require.NoError(t, err)
+
+	include, err := os.ReadFile(filepath.Join("fixtures", "hosts.zone"))
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, "example.com.zone")
+	require.NoError(t, os.WriteFile(path, original, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "hosts.zone"), include, 0o644))
+
+	config := NewDefaultConfig()
+	config.Path = path
+	config.Zone = "example.com"
+	config.TTL = 120
+	config.ReloadCommand = "touch " + filepath.Join(dir, "reloaded")
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	info := dns01.GetChallengeInfo("www.example.com", "123d==")
+
+	err = provider.Present("www.example.com", "", "123d==")
+	require.NoError(t, err)
+
+	serial := nextSerial(2024010101, time.Now().UTC())
+
+	expected := strings.Replace(string(original), "2024010101", strconv.FormatUint(uint64(serial), 10), 1) +
+		"_acme-challenge.www.example.com.\t120\tIN\tTXT\t\"" + info.Value + "\"\n"
+
+	assertFile(t, expected, path)
+	assert.FileExists(t, filepath.Join(dir, "reloaded"))
+
+	// The record already exists.
+	err = provider.Present("www.example.com", "", "123d==")
+	require.NoError(t, err)
+
+	assertFile(t, expected, path)
+
+	err = provider.CleanUp("www.example.com", "", "123d==")
+	require.NoError(t, err)
+
+	expected = strings.Replace(string(original), "2024010101", strconv.FormatUint(uint64(serial+1), 10), 1)
+
+	assertFile(t, expected, path)
+
+	fileInfo, err := os.Stat(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, os.FileMode(0o644), fileInfo.Mode().Perm())
+}
+
+func TestDNSProvider_Present_invalidZone(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "example.com.zone")
+	require.NoError(t, os.WriteFile(path, []byte("@ IN NS ns1.example.com.\n"), 0o644))
+
+	config := NewDefaultConfig()
+	config.Path = path
+	config.Zone = "example.com"
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	err = provider.Present("www.example.com", "", "123d==")
+	require.EqualError(t, err, "zonefile: SOA record not found in the zone file")
+}
+
+func assertFile(t *testing.T, expected, filename string) {
+	t.Helper()
+
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+
+	assert.Equal(t, expected, string(data))
+}
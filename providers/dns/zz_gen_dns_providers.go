@@ -4,6 +4,7 @@ package dns
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/go-acme/lego/v4/challenge"
 	"github.com/go-acme/lego/v4/challenge/dns01"
@@ -109,6 +110,7 @@ import (
 	"github.com/go-acme/lego/v4/providers/dns/ovh"
 	"github.com/go-acme/lego/v4/providers/dns/pdns"
 	"github.com/go-acme/lego/v4/providers/dns/plesk"
+	"github.com/go-acme/lego/v4/providers/dns/plugin"
 	"github.com/go-acme/lego/v4/providers/dns/porkbun"
 	"github.com/go-acme/lego/v4/providers/dns/rackspace"
 	"github.com/go-acme/lego/v4/providers/dns/rainyun"
@@ -151,10 +153,12 @@ import (
 	"github.com/go-acme/lego/v4/providers/dns/yandex360"
 	"github.com/go-acme/lego/v4/providers/dns/yandexcloud"
 	"github.com/go-acme/lego/v4/providers/dns/zoneee"
+	"github.com/go-acme/lego/v4/providers/dns/zonefile"
 	"github.com/go-acme/lego/v4/providers/dns/zonomi"
 )
 
 // NewDNSChallengeProviderByName Factory for DNS providers.
+// The names with the prefix "plugin:" use the plugin with the given name (e.g. "plugin:example").
 func NewDNSChallengeProviderByName(name string) (challenge.Provider, error) {
 	switch name {
 	case "manual":
@@ -447,9 +451,15 @@ func NewDNSChallengeProviderByName(name string) (challenge.Provider, error) {
 		return yandexcloud.NewDNSProvider()
 	case "zoneee":
 		return zoneee.NewDNSProvider()
+	case "zonefile":
+		return zonefile.NewDNSProvider()
 	case "zonomi":
 		return zonomi.NewDNSProvider()
 	default:
+		if pluginName, ok := strings.CutPrefix(name, plugin.ProviderPrefix); ok {
+			return plugin.NewDNSProvider(pluginName)
+		}
+
 		return nil, fmt.Errorf("unrecognized DNS provider: %s", name)
 	}
 }
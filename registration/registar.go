@@ -143,6 +143,26 @@ func (r *Registrar) UpdateRegistration(options RegisterOptions) (*Resource, erro
 	return &Resource{URI: accountURL, Body: account}, nil
 }
 
+// UpdateContact replaces the contact URIs (e.g. `mailto:admin@example.com`) of the user registration on the ACME server.
+// An empty list removes all the contacts.
+// - https://www.rfc-editor.org/rfc/rfc8555.html#section-7.3.2
+func (r *Registrar) UpdateContact(contacts []string) (*Resource, error) {
+	if r == nil || r.user == nil || r.user.GetRegistration() == nil {
+		return nil, errors.New("acme: cannot update the contacts of a nil client or user")
+	}
+
+	log.Infof("acme: Updating the contacts of the account %s", r.user.GetRegistration().URI)
+
+	accountURL := r.user.GetRegistration().URI
+
+	account, err := r.core.Accounts.UpdateContact(accountURL, contacts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Resource{URI: accountURL, Body: account}, nil
+}
+
 // DeleteRegistration deletes the client's user registration from the ACME server.
 func (r *Registrar) DeleteRegistration() error {
 	if r == nil || r.user == nil {